@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"twitter-demo/internal"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/usecase"
+)
+
+const usage = `Usage: admin <command> [flags]
+
+Commands:
+  rebuild   Rebuild cached timelines from Postgres
+              -user ID          rebuild one user's timeline
+              -all              rebuild every user's timeline
+              -rate N           timelines per second with -all (0 for no limit, default 50)
+              -after ID         with -all, start after this user
+              -resume           with -all, start after the checkpoint of an interrupted run
+  diff      Compare a cached timeline with Postgres
+              -user ID
+  evict     Drop cached keys, rebuilt on the next read
+              -user ID          drop one user's timeline
+              -pattern GLOB     drop every key matching GLOB, e.g. "timeline:user:*"
+  stats     Report the length and TTL distributions of cached lists, and the fan-out counters
+              -pattern GLOB     lists to inspect (default "timeline:user:*")
+`
+
+func main() {
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Stop cleanly on Ctrl+C, a rebuild can be resumed from its checkpoint
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	command, args := os.Args[1], os.Args[2:]
+
+	var run func(ctx context.Context, admin usecase.TimelineAdminUsecase, args []string) error
+	switch command {
+	case "rebuild":
+		run = rebuild
+	case "diff":
+		run = diff
+	case "evict":
+		run = evict
+	case "stats":
+		run = stats
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	container, err := internal.NewAdminContainer()
+	if err != nil {
+		log.Fatalf("Failed to create admin container: %v", err)
+	}
+
+	if err := run(ctx, container.TimelineAdminUsecase, args); err != nil {
+		log.Fatalf("%s failed: %v", command, err)
+	}
+}
+
+func rebuild(ctx context.Context, admin usecase.TimelineAdminUsecase, args []string) error {
+
+	flags := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	userID := flags.Int64("user", 0, "user whose timeline is rebuilt")
+	all := flags.Bool("all", false, "rebuild every user's timeline")
+	rate := flags.Float64("rate", 50, "timelines rebuilt per second with -all, 0 for no limit")
+	after := flags.Int64("after", 0, "with -all, start after this user")
+	resume := flags.Bool("resume", false, "with -all, start after the checkpoint of an interrupted run")
+	_ = flags.Parse(args)
+
+	if *all {
+		started := time.Now()
+		rebuilt, err := admin.RebuildAllTimelines(ctx, usecase.RebuildOptions{AfterID: *after, Resume: *resume, Rate: *rate})
+		fmt.Printf("Rebuilt %d timelines in %s\n", rebuilt, time.Since(started).Round(time.Second))
+		if err != nil {
+			return fmt.Errorf("%w (resume with -resume)", err)
+		}
+		return nil
+	}
+
+	if *userID == 0 {
+		return fmt.Errorf("-user or -all is required")
+	}
+
+	cached, err := admin.RebuildTimeline(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rebuilt timeline of user %d: %d tweets cached\n", *userID, cached)
+
+	return nil
+}
+
+func diff(ctx context.Context, admin usecase.TimelineAdminUsecase, args []string) error {
+
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	userID := flags.Int64("user", 0, "user whose timeline is compared")
+	_ = flags.Parse(args)
+
+	if *userID == 0 {
+		return fmt.Errorf("-user is required")
+	}
+
+	result, err := admin.DiffTimeline(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	printDiff(result)
+
+	return nil
+}
+
+func evict(ctx context.Context, admin usecase.TimelineAdminUsecase, args []string) error {
+
+	flags := flag.NewFlagSet("evict", flag.ExitOnError)
+	userID := flags.Int64("user", 0, "user whose timeline is dropped")
+	pattern := flags.String("pattern", "", "drop every key matching this glob")
+	_ = flags.Parse(args)
+
+	switch {
+	case *userID != 0:
+		if err := admin.EvictTimeline(ctx, *userID); err != nil {
+			return err
+		}
+		fmt.Printf("Evicted timeline of user %d\n", *userID)
+
+	case *pattern != "":
+		evicted, err := admin.EvictKeys(ctx, *pattern)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Evicted %d keys matching %q\n", evicted, *pattern)
+
+	default:
+		return fmt.Errorf("-user or -pattern is required")
+	}
+
+	return nil
+}
+
+func stats(ctx context.Context, admin usecase.TimelineAdminUsecase, args []string) error {
+
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	pattern := flags.String("pattern", "timeline:user:*", "lists to inspect")
+	_ = flags.Parse(args)
+
+	cacheStats, err := admin.GetCacheStats(ctx, *pattern)
+	if err != nil {
+		return err
+	}
+
+	fanOutStats, err := admin.GetFanOutStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Lists matching %q: %d (%d never expire)\n", cacheStats.Pattern, cacheStats.Keys, cacheStats.NoExpiry)
+	printDistribution("Length", cacheStats.Lengths)
+	printDistribution("TTL (s)", cacheStats.TTLs)
+
+	fmt.Println("Fan-out counters:")
+	fields := make([]string, 0, len(fanOutStats))
+	for field := range fanOutStats {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Printf("  %-10s %d\n", field, fanOutStats[field])
+	}
+
+	return nil
+}
+
+func printDiff(diff domain.TimelineDiff) {
+
+	if !diff.Cached {
+		fmt.Printf("Timeline of user %d is not cached (%d tweets in Postgres)\n", diff.UserID, diff.DBCount)
+		return
+	}
+
+	fmt.Printf("Timeline of user %d\n", diff.UserID)
+	fmt.Printf("  cached:       %d tweets, complete: %t, ttl: %s\n", diff.CachedCount, diff.Complete, diff.TTL)
+	fmt.Printf("  postgres:     %d tweets\n", diff.DBCount)
+	fmt.Printf("  missing:      %v\n", diff.Missing)
+	fmt.Printf("  extra:        %v\n", diff.Extra)
+	fmt.Printf("  out of order: %t\n", diff.OutOfOrder)
+
+	if diff.Consistent() {
+		fmt.Println("Cache is consistent")
+	} else {
+		fmt.Println("Cache is inconsistent, fix it with: admin rebuild -user", diff.UserID)
+	}
+}
+
+func printDistribution(name string, distribution domain.Distribution) {
+	fmt.Printf("  %-8s count=%d min=%d p50=%d p90=%d p99=%d max=%d mean=%.1f\n",
+		name, distribution.Count, distribution.Min, distribution.P50, distribution.P90, distribution.P99, distribution.Max, distribution.Mean)
+}
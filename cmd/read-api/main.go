@@ -17,8 +17,31 @@ func initRouter(c *internal.Container) *gin.Engine {
 	apiV1.GET("/users/:id", c.UserController.GetUserByID)
 
 	apiV1.GET("/tweets/:id", c.TweetController.GetTweetByID)
+	apiV1.GET("/tweets/:id/history", c.TweetController.GetTweetHistory)
 
 	apiV1.GET("/users/:id/timeline", c.TimelineController.GetTimeline)
+	apiV1.GET("/users/:id/timeline/stream", c.TimelineStreamController.StreamTimeline)
+	apiV1.GET("/users/:id/tweets", c.TimelineController.GetUserTweets)
+	apiV1.GET("/users/:id/mentions", c.TimelineController.GetMentions)
+	apiV1.GET("/users/:id/followers", c.FollowerController.GetFollowers)
+	apiV1.GET("/users/:id/following", c.FollowerController.GetFollowing)
+	apiV1.GET("/users/:id/following/export", c.FollowerController.ExportFollowing)
+	apiV1.GET("/users/:id/follow-requests", c.FollowerController.GetFollowRequests)
+	apiV1.GET("/users/:id/blocks", c.BlockController.GetBlocks)
+	apiV1.GET("/users/:id/suggestions", c.SuggestionController.GetSuggestions)
+	apiV1.GET("/users/:id/mutes", c.MuteController.GetMutes)
+	apiV1.GET("/users/:id/scheduled-tweets", c.ScheduledTweetController.GetScheduledTweets)
+	apiV1.GET("/users/:id/drafts", c.DraftController.GetDrafts)
+	apiV1.GET("/users/:id/lists", c.ListController.GetUserLists)
+	apiV1.GET("/users/:id/subscribed-lists", c.ListController.GetSubscribedLists)
+
+	apiV1.GET("/followers/bulk/:id", c.FollowImportController.GetImport)
+	apiV1.GET("/relationships", c.RelationshipController.GetRelationships)
+	apiV1.GET("/search/tweets", c.SearchController.SearchTweets)
+
+	apiV1.GET("/lists/:id", c.ListController.GetList)
+	apiV1.GET("/lists/:id/members", c.ListController.GetListMembers)
+	apiV1.GET("/lists/:id/timeline", c.TimelineController.GetListTimeline)
 
 	return router
 
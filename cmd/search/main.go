@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"twitter-demo/internal"
+	"twitter-demo/internal/config"
+)
+
+const usage = `Usage: search [command]
+
+Commands:
+  serve     Serve the search API on port 8082, indexing tweets from Kafka (default)
+  reindex   Rebuild the index from every tweet in Postgres. Stop the service first:
+            it would keep writing to the index being rebuilt
+`
+
+func initRouter(c *internal.SearchContainer) *gin.Engine {
+
+	router := gin.Default()
+	apiV1 := router.Group("/api/v1")
+
+	apiV1.GET("/search", c.SearchIndexController.SearchTweets)
+	apiV1.GET("/index/stats", c.SearchIndexController.GetIndexStats)
+
+	return router
+
+}
+
+func main() {
+
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	// Stop cleanly on Ctrl+C, flushing what was indexed
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "serve":
+		serve(ctx)
+	case "reindex":
+		reindex(ctx)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func serve(ctx context.Context) {
+
+	container, err := internal.NewSearchContainer()
+	if err != nil {
+		log.Fatalf("Failed to create search container: %v", err)
+	}
+
+	// Start consuming tweet and user events, in a consumer group of its own. Consuming
+	// stops after the last flush on shutdown, so its events can still be committed
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+
+	topics := []string{config.TopicTweets, config.TopicUsers}
+	log.Printf("Indexing events of topics %v as group %s", topics, container.SearchIndexConfig.GroupID)
+	go func() {
+		if err := container.Consumer.Consume(consumeCtx, topics, container.SearchIndexController.HandleEvent); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatalf("Consumer error: %v", err)
+		}
+	}()
+
+	// Start the flush loop, writing what was indexed since the last flush to a new segment,
+	// then committing the events it came from
+	log.Printf("Flushing the index to %s every %s", container.SearchIndexConfig.Dir, container.SearchIndexConfig.FlushInterval)
+	go func() {
+		ticker := time.NewTicker(container.SearchIndexConfig.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = container.Consumer.CommitAfter(ctx, container.SearchIndexController.FlushIndex)
+			}
+		}
+	}()
+
+	server := &http.Server{Addr: ":8082", Handler: initRouter(container)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to run server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down search service...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+
+	// Write what was indexed since the last flush and commit its events, events consumed
+	// meanwhile are consumed again on restart
+	if err := container.Consumer.CommitAfter(shutdownCtx, container.SearchIndexController.FlushIndex); err != nil {
+		log.Fatalf("Failed to flush index: %v", err)
+	}
+
+	stopConsuming()
+	if err := container.Consumer.Close(); err != nil {
+		log.Printf("Error closing consumer: %v", err)
+	}
+}
+
+func reindex(ctx context.Context) {
+
+	container, err := internal.NewReindexContainer()
+	if err != nil {
+		log.Fatalf("Failed to create reindex container: %v", err)
+	}
+
+	started := time.Now()
+	indexed, err := container.SearchIndexUsecase.Reindex(ctx)
+	fmt.Printf("Indexed %d tweets in %s\n", indexed, time.Since(started).Round(time.Second))
+	if err != nil {
+		log.Fatalf("reindex failed: %v (run it again, the index is rebuilt from scratch)", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"twitter-demo/internal"
 	"twitter-demo/internal/config"
@@ -38,19 +39,85 @@ func main() {
 	sigterm := make(chan os.Signal, 1)
 	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start consuming tweets topic
-	topics := []string{config.TopicTweets}
-	log.Printf("Listening for events on topic: %s", config.TopicTweets)
+	// Start consuming tweets and follows topics
+	topics := []string{config.TopicTweets, config.TopicFollows}
+	log.Printf("Listening for events on topics: %v", topics)
 	log.Println("Press Ctrl+C to stop...")
 	log.Println("========================================")
 
 	// Start consuming messages in a goroutine
 	go func() {
-		if err := container.Consumer.Consume(ctx, topics, container.TimelineController.HandleTweetCreated); err != nil {
+		if err := container.Consumer.Consume(ctx, topics, container.TimelineController.HandleEvent); err != nil {
 			log.Fatalf("Consumer error: %v", err)
 		}
 	}()
 
+	// Start the scheduler loop, publishing scheduled tweets when they are due.
+	// Every replica runs it: due tweets are claimed with row locks so each one is published once.
+	log.Printf("Publishing scheduled tweets every %s", container.SchedulerConfig.Interval)
+	go func() {
+		ticker := time.NewTicker(container.SchedulerConfig.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = container.ScheduledTweetController.PublishDueTweets(ctx)
+			}
+		}
+	}()
+
+	// Start the follow imports loop, following the accounts of pending bulk imports.
+	// Every replica runs it: imports are claimed with row locks so each one is processed once.
+	log.Printf("Processing follow imports every %s", container.FollowImportConfig.Interval)
+	go func() {
+		ticker := time.NewTicker(container.FollowImportConfig.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = container.FollowImportController.ProcessPendingImports(ctx)
+			}
+		}
+	}()
+
+	// Start the suggestions loop, recomputing who-to-follow suggestions into Redis
+	log.Printf("Refreshing suggestions every %s", container.SuggestionConfig.Interval)
+	go func() {
+		ticker := time.NewTicker(container.SuggestionConfig.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = container.SuggestionController.RefreshSuggestions(ctx)
+			}
+		}
+	}()
+
+	// Start the pruning loop, removing users who left the active and online windows
+	log.Printf("Pruning inactive users every %s", container.FanOutConfig.PruneInterval)
+	go func() {
+		ticker := time.NewTicker(container.FanOutConfig.PruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = container.TimelineController.PruneActivity(ctx)
+			}
+		}
+	}()
+
 	// Wait for termination signal
 	<-sigterm
 	log.Println("\n========================================")
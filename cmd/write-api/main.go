@@ -14,12 +14,40 @@ func initRouter(c *internal.Container) *gin.Engine {
 
 	apiV1.POST("/users", c.UserController.CreateUser)
 	apiV1.PUT("/users/:id", c.UserController.UpdateUser)
+	apiV1.PUT("/users/:id/pinned-tweet", c.TweetController.PinTweet)
+	apiV1.DELETE("/users/:id/pinned-tweet", c.TweetController.UnpinTweet)
 
 	apiV1.POST("/tweets", c.TweetController.CreateTweet)
+	apiV1.POST("/tweets/validate", c.TweetController.ValidateTweet)
 	apiV1.PUT("/tweets/:id", c.TweetController.UpdateTweetByID)
 
+	apiV1.POST("/tweets/scheduled", c.ScheduledTweetController.ScheduleTweet)
+	apiV1.DELETE("/tweets/scheduled/:id", c.ScheduledTweetController.CancelScheduledTweet)
+
+	apiV1.POST("/drafts", c.DraftController.CreateDraft)
+	apiV1.PUT("/drafts/:id", c.DraftController.UpdateDraft)
+	apiV1.DELETE("/drafts/:id", c.DraftController.DeleteDraft)
+	apiV1.POST("/drafts/:id/publish", c.DraftController.PublishDraft)
+
+	apiV1.POST("/lists", c.ListController.CreateList)
+	apiV1.PUT("/lists/:id", c.ListController.UpdateList)
+	apiV1.DELETE("/lists/:id", c.ListController.DeleteList)
+	apiV1.POST("/lists/:id/members", c.ListController.AddMember)
+	apiV1.DELETE("/lists/:id/members", c.ListController.RemoveMember)
+	apiV1.POST("/lists/:id/subscribers", c.ListController.Subscribe)
+	apiV1.DELETE("/lists/:id/subscribers", c.ListController.Unsubscribe)
+
 	apiV1.POST("/followers", c.FollowerController.FollowUser)
 	apiV1.DELETE("/followers", c.FollowerController.UnfollowUser)
+	apiV1.POST("/followers/bulk", c.FollowImportController.CreateImport)
+	apiV1.POST("/users/:id/follow-requests/:requester_id/approve", c.FollowerController.ApproveFollowRequest)
+	apiV1.POST("/users/:id/follow-requests/:requester_id/reject", c.FollowerController.RejectFollowRequest)
+
+	apiV1.POST("/blocks", c.BlockController.BlockUser)
+	apiV1.DELETE("/blocks", c.BlockController.UnblockUser)
+
+	apiV1.POST("/mutes", c.MuteController.MuteUser)
+	apiV1.DELETE("/mutes", c.MuteController.UnmuteUser)
 
 	return router
 }
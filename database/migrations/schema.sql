@@ -5,6 +5,9 @@ CREATE TABLE users (
     email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
     bio VARCHAR(255),
+    followers_count INT NOT NULL DEFAULT 0, -- Denormalized, maintained with every follow/unfollow
+    following_count INT NOT NULL DEFAULT 0, -- Denormalized, maintained with every follow/unfollow
+    protected BOOLEAN NOT NULL DEFAULT FALSE, -- Tweets only visible to approved followers
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -12,14 +15,92 @@ CREATE TABLE users (
 CREATE TABLE tweets (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL,
-    content VARCHAR(280) NOT NULL, -- Required by the PDF
+    content TEXT NOT NULL CHECK (octet_length(content) <= 4096), -- 280 weighted characters, enforced by the application (URLs, CJK and emoji are not counted as runes), and at most config.TweetMaxContentBytes bytes
+    reply_to_tweet_id INT, -- The tweet this one answers
+    retweet_of_id INT, -- The tweet this one shares
+    edit_count INT NOT NULL DEFAULT 0,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', content)) STORED -- Kept up to date on edits
 );
 
 -- Index necessary: To quickly find "all tweets of Pedro"
 CREATE INDEX idx_tweets_user_id ON tweets(user_id);
 
+-- Index necessary: To search tweets by content (full-text search)
+CREATE INDEX idx_tweets_search_vector ON tweets USING GIN (search_vector);
+
+-- Indexes necessary: To count the replies and retweets of a tweet when ranking timelines
+CREATE INDEX idx_tweets_reply_to_tweet_id ON tweets(reply_to_tweet_id) WHERE reply_to_tweet_id IS NOT NULL;
+CREATE INDEX idx_tweets_retweet_of_id ON tweets(retweet_of_id) WHERE retweet_of_id IS NOT NULL;
+
+CREATE TABLE pinned_tweets (
+    user_id INT PRIMARY KEY, -- A user can pin a single tweet
+    tweet_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_pinned_tweet FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE
+);
+
+CREATE TABLE tweet_revisions (
+    id SERIAL PRIMARY KEY,
+    tweet_id INT NOT NULL,
+    content TEXT NOT NULL, -- The content as it was before the edit
+    created_at TIMESTAMP NOT NULL, -- When this version was published
+
+    CONSTRAINT fk_tweet FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To list the edit history of a tweet
+CREATE INDEX idx_tweet_revisions_tweet_id ON tweet_revisions(tweet_id);
+
+CREATE TABLE tweet_mentions (
+    tweet_id INT NOT NULL,
+    user_id INT NOT NULL, -- The one mentioned with @username in the content
+    author_id INT NOT NULL, -- Denormalized from tweets, to hide muted and blocked authors without a join
+
+    PRIMARY KEY (tweet_id, user_id),
+    CONSTRAINT fk_tweet_mention_tweet FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tweet_mention_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To list "the tweets mentioning me", newest first
+CREATE INDEX idx_tweet_mentions_user ON tweet_mentions(user_id, tweet_id DESC);
+
+CREATE TABLE scheduled_tweets (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    content TEXT NOT NULL CHECK (octet_length(content) <= 4096), -- Validated like a tweet when it is scheduled
+    publish_at TIMESTAMPTZ NOT NULL, -- Compared to CURRENT_TIMESTAMP whatever the time zone of the client
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, publishing, published, canceled, failed
+    tweet_id INT, -- The tweet created when it was published
+    error TEXT,
+    claimed_at TIMESTAMPTZ, -- When a worker started publishing it
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_scheduled_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index 1: To list the scheduled tweets of a user
+CREATE INDEX idx_scheduled_tweets_user_id ON scheduled_tweets(user_id);
+
+-- Index 2: To find the tweets that are due (Scheduler)
+CREATE INDEX idx_scheduled_tweets_due ON scheduled_tweets(status, publish_at);
+
+CREATE TABLE drafts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    content TEXT NOT NULL, -- Not validated until the draft is published
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_draft_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To list the drafts of a user
+CREATE INDEX idx_drafts_user_id ON drafts(user_id);
+
 CREATE TABLE followers (
     id SERIAL PRIMARY KEY,
     follower_id INT NOT NULL, -- The one who follows (me)
@@ -42,3 +123,125 @@ CREATE INDEX idx_followers_follower ON followers(follower_id);
 
 -- Index 2: To know "Who follows me" (Fan-out or notifications)
 CREATE INDEX idx_followers_followed ON followers(followed_id);
+
+CREATE TABLE follow_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INT NOT NULL, -- The one who wants to follow
+    target_id INT NOT NULL, -- The protected account that must approve
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_requester FOREIGN KEY (requester_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_target FOREIGN KEY (target_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_follow_request UNIQUE (requester_id, target_id),
+    CONSTRAINT check_no_self_request CHECK (requester_id <> target_id)
+);
+
+-- Index necessary: To list "Who asked to follow me" (pending requests of the owner)
+CREATE INDEX idx_follow_requests_target ON follow_requests(target_id, id DESC);
+
+CREATE TABLE follow_imports (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL, -- The one who follows
+    targets TEXT[] NOT NULL, -- Usernames or user IDs to follow, in the order they were sent
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, completed
+    processed_count INT NOT NULL DEFAULT 0, -- Targets handled so far, a reclaimed import resumes from there
+    followed_count INT NOT NULL DEFAULT 0,
+    requested_count INT NOT NULL DEFAULT 0, -- Protected accounts asked to approve
+    skipped_count INT NOT NULL DEFAULT 0, -- Already followed or already requested
+    failed_count INT NOT NULL DEFAULT 0,
+    claimed_at TIMESTAMP, -- When a worker last made progress on it
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_follow_import_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To find the imports waiting for a worker
+CREATE INDEX idx_follow_imports_status ON follow_imports(status, id);
+
+CREATE TABLE follow_import_failures (
+    id SERIAL PRIMARY KEY,
+    import_id INT NOT NULL,
+    target TEXT NOT NULL,
+    reason TEXT NOT NULL,
+
+    CONSTRAINT fk_follow_import_failure_import FOREIGN KEY (import_id) REFERENCES follow_imports(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To report the failures of an import
+CREATE INDEX idx_follow_import_failures_import_id ON follow_import_failures(import_id);
+
+CREATE TABLE lists (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL,
+    name VARCHAR(25) NOT NULL,
+    description VARCHAR(100) NOT NULL DEFAULT '',
+    private BOOLEAN NOT NULL DEFAULT FALSE, -- Only visible to the owner
+    members_count INT NOT NULL DEFAULT 0, -- Denormalized, maintained with every member change
+    subscribers_count INT NOT NULL DEFAULT 0, -- Denormalized, maintained with every subscription change
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_list_owner FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Index necessary: To list the lists of a user
+CREATE INDEX idx_lists_owner_id ON lists(owner_id);
+
+CREATE TABLE list_members (
+    id SERIAL PRIMARY KEY,
+    list_id INT NOT NULL,
+    user_id INT NOT NULL, -- The account whose tweets show up in the list timeline
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_list_member_list FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_list_member_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_list_member UNIQUE (list_id, user_id)
+);
+
+-- Index necessary: To know "Which lists am I in" (Fan-out to list timelines)
+CREATE INDEX idx_list_members_user_id ON list_members(user_id);
+
+CREATE TABLE list_subscribers (
+    id SERIAL PRIMARY KEY,
+    list_id INT NOT NULL,
+    user_id INT NOT NULL, -- The one who reads the list
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_list_subscriber_list FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_list_subscriber_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_list_subscriber UNIQUE (list_id, user_id)
+);
+
+-- Index necessary: To know "Which lists do I read"
+CREATE INDEX idx_list_subscribers_user_id ON list_subscribers(user_id);
+
+CREATE TABLE blocks (
+    id SERIAL PRIMARY KEY,
+    blocker_id INT NOT NULL, -- The one who blocks
+    blocked_id INT NOT NULL, -- The one who is blocked
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_blocker FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_blocked FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_block UNIQUE (blocker_id, blocked_id),
+    CONSTRAINT check_no_self_block CHECK (blocker_id <> blocked_id)
+);
+
+-- Index necessary: To know "Who blocked me" (the unique constraint covers "Who I blocked")
+CREATE INDEX idx_blocks_blocked ON blocks(blocked_id);
+
+CREATE TABLE mutes (
+    id SERIAL PRIMARY KEY,
+    muter_id INT NOT NULL, -- The one who mutes
+    muted_id INT NOT NULL, -- The one who is muted (never notified)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_muter FOREIGN KEY (muter_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_muted FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_mute UNIQUE (muter_id, muted_id),
+    CONSTRAINT check_no_self_mute CHECK (muter_id <> muted_id)
+);
+
+-- Index necessary: To know "Who muted me" (Fan-out skips them)
+CREATE INDEX idx_mutes_muted ON mutes(muted_id);
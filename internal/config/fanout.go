@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// FanOutConfig controls which timelines a new tweet is pushed to, and how it is
+// streamed to the users connected to their timeline.
+type FanOutConfig struct {
+	ActiveWindow       time.Duration // Followers who didn't read their timeline for longer are skipped (0 pushes to everyone)
+	StreamOnlineWindow time.Duration // Users whose stream didn't send a heartbeat for longer are not streamed to
+	StreamMaxLen       int64         // How many events are kept per user to resume a stream
+	StreamExpiration   time.Duration // How long the events of a user are kept after the last one
+	PruneInterval      time.Duration // How often users outside the active and online windows are removed from their sets
+}
+
+func NewFanOutConfig() FanOutConfig {
+	return FanOutConfig{
+		ActiveWindow:       getEnvDuration("FANOUT_ACTIVE_WINDOW", 7*24*time.Hour),
+		StreamOnlineWindow: getEnvDuration("FANOUT_STREAM_ONLINE_WINDOW", time.Minute),
+		StreamMaxLen:       int64(getEnvInt("FANOUT_STREAM_MAX_LEN", 100)),
+		StreamExpiration:   getEnvDuration("FANOUT_STREAM_EXPIRATION", 10*time.Minute),
+		PruneInterval:      getEnvDuration("FANOUT_PRUNE_INTERVAL", time.Hour),
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// FollowImportConfig controls the bulk follow imports processed by the worker.
+type FollowImportConfig struct {
+	Interval     time.Duration // How often pending imports are checked
+	BatchSize    int           // How many imports are claimed per check
+	LeaseTimeout time.Duration // After this long without progress a claimed import is considered abandoned
+	MaxTargets   int           // How many accounts a single import can follow
+}
+
+func NewFollowImportConfig() FollowImportConfig {
+	return FollowImportConfig{
+		Interval:     getEnvDuration("FOLLOW_IMPORT_INTERVAL", 5*time.Second),
+		BatchSize:    getEnvInt("FOLLOW_IMPORT_BATCH_SIZE", 5),
+		LeaseTimeout: getEnvDuration("FOLLOW_IMPORT_LEASE_TIMEOUT", 5*time.Minute),
+		MaxTargets:   getEnvInt("FOLLOW_IMPORT_MAX_TARGETS", 5000),
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	GraphStorePostgres = "postgres" // Relationships are read from the followers table
+	GraphStoreMemory   = "memory"   // Relationships are kept in an in-process adjacency list
+)
+
+// GraphConfig selects and tunes the store backing the social graph.
+type GraphConfig struct {
+	Store           string        // GraphStorePostgres or GraphStoreMemory
+	SnapshotPath    string        // Where the in-memory graph is persisted, empty to keep it in memory only
+	RefreshInterval time.Duration // How often the in-memory graph is reloaded from Postgres
+}
+
+func NewGraphConfig() GraphConfig {
+	store := os.Getenv("GRAPH_STORE")
+	if store == "" {
+		store = GraphStorePostgres
+	}
+
+	if store != GraphStorePostgres && store != GraphStoreMemory {
+		log.Fatalf("Unknown GRAPH_STORE %q, expected %q or %q", store, GraphStorePostgres, GraphStoreMemory)
+	}
+
+	return GraphConfig{
+		Store:           store,
+		SnapshotPath:    os.Getenv("GRAPH_SNAPSHOT_PATH"),
+		RefreshInterval: getEnvDuration("GRAPH_REFRESH_INTERVAL", 5*time.Minute),
+	}
+}
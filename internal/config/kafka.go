@@ -6,8 +6,9 @@ import (
 )
 
 type KafkaConfig struct {
-	Brokers []string
-	GroupID string
+	Brokers      []string
+	GroupID      string
+	ManualCommit bool // Offsets are only committed by Consumer.CommitAfter, e.g. once what was consumed is persisted
 }
 
 func NewKafkaConfig() KafkaConfig {
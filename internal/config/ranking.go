@@ -0,0 +1,68 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	RankingScorerWeighted      = "weighted"      // Recency decay boosted by engagement, affinity and social proof
+	RankingScorerChronological = "chronological" // Newest first, like the latest timeline
+)
+
+// RankingConfig selects and tunes the scorer of the ranked timeline and its candidates.
+type RankingConfig struct {
+	Scorer                 string        // RankingScorerWeighted or RankingScorerChronological
+	HalfLife               time.Duration // Age at which the recency of a tweet is halved
+	EngagementWeight       float64       // Boost given by the replies and retweets of a tweet
+	AffinityWeight         float64       // Boost given by the viewer's recent interactions with the author
+	SocialProofWeight      float64       // Boost given by the followed users who engaged with a tweet
+	OutOfNetworkWeight     float64       // Factor applied to tweets of users the viewer doesn't follow
+	InNetworkCandidates    int           // How many of the newest timeline tweets are ranked
+	OutOfNetworkCandidates int           // How many tweets from outside the network are ranked
+	OutOfNetworkWindow     time.Duration // How recent the engagement of followed users must be
+	InteractionWindow      time.Duration // How far back the viewer's interactions are counted for affinity
+	SnapshotExpiration     time.Duration // How long a ranking is kept for paginating it
+}
+
+func NewRankingConfig() RankingConfig {
+	scorer := os.Getenv("RANKING_SCORER")
+	if scorer == "" {
+		scorer = RankingScorerWeighted
+	}
+
+	if scorer != RankingScorerWeighted && scorer != RankingScorerChronological {
+		log.Fatalf("Unknown RANKING_SCORER %q, expected %q or %q", scorer, RankingScorerWeighted, RankingScorerChronological)
+	}
+
+	return RankingConfig{
+		Scorer:                 scorer,
+		HalfLife:               getEnvDuration("RANKING_HALF_LIFE", 6*time.Hour),
+		EngagementWeight:       getEnvFloat("RANKING_ENGAGEMENT_WEIGHT", 1),
+		AffinityWeight:         getEnvFloat("RANKING_AFFINITY_WEIGHT", 0.5),
+		SocialProofWeight:      getEnvFloat("RANKING_SOCIAL_PROOF_WEIGHT", 0.5),
+		OutOfNetworkWeight:     getEnvFloat("RANKING_OUT_OF_NETWORK_WEIGHT", 0.5),
+		InNetworkCandidates:    getEnvInt("RANKING_IN_NETWORK_CANDIDATES", 500),
+		OutOfNetworkCandidates: getEnvInt("RANKING_OUT_OF_NETWORK_CANDIDATES", 100),
+		OutOfNetworkWindow:     getEnvDuration("RANKING_OUT_OF_NETWORK_WINDOW", 48*time.Hour),
+		InteractionWindow:      getEnvDuration("RANKING_INTERACTION_WINDOW", 30*24*time.Hour),
+		SnapshotExpiration:     getEnvDuration("RANKING_SNAPSHOT_EXPIRATION", 30*time.Minute),
+	}
+}
+
+// getEnvFloat reads a float environment variable, falling back to the default when unset.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Failed to convert %s to float: %v", key, err)
+	}
+
+	return parsed
+}
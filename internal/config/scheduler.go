@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// SchedulerConfig controls how the worker publishes scheduled tweets.
+type SchedulerConfig struct {
+	Interval     time.Duration // How often due tweets are checked
+	BatchSize    int           // How many due tweets are claimed per check
+	LeaseTimeout time.Duration // After this long a claimed tweet is considered abandoned
+	MaxHorizon   time.Duration // How far in the future a tweet can be scheduled
+}
+
+func NewSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		Interval:     getEnvDuration("SCHEDULER_INTERVAL", 10*time.Second),
+		BatchSize:    getEnvInt("SCHEDULER_BATCH_SIZE", 100),
+		LeaseTimeout: getEnvDuration("SCHEDULER_LEASE_TIMEOUT", 5*time.Minute),
+		MaxHorizon:   getEnvDuration("SCHEDULER_MAX_HORIZON", 365*24*time.Hour),
+	}
+}
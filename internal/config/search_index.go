@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// SearchIndexConfig controls the inverted index of the search service.
+type SearchIndexConfig struct {
+	Dir              string        // Where the index segments are persisted
+	FlushInterval    time.Duration // How often indexed changes are written to a new segment
+	FlushSize        int           // Changes that trigger a flush before the interval
+	MaxSegments      int           // Segments on disk before they are merged into one
+	ReindexBatchSize int           // Tweets read from Postgres at once by a reindex
+	GroupID          string        // Kafka consumer group, distinct from the worker's so both receive every event
+}
+
+func NewSearchIndexConfig() SearchIndexConfig {
+	dir := os.Getenv("SEARCH_INDEX_DIR")
+	if dir == "" {
+		dir = "data/search"
+	}
+
+	groupID := os.Getenv("SEARCH_KAFKA_GROUP_ID")
+	if groupID == "" {
+		groupID = "twitter-demo-search"
+	}
+
+	return SearchIndexConfig{
+		Dir:              dir,
+		FlushInterval:    getEnvDuration("SEARCH_INDEX_FLUSH_INTERVAL", 5*time.Second),
+		FlushSize:        getEnvInt("SEARCH_INDEX_FLUSH_SIZE", 1000),
+		MaxSegments:      getEnvInt("SEARCH_INDEX_MAX_SEGMENTS", 8),
+		ReindexBatchSize: getEnvInt("SEARCH_REINDEX_BATCH_SIZE", 1000),
+		GroupID:          groupID,
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// StreamConfig controls the timeline streams served by the read API.
+type StreamConfig struct {
+	Heartbeat  time.Duration // How often an idle stream sends a heartbeat, must be shorter than FANOUT_STREAM_ONLINE_WINDOW
+	BufferSize int           // How many events can wait for a slow client before it is disconnected
+}
+
+func NewStreamConfig() StreamConfig {
+	return StreamConfig{
+		Heartbeat:  getEnvDuration("STREAM_HEARTBEAT", 15*time.Second),
+		BufferSize: getEnvInt("STREAM_BUFFER_SIZE", 64),
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// SuggestionConfig controls the who-to-follow suggestions computed by the worker.
+type SuggestionConfig struct {
+	Interval      time.Duration // How often suggestions are recomputed for every user
+	BatchSize     int           // How many users are loaded per query while recomputing
+	MaxPerUser    int           // How many suggestions are kept per user
+	MaxFollowedBy int           // How many mutual connections are kept to explain a suggestion
+	Expiration    time.Duration // How long computed suggestions stay in cache
+}
+
+func NewSuggestionConfig() SuggestionConfig {
+	return SuggestionConfig{
+		Interval:      getEnvDuration("SUGGESTIONS_INTERVAL", time.Hour),
+		BatchSize:     getEnvInt("SUGGESTIONS_BATCH_SIZE", 500),
+		MaxPerUser:    getEnvInt("SUGGESTIONS_MAX_PER_USER", 50),
+		MaxFollowedBy: getEnvInt("SUGGESTIONS_MAX_FOLLOWED_BY", 3),
+		Expiration:    getEnvDuration("SUGGESTIONS_EXPIRATION", 24*time.Hour),
+	}
+}
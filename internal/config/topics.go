@@ -4,9 +4,11 @@ package config
 const (
 	TopicTweets  = "tweets"
 	TopicFollows = "follows"
+	TopicUsers   = "users"
 )
 
 // Kafka message key formats
 const (
 	KeyFormatTweet = "tweet-%d" // tweet-{tweetID}
+	KeyFormatUser  = "user-%d"  // user-{userID}
 )
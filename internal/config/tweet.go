@@ -0,0 +1,97 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WeightRange assigns a weight to every code point between Start and End (inclusive).
+type WeightRange struct {
+	Start  rune
+	End    rune
+	Weight int
+}
+
+// TweetMaxContentBytes is the largest content accepted, in bytes. The tweets table
+// enforces it too, with a CHECK constraint.
+const TweetMaxContentBytes = 4096
+
+// TweetTextConfig defines how tweet content is measured against the length limit.
+// Weights are expressed in units of Scale, so with a Scale of 100 a weight of 100
+// counts as one character and a weight of 200 counts as two.
+type TweetTextConfig struct {
+	MaxWeightedLength int
+	MaxBytes          int // Raw size limit, checked before weighting since a URL counts the same whatever its size
+	Scale             int
+	DefaultWeight     int
+	EmojiWeight       int
+	URLLength         int
+	Ranges            []WeightRange
+}
+
+// NewTweetTextConfig returns the tweet text configuration, following Twitter's
+// weighted counting rules by default: Latin and general punctuation count as one
+// character, CJK and emoji count as two and every URL counts as 23.
+func NewTweetTextConfig() TweetTextConfig {
+	return TweetTextConfig{
+		MaxWeightedLength: getEnvInt("TWEET_MAX_LENGTH", 280),
+		MaxBytes:          TweetMaxContentBytes,
+		Scale:             100,
+		DefaultWeight:     getEnvInt("TWEET_DEFAULT_WEIGHT", 200),
+		EmojiWeight:       getEnvInt("TWEET_EMOJI_WEIGHT", 200),
+		URLLength:         getEnvInt("TWEET_URL_LENGTH", 23),
+		Ranges: []WeightRange{
+			{Start: 0x0000, End: 0x10FF, Weight: 100},
+			{Start: 0x2000, End: 0x200D, Weight: 100},
+			{Start: 0x2010, End: 0x201F, Weight: 100},
+			{Start: 0x2032, End: 0x2037, Weight: 100},
+		},
+	}
+}
+
+// TweetEditConfig limits how tweets can be edited after they are published.
+type TweetEditConfig struct {
+	Window   time.Duration
+	MaxEdits int
+}
+
+// NewTweetEditConfig returns the edit rules: by default a tweet can be edited
+// up to 5 times during the first hour after it was published.
+func NewTweetEditConfig() TweetEditConfig {
+	return TweetEditConfig{
+		Window:   getEnvDuration("TWEET_EDIT_WINDOW", time.Hour),
+		MaxEdits: getEnvInt("TWEET_MAX_EDITS", 5),
+	}
+}
+
+// getEnvInt reads an integer environment variable, falling back to the default when unset.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Failed to convert %s to int: %v", key, err)
+	}
+
+	return parsed
+}
+
+// getEnvDuration reads a duration environment variable (e.g. "30m"), falling back to the default when unset.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Failed to convert %s to duration: %v", key, err)
+	}
+
+	return parsed
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"log"
 	"twitter-demo/internal/config"
 	"twitter-demo/internal/infrastructure/repository"
@@ -10,10 +11,20 @@ import (
 )
 
 type Container struct {
-	UserController     controller.UserController
-	TweetController    controller.TweetController
-	FollowerController controller.FollowerController
-	TimelineController controller.TimelineController
+	UserController           controller.UserController
+	TweetController          controller.TweetController
+	FollowerController       controller.FollowerController
+	TimelineController       controller.TimelineController
+	ScheduledTweetController controller.ScheduledTweetController
+	DraftController          controller.DraftController
+	ListController           controller.ListController
+	BlockController          controller.BlockController
+	MuteController           controller.MuteController
+	SuggestionController     controller.SuggestionController
+	FollowImportController   controller.FollowImportController
+	RelationshipController   controller.RelationshipController
+	TimelineStreamController controller.TimelineStreamController
+	SearchController         controller.SearchController
 }
 
 func NewContainer() (*Container, error) {
@@ -34,34 +45,100 @@ func NewContainer() (*Container, error) {
 		// Continue without Kafka - events won't be published but API will work
 	}
 
+	// The in-memory graph is only kept by the worker, which runs the fan-out. The API
+	// reads relationships from Postgres so its replicas don't each hold the whole graph.
+	graph := repository.NewPostgresGraph(db)
+
 	userRepository := repository.NewUser(db)
-	userUsecase := usecase.NewUser(userRepository)
-	userController := controller.NewUser(userUsecase)
+
+	blockRepository := repository.NewBlock(db, graph)
+	blockUsecase := usecase.NewBlock(blockRepository, userRepository, producer)
+	blockController := controller.NewBlock(blockUsecase)
+
+	muteRepository := repository.NewMute(db)
+	muteUsecase := usecase.NewMute(muteRepository, userRepository, producer)
+	muteController := controller.NewMute(muteUsecase)
+
+	followerRepository := repository.NewFollower(db, graph)
 
 	tweetRepository := repository.NewTweet(db)
-	tweetUsecase := usecase.NewTweet(tweetRepository, userRepository, producer)
+	tweetUsecase := usecase.NewTweet(tweetRepository, userRepository, followerRepository, blockRepository, producer, config.NewTweetTextConfig(), config.NewTweetEditConfig())
 	tweetController := controller.NewTweet(tweetUsecase)
 
-	followerRepository := repository.NewFollower(db)
-	followerUsecase := usecase.NewFollower(followerRepository, userRepository)
+	scheduledTweetRepository := repository.NewScheduledTweet(db)
+	scheduledTweetUsecase := usecase.NewScheduledTweet(scheduledTweetRepository, userRepository, tweetUsecase, config.NewSchedulerConfig())
+	scheduledTweetController := controller.NewScheduledTweet(scheduledTweetUsecase)
+
+	draftRepository := repository.NewDraft(db)
+	draftUsecase := usecase.NewDraft(draftRepository, userRepository, tweetUsecase)
+	draftController := controller.NewDraft(draftUsecase)
+
+	followRequestRepository := repository.NewFollowRequest(db, graph)
+	followerUsecase := usecase.NewFollower(followerRepository, followRequestRepository, userRepository, blockRepository, producer)
 	followerController := controller.NewFollower(followerUsecase)
 
-	timelineUsecase := usecase.NewTimeline(tweetRepository, followerRepository, cache)
-	timelineController := controller.NewTimeline(timelineUsecase)
+	listRepository := repository.NewList(db)
+	engagementRepository := repository.NewEngagement(db)
+	mentionRepository := repository.NewMention(db)
+	rankingConfig := config.NewRankingConfig()
+	timelineUsecase := usecase.NewTimeline(tweetRepository, userRepository, followerRepository, blockRepository, muteRepository, listRepository, engagementRepository, mentionRepository, cache, newTimelineScorer(rankingConfig), config.NewFanOutConfig(), rankingConfig)
+	timelineController := controller.NewTimeline(timelineUsecase, usecase.NewSocialGraph(graph))
+
+	userUsecase := usecase.NewUser(userRepository, followerUsecase, timelineUsecase, producer)
+	userController := controller.NewUser(userUsecase)
+
+	streamConfig := config.NewStreamConfig()
+	timelineStreamUsecase := usecase.NewTimelineStream(cache, streamConfig)
+	timelineStreamController := controller.NewTimelineStream(timelineStreamUsecase, timelineUsecase, streamConfig)
+
+	searchRepository := repository.NewSearch(db)
+	searchUsecase := usecase.NewSearch(searchRepository, muteRepository, blockRepository)
+	searchController := controller.NewSearch(searchUsecase, timelineUsecase)
+
+	listUsecase := usecase.NewList(listRepository, userRepository, blockRepository, timelineUsecase)
+	listController := controller.NewList(listUsecase)
+
+	suggestionUsecase := usecase.NewSuggestion(followerRepository, userRepository, blockRepository, cache, config.NewSuggestionConfig())
+	suggestionController := controller.NewSuggestion(suggestionUsecase)
+
+	followImportRepository := repository.NewFollowImport(db)
+	followImportUsecase := usecase.NewFollowImport(followImportRepository, userRepository, followerUsecase, timelineUsecase, config.NewFollowImportConfig())
+	followImportController := controller.NewFollowImport(followImportUsecase)
+
+	relationshipRepository := repository.NewRelationship(db)
+	relationshipUsecase := usecase.NewRelationship(relationshipRepository, userRepository)
+	relationshipController := controller.NewRelationship(relationshipUsecase)
 
 	return &Container{
-		UserController:     userController,
-		TweetController:    tweetController,
-		FollowerController: followerController,
-		TimelineController: timelineController,
+		UserController:           userController,
+		TweetController:          tweetController,
+		FollowerController:       followerController,
+		TimelineController:       timelineController,
+		ScheduledTweetController: scheduledTweetController,
+		DraftController:          draftController,
+		ListController:           listController,
+		BlockController:          blockController,
+		MuteController:           muteController,
+		SuggestionController:     suggestionController,
+		FollowImportController:   followImportController,
+		RelationshipController:   relationshipController,
+		TimelineStreamController: timelineStreamController,
+		SearchController:         searchController,
 	}, nil
 
 }
 
 // WorkerContainer holds dependencies for the Kafka worker service
 type WorkerContainer struct {
-	TimelineController controller.TimelineController
-	Consumer           pkg.Consumer
+	TimelineController       controller.TimelineController
+	ScheduledTweetController controller.ScheduledTweetController
+	SchedulerConfig          config.SchedulerConfig
+	SuggestionController     controller.SuggestionController
+	SuggestionConfig         config.SuggestionConfig
+	FollowImportController   controller.FollowImportController
+	FollowImportConfig       config.FollowImportConfig
+	FanOutConfig             config.FanOutConfig
+	Consumer                 pkg.Consumer
 }
 
 // NewWorkerContainer creates a new container for the worker service
@@ -82,18 +159,211 @@ func NewWorkerContainer() (*WorkerContainer, error) {
 		return nil, err
 	}
 
+	// Initialize Kafka producer (scheduled tweets are published like any other tweet)
+	producer, err := pkg.NewKafkaProducer(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := newSocialGraph(db, config.NewGraphConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	schedulerConfig := config.NewSchedulerConfig()
+	suggestionConfig := config.NewSuggestionConfig()
+	followImportConfig := config.NewFollowImportConfig()
+	rankingConfig := config.NewRankingConfig()
+	fanOutConfig := config.NewFanOutConfig()
+
 	// Initialize repositories
+	userRepository := repository.NewUser(db)
 	tweetRepository := repository.NewTweet(db)
-	followerRepository := repository.NewFollower(db)
+	followerRepository := repository.NewFollower(db, graph)
+	scheduledTweetRepository := repository.NewScheduledTweet(db)
+	blockRepository := repository.NewBlock(db, graph)
+	muteRepository := repository.NewMute(db)
+	listRepository := repository.NewList(db)
+	followRequestRepository := repository.NewFollowRequest(db, graph)
+	followImportRepository := repository.NewFollowImport(db)
+	engagementRepository := repository.NewEngagement(db)
+	mentionRepository := repository.NewMention(db)
 
 	// Initialize use cases
-	timelineUsecase := usecase.NewTimeline(tweetRepository, followerRepository, cache)
+	tweetUsecase := usecase.NewTweet(tweetRepository, userRepository, followerRepository, blockRepository, producer, config.NewTweetTextConfig(), config.NewTweetEditConfig())
+	timelineUsecase := usecase.NewTimeline(tweetRepository, userRepository, followerRepository, blockRepository, muteRepository, listRepository, engagementRepository, mentionRepository, cache, newTimelineScorer(rankingConfig), fanOutConfig, rankingConfig)
+	scheduledTweetUsecase := usecase.NewScheduledTweet(scheduledTweetRepository, userRepository, tweetUsecase, schedulerConfig)
+	suggestionUsecase := usecase.NewSuggestion(followerRepository, userRepository, blockRepository, cache, suggestionConfig)
+	followerUsecase := usecase.NewFollower(followerRepository, followRequestRepository, userRepository, blockRepository, producer)
+	followImportUsecase := usecase.NewFollowImport(followImportRepository, userRepository, followerUsecase, timelineUsecase, followImportConfig)
 
 	// Initialize controllers
-	timelineController := controller.NewTimeline(timelineUsecase)
+	timelineController := controller.NewTimeline(timelineUsecase, usecase.NewSocialGraph(graph))
+	scheduledTweetController := controller.NewScheduledTweet(scheduledTweetUsecase)
+	suggestionController := controller.NewSuggestion(suggestionUsecase)
+	followImportController := controller.NewFollowImport(followImportUsecase)
 
 	return &WorkerContainer{
-		TimelineController: timelineController,
-		Consumer:           consumer,
+		TimelineController:       timelineController,
+		ScheduledTweetController: scheduledTweetController,
+		SchedulerConfig:          schedulerConfig,
+		SuggestionController:     suggestionController,
+		SuggestionConfig:         suggestionConfig,
+		FollowImportController:   followImportController,
+		FollowImportConfig:       followImportConfig,
+		FanOutConfig:             fanOutConfig,
+		Consumer:                 consumer,
 	}, nil
 }
+
+// AdminContainer holds dependencies for the admin command
+type AdminContainer struct {
+	TimelineAdminUsecase usecase.TimelineAdminUsecase
+}
+
+// NewAdminContainer creates a new container for the admin command. It reads the social
+// graph from Postgres rather than loading it in memory, a command only needs a few lookups.
+func NewAdminContainer() (*AdminContainer, error) {
+
+	db, err := pkg.NewPostgres(config.NewPostgresConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize Redis cache
+	cache := pkg.NewRedisCache(config.NewRedisConfig())
+
+	graph := repository.NewPostgresGraph(db)
+	rankingConfig := config.NewRankingConfig()
+
+	// Initialize repositories
+	userRepository := repository.NewUser(db)
+	tweetRepository := repository.NewTweet(db)
+	followerRepository := repository.NewFollower(db, graph)
+	blockRepository := repository.NewBlock(db, graph)
+	muteRepository := repository.NewMute(db)
+	listRepository := repository.NewList(db)
+	engagementRepository := repository.NewEngagement(db)
+	mentionRepository := repository.NewMention(db)
+
+	// Initialize use cases
+	timelineUsecase := usecase.NewTimeline(tweetRepository, userRepository, followerRepository, blockRepository, muteRepository, listRepository, engagementRepository, mentionRepository, cache, newTimelineScorer(rankingConfig), config.NewFanOutConfig(), rankingConfig)
+
+	return &AdminContainer{
+		TimelineAdminUsecase: timelineUsecase,
+	}, nil
+}
+
+// SearchContainer holds dependencies for the search service
+type SearchContainer struct {
+	SearchIndexController controller.SearchIndexController
+	SearchIndexConfig     config.SearchIndexConfig
+	Consumer              pkg.Consumer
+}
+
+// NewSearchContainer creates a new container for the search service. The index is
+// loaded from disk, and kept up to date by a consumer group of its own.
+func NewSearchContainer() (*SearchContainer, error) {
+
+	db, err := pkg.NewPostgres(config.NewPostgresConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndexConfig := config.NewSearchIndexConfig()
+
+	// Initialize Kafka consumer. The index is only written to disk when it is flushed,
+	// events are committed once they are
+	kafkaConfig := config.NewKafkaConfig()
+	kafkaConfig.GroupID = searchIndexConfig.GroupID
+	kafkaConfig.ManualCommit = true
+	consumer, err := pkg.NewKafkaConsumer(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndexUsecase, err := newSearchIndexUsecase(db, searchIndexConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchContainer{
+		SearchIndexController: controller.NewSearchIndex(searchIndexUsecase),
+		SearchIndexConfig:     searchIndexConfig,
+		Consumer:              consumer,
+	}, nil
+}
+
+// ReindexContainer holds dependencies for the reindex command of the search service
+type ReindexContainer struct {
+	SearchIndexUsecase usecase.SearchIndexUsecase
+}
+
+// NewReindexContainer creates a new container for the reindex command, which only needs
+// Postgres and the index directory.
+func NewReindexContainer() (*ReindexContainer, error) {
+
+	db, err := pkg.NewPostgres(config.NewPostgresConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndexUsecase, err := newSearchIndexUsecase(db, config.NewSearchIndexConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReindexContainer{
+		SearchIndexUsecase: searchIndexUsecase,
+	}, nil
+}
+
+// newSearchIndexUsecase loads the index of the search service from disk and builds its
+// use case. Relationships are read from Postgres, they are only needed to filter results.
+func newSearchIndexUsecase(db *pkg.Postgres, searchIndexConfig config.SearchIndexConfig) (usecase.SearchIndexUsecase, error) {
+
+	searchIndex := repository.NewInvertedIndex(searchIndexConfig.Dir, searchIndexConfig.FlushSize, searchIndexConfig.MaxSegments)
+	if err := searchIndex.Load(); err != nil {
+		return nil, err
+	}
+
+	graph := repository.NewPostgresGraph(db)
+
+	// Initialize repositories
+	tweetRepository := repository.NewTweet(db)
+	userRepository := repository.NewUser(db)
+	followerRepository := repository.NewFollower(db, graph)
+	muteRepository := repository.NewMute(db)
+	blockRepository := repository.NewBlock(db, graph)
+
+	return usecase.NewSearchIndex(searchIndex, tweetRepository, userRepository, followerRepository, muteRepository, blockRepository, searchIndexConfig), nil
+}
+
+// newSocialGraph builds the social graph store of the worker selected by the config. The
+// in-memory graph is loaded before serving and then reloaded from Postgres in the background.
+func newSocialGraph(db *pkg.Postgres, graphConfig config.GraphConfig) (repository.SocialGraph, error) {
+
+	if graphConfig.Store != config.GraphStoreMemory {
+		return repository.NewPostgresGraph(db), nil
+	}
+
+	graph := repository.NewMemoryGraph(db, graphConfig.SnapshotPath)
+	if err := graph.Load(context.Background()); err != nil {
+		return nil, err
+	}
+
+	go graph.Run(context.Background(), graphConfig.RefreshInterval)
+
+	log.Printf("Using in-memory social graph, reloaded every %s", graphConfig.RefreshInterval)
+
+	return graph, nil
+}
+
+// newTimelineScorer builds the scorer of the ranked timeline selected by the config.
+func newTimelineScorer(rankingConfig config.RankingConfig) usecase.TimelineScorer {
+	if rankingConfig.Scorer == config.RankingScorerChronological {
+		return usecase.ChronologicalScorer{}
+	}
+
+	return usecase.NewWeightedScorer(rankingConfig)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+type Block struct {
+	ID        int64
+	BlockerID int64
+	BlockedID int64
+	CreatedAt time.Time
+}
+
+type Mute struct {
+	ID        int64
+	MuterID   int64
+	MutedID   int64
+	CreatedAt time.Time
+}
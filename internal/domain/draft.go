@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type Draft struct {
+	ID        int64
+	UserID    int64
+	Content   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
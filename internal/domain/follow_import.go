@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Follow import statuses
+const (
+	FollowImportPending    = "pending"
+	FollowImportProcessing = "processing"
+	FollowImportCompleted  = "completed"
+)
+
+// Outcomes of following one target of an import
+const (
+	FollowImportFollowed  = "followed"
+	FollowImportRequested = "requested"
+	FollowImportSkipped   = "skipped"
+	FollowImportFailed    = "failed"
+)
+
+// FollowImport is a bulk follow processed asynchronously by the worker.
+// Targets are usernames or user IDs, followed in order.
+type FollowImport struct {
+	ID             int64
+	UserID         int64
+	Targets        []string
+	Status         string
+	ProcessedCount int
+	FollowedCount  int
+	RequestedCount int
+	SkippedCount   int
+	FailedCount    int
+	Failures       []FollowImportFailure
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type FollowImportFailure struct {
+	Target string
+	Reason string
+}
@@ -8,3 +8,21 @@ type Follower struct {
 	FollowedID int64
 	CreatedAt  time.Time
 }
+
+// Connection is the user on the other side of a follow relationship,
+// as listed in followers and following lists.
+type Connection struct {
+	FollowID   int64 // ID of the follow relationship, used as pagination cursor
+	User       User
+	FollowedAt time.Time
+}
+
+// FollowRequest is a pending request to follow a protected account.
+// It becomes a Follower once the target approves it.
+type FollowRequest struct {
+	ID          int64
+	RequesterID int64
+	TargetID    int64
+	Requester   User // Loaded when listing the pending requests of the target
+	CreatedAt   time.Time
+}
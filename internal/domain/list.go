@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// List is a named collection of accounts curated by its owner.
+// Its timeline shows the tweets of its members.
+type List struct {
+	ID               int64
+	OwnerID          int64
+	Name             string
+	Description      string
+	Private          bool // Only visible to the owner
+	MembersCount     int
+	SubscribersCount int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,17 @@
+package domain
+
+// TweetEngagement counts the tweets answering or sharing a tweet.
+type TweetEngagement struct {
+	Replies  int
+	Retweets int
+}
+
+// RankingCandidate is a tweet that may appear in a ranked timeline, with the signals
+// used to score it for the viewer.
+type RankingCandidate struct {
+	Tweet       Tweet
+	InNetwork   bool // Written by the viewer or by someone they follow
+	Engagement  TweetEngagement
+	Affinity    int // How many tweets of the author the viewer replied to or retweeted recently
+	SocialProof int // How many of the users the viewer follows replied to or retweeted the tweet
+}
@@ -0,0 +1,15 @@
+package domain
+
+// Relationship is everything that links a source user to a target user, from the source's point of view.
+// Whether the target muted the source is never exposed.
+type Relationship struct {
+	SourceID              int64
+	TargetID              int64
+	Following             bool // The source follows the target
+	FollowedBy            bool // The target follows the source
+	Blocking              bool // The source blocked the target
+	BlockedBy             bool // The target blocked the source
+	Muting                bool // The source muted the target
+	FollowRequestSent     bool // The source asked to follow the protected target
+	FollowRequestReceived bool // The target asked to follow the protected source
+}
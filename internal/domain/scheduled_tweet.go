@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Scheduled tweet statuses
+const (
+	ScheduledTweetPending    = "pending"
+	ScheduledTweetPublishing = "publishing"
+	ScheduledTweetPublished  = "published"
+	ScheduledTweetCanceled   = "canceled"
+	ScheduledTweetFailed     = "failed"
+)
+
+type ScheduledTweet struct {
+	ID        int64
+	UserID    int64
+	Content   string
+	PublishAt time.Time
+	Status    string
+	TweetID   int64
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+const (
+	SearchSortRelevance = "relevance" // Best matches first (default)
+	SearchSortRecent    = "recent"    // Newest first
+)
+
+// SearchQuery is a parsed tweet search. The zero value of a filter doesn't filter.
+type SearchQuery struct {
+	Text     string    // Words and "quoted phrases" the content must contain
+	Hashtags []string  // Hashtags the content must contain, without the #
+	From     string    // Username of the author
+	Since    time.Time // Tweets published on or after
+	Until    time.Time // Tweets published before
+	SinceID  int64     // Tweets newer than this one
+	Sort     string    // SearchSortRelevance or SearchSortRecent
+}
+
+// Empty reports whether the query has nothing to search for.
+func (q SearchQuery) Empty() bool {
+	return q.Text == "" && len(q.Hashtags) == 0 && q.From == ""
+}
+
+// SearchCursor is the position of the last result of a page of search results.
+type SearchCursor struct {
+	TweetID int64
+	Rank    float32 // Relevance of the tweet, for searches sorted by relevance
+}
+
+// SearchResult is a tweet matching a search, with its relevance.
+type SearchResult struct {
+	Tweet Tweet
+	Rank  float32
+}
+
+// IndexedTweet is the copy of a tweet kept by the search service index.
+type IndexedTweet struct {
+	ID        int64
+	UserID    int64
+	Content   string
+	CreatedAt time.Time
+	UpdatedAt time.Time // Version of the content, an older version never replaces a newer one
+}
+
+// IndexHit is a tweet found in the search service index, with its BM25 score.
+type IndexHit struct {
+	Tweet IndexedTweet
+	Score float64
+}
+
+// IndexStats describes the search service index.
+type IndexStats struct {
+	Tweets   int // Tweets searchable
+	Terms    int // Distinct terms, across segments
+	Segments int // Segments on disk
+	Buffered int // Tweets and tombstones written since the last flush
+}
@@ -0,0 +1,11 @@
+package domain
+
+// Suggestion is an account a user may want to follow: someone followed by the
+// people they follow (a friend of a friend).
+type Suggestion struct {
+	UserID        int64
+	MutualCount   int     // How many of the people the user follows follow this account
+	FollowedByIDs []int64 // Some of those people, used to explain the suggestion
+	User          User    // Loaded when serving the suggestions
+	FollowedBy    []User  // Loaded when serving the suggestions
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// TimelineDiff compares the cached timeline of a user with the timeline read from the database.
+type TimelineDiff struct {
+	UserID      int64
+	Cached      bool          // Whether the timeline is cached at all
+	CachedCount int           // Tweet IDs in the cached list
+	Complete    bool          // Whether the cached list ends with the end marker
+	TTL         time.Duration // Time left before the cached list expires
+	DBCount     int           // Tweet IDs the database returns for the cached window
+	Missing     []int64       // In the database within the cached range, but not cached
+	Extra       []int64       // Cached, but not in the database (deleted, or from hidden users)
+	OutOfOrder  bool          // Whether the cached IDs are not sorted newest first
+}
+
+// Consistent reports whether the cached timeline matches the database.
+func (d TimelineDiff) Consistent() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && !d.OutOfOrder
+}
+
+// Distribution summarizes a set of values.
+type Distribution struct {
+	Count int
+	Min   int64
+	Max   int64
+	Mean  float64
+	P50   int64
+	P90   int64
+	P99   int64
+}
+
+// CacheStats describes the lists cached under a key pattern.
+type CacheStats struct {
+	Pattern  string
+	Keys     int
+	Lengths  Distribution // Entries per list, end markers included
+	TTLs     Distribution // Seconds left before the lists expire, for the ones that expire
+	NoExpiry int          // Lists that never expire
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TimelineEvent tells a user connected to their timeline that a tweet landed in it.
+// Its ID is the ID of the event in the user's stream ("<milliseconds>-<sequence>"),
+// which clients send back to resume the stream where they left off.
+type TimelineEvent struct {
+	ID      string
+	TweetID int64
+}
+
+// After reports whether the event came after the event with the given ID.
+// Every event comes after an empty ID.
+func (e TimelineEvent) After(id string) bool {
+
+	if id == "" {
+		return true
+	}
+
+	eventMillis, eventSeq := splitEventID(e.ID)
+	millis, seq := splitEventID(id)
+
+	if eventMillis != millis {
+		return eventMillis > millis
+	}
+
+	return eventSeq > seq
+}
+
+// splitEventID parses a stream ID. Malformed parts are read as 0.
+func splitEventID(id string) (uint64, uint64) {
+
+	millisPart, seqPart, _ := strings.Cut(id, "-")
+
+	millis, _ := strconv.ParseUint(millisPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+
+	return millis, seq
+}
@@ -1,11 +1,92 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
 
 type Tweet struct {
+	ID             int64
+	UserID         int64
+	Content        string
+	ReplyToTweetID int64 // 0 when the tweet is not a reply
+	RetweetOfID    int64 // 0 when the tweet is not a retweet
+	EditCount      int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// IsReply reports whether the tweet answers another tweet.
+func (t Tweet) IsReply() bool {
+	return t.ReplyToTweetID != 0
+}
+
+// IsRetweet reports whether the tweet shares another tweet.
+func (t Tweet) IsRetweet() bool {
+	return t.RetweetOfID != 0
+}
+
+// mentionPattern matches @username not preceded by a word character, so e-mail
+// addresses are not read as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w+)`)
+
+// Mentions returns the usernames mentioned in the content, in order of appearance and
+// without duplicates.
+func (t Tweet) Mentions() []string {
+
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(t.Content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			usernames = append(usernames, match[1])
+		}
+	}
+
+	return usernames
+}
+
+// Mention is a tweet mentioning a user.
+type Mention struct {
+	TweetID  int64
+	AuthorID int64
+}
+
+// TweetFilter selects which kinds of tweets are listed on a profile.
+type TweetFilter struct {
+	IncludeReplies  bool
+	IncludeRetweets bool
+}
+
+// Matches reports whether the tweet passes the filter.
+func (f TweetFilter) Matches(isReply, isRetweet bool) bool {
+	return (f.IncludeReplies || !isReply) && (f.IncludeRetweets || !isRetweet)
+}
+
+// Edited reports whether the tweet content changed after it was published.
+func (t Tweet) Edited() bool {
+	return t.EditCount > 0
+}
+
+// TweetRevision is a previous version of a tweet's content, stored on every edit.
+type TweetRevision struct {
 	ID        int64
-	UserID    int64
+	TweetID   int64
 	Content   string
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
+
+// TweetLength is the result of measuring tweet content against the weighted limit.
+type TweetLength struct {
+	WeightedLength int
+	MaxLength      int
+	Permillage     int
+	Valid          bool
+	Oversized      bool // Over the raw size limit, the weighted length was not measured
+}
+
+// Remaining returns how many characters are still available.
+// It is negative when the content is over the limit.
+func (l TweetLength) Remaining() int {
+	return l.MaxLength - l.WeightedLength
 }
@@ -3,10 +3,13 @@ package domain
 import "time"
 
 type User struct {
-	ID        int64
-	Username  string
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID             int64
+	Username       string
+	Email          string
+	Password       string
+	FollowersCount int
+	FollowingCount int
+	Protected      bool // Tweets are only visible to approved followers
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+type BlockRepository interface {
+	Insert(ctx context.Context, block domain.Block) (domain.Block, error)
+	Delete(ctx context.Context, blockerID, blockedID int64) error
+	SelectByBlockerAndBlocked(ctx context.Context, blockerID, blockedID int64) (domain.Block, error)
+	SelectBlocksPage(ctx context.Context, blockerID, maxID int64, limit int) ([]domain.Block, error)
+	ExistsBetween(ctx context.Context, userID, otherUserID int64) (bool, error)
+	SelectBlockedOrBlockingIDs(ctx context.Context, userID int64) ([]int64, error)
+}
+
+type Block struct {
+	db    *pkg.Postgres
+	graph SocialGraph
+}
+
+func NewBlock(db *pkg.Postgres, graph SocialGraph) Block {
+	return Block{
+		db:    db,
+		graph: graph,
+	}
+}
+
+// Insert creates a block and removes the follow relationships and pending follow
+// requests in both directions, updating the follow counts, in the same transaction.
+func (b Block) Insert(ctx context.Context, block domain.Block) (domain.Block, error) {
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Block{}, err
+	}
+	defer tx.Rollback()
+
+	var newBlock domain.Block
+
+	row := tx.QueryRowContext(ctx,
+		"INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2) RETURNING id, blocker_id, blocked_id, created_at",
+		block.BlockerID, block.BlockedID)
+
+	err = row.Scan(&newBlock.ID, &newBlock.BlockerID, &newBlock.BlockedID, &newBlock.CreatedAt)
+	if err != nil {
+		return domain.Block{}, err
+	}
+
+	var unfollowed [][2]int64
+
+	pairs := [][2]int64{{block.BlockerID, block.BlockedID}, {block.BlockedID, block.BlockerID}}
+	for _, pair := range pairs {
+		result, err := tx.ExecContext(ctx, "DELETE FROM followers WHERE follower_id = $1 AND followed_id = $2", pair[0], pair[1])
+		if err != nil {
+			return domain.Block{}, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return domain.Block{}, err
+		}
+
+		if rowsAffected > 0 {
+			if err := updateFollowCounts(ctx, tx, pair[0], pair[1], -1); err != nil {
+				return domain.Block{}, err
+			}
+			unfollowed = append(unfollowed, pair)
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM follow_requests WHERE requester_id = $1 AND target_id = $2", pair[0], pair[1])
+		if err != nil {
+			return domain.Block{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Block{}, err
+	}
+
+	for _, pair := range unfollowed {
+		removeGraphEdge(ctx, b.graph, pair[0], pair[1])
+	}
+
+	return newBlock, nil
+}
+
+func (b Block) Delete(ctx context.Context, blockerID, blockedID int64) error {
+
+	result, err := b.db.ExecContext(ctx,
+		"DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2",
+		blockerID, blockedID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("block not found")
+	}
+
+	return nil
+}
+
+func (b Block) SelectByBlockerAndBlocked(ctx context.Context, blockerID, blockedID int64) (domain.Block, error) {
+
+	var block domain.Block
+
+	row := b.db.QueryRowContext(ctx,
+		"SELECT id, blocker_id, blocked_id, created_at FROM blocks WHERE blocker_id = $1 AND blocked_id = $2",
+		blockerID, blockedID)
+
+	err := row.Scan(&block.ID, &block.BlockerID, &block.BlockedID, &block.CreatedAt)
+	if err != nil {
+		// If no rows found, return empty block (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.Block{}, nil
+		}
+		return domain.Block{}, err
+	}
+
+	return block, nil
+}
+
+// SelectBlocksPage returns the blocks made by a user, most recent first, using keyset
+// pagination: only rows older than maxID are returned (0 starts from the newest).
+func (b Block) SelectBlocksPage(ctx context.Context, blockerID, maxID int64, limit int) ([]domain.Block, error) {
+
+	query := `
+		SELECT id, blocker_id, blocked_id, created_at
+		FROM blocks
+		WHERE blocker_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := b.db.QueryContext(ctx, query, blockerID, maxID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []domain.Block
+	for rows.Next() {
+		var block domain.Block
+		if err := rows.Scan(&block.ID, &block.BlockerID, &block.BlockedID, &block.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ExistsBetween reports whether either user blocks the other.
+func (b Block) ExistsBetween(ctx context.Context, userID, otherUserID int64) (bool, error) {
+
+	var exists bool
+
+	row := b.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM blocks WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1))",
+		userID, otherUserID)
+
+	if err := row.Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// SelectBlockedOrBlockingIDs returns the users blocked by userID and the users blocking userID.
+// Their tweets are hidden from each other.
+func (b Block) SelectBlockedOrBlockingIDs(ctx context.Context, userID int64) ([]int64, error) {
+
+	query := `
+		SELECT blocked_id FROM blocks WHERE blocker_id = $1
+		UNION
+		SELECT blocker_id FROM blocks WHERE blocked_id = $1
+	`
+
+	return selectIDs(ctx, b.db, query, userID)
+}
+
+// selectIDs runs a query returning a single ID column.
+func selectIDs(ctx context.Context, db *pkg.Postgres, query string, args ...any) ([]int64, error) {
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
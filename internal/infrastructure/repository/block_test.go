@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var blockColumnNames = []string{"id", "blocker_id", "blocked_id", "created_at"}
+
+// expectBlockInsert expects the INSERT of a block of user 1 by user 2 at the start of the transaction.
+func expectBlockInsert(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO blocks \\(blocker_id, blocked_id\\) VALUES \\(\\$1, \\$2\\) RETURNING id, blocker_id, blocked_id, created_at").
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(blockColumnNames).AddRow(7, 1, 2, time.Now()))
+}
+
+// expectUnfollow expects the removal of the follow of followedID by followerID, and the
+// count updates when the follow existed.
+func expectUnfollow(mock sqlmock.Sqlmock, followerID, followedID int64, existed bool) {
+	if !existed {
+		mock.ExpectExec("DELETE FROM followers WHERE follower_id = \\$1 AND followed_id = \\$2").
+			WithArgs(followerID, followedID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		return
+	}
+
+	mock.ExpectExec("DELETE FROM followers WHERE follower_id = \\$1 AND followed_id = \\$2").
+		WithArgs(followerID, followedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET following_count = following_count \\+ \\$1 WHERE id = \\$2").
+		WithArgs(-1, followerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET followers_count = followers_count \\+ \\$1 WHERE id = \\$2").
+		WithArgs(-1, followedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectFollowRequestDelete(mock sqlmock.Sqlmock, requesterID, targetID int64) {
+	mock.ExpectExec("DELETE FROM follow_requests WHERE requester_id = \\$1 AND target_id = \\$2").
+		WithArgs(requesterID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestBlock_Insert_WithExistingEdges(t *testing.T) {
+	// Arrange: users 1 and 2 follow each other
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", [][2]int64{{1, 2}, {2, 1}, {1, 3}})
+	repo := NewBlock(&pkg.Postgres{DB: db}, graph)
+
+	expectBlockInsert(mock)
+	expectUnfollow(mock, 1, 2, true)
+	expectFollowRequestDelete(mock, 1, 2)
+	expectUnfollow(mock, 2, 1, true)
+	expectFollowRequestDelete(mock, 2, 1)
+	mock.ExpectCommit()
+
+	// Act
+	block, err := repo.Insert(ctx, domain.Block{BlockerID: 1, BlockedID: 2})
+
+	// Assert: both edges are removed from the graph once committed, the others are kept
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), block.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	following, _ := graph.Following(ctx, 1)
+	followers, _ := graph.Followers(ctx, 1)
+	assert.Equal(t, []int64{3}, following)
+	assert.Empty(t, followers)
+}
+
+func TestBlock_Insert_WithoutExistingEdges(t *testing.T) {
+	// Arrange: only user 2 asked to follow user 1, no follow exists
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", [][2]int64{{2, 3}})
+	repo := NewBlock(&pkg.Postgres{DB: db}, graph)
+
+	// No count is updated when no follow was removed
+	expectBlockInsert(mock)
+	expectUnfollow(mock, 1, 2, false)
+	expectFollowRequestDelete(mock, 1, 2)
+	expectUnfollow(mock, 2, 1, false)
+	mock.ExpectExec("DELETE FROM follow_requests WHERE requester_id = \\$1 AND target_id = \\$2").
+		WithArgs(int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	block, err := repo.Insert(ctx, domain.Block{BlockerID: 1, BlockedID: 2})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), block.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	following, _ := graph.Following(ctx, 2)
+	assert.Equal(t, []int64{3}, following)
+}
+
+func TestBlock_Insert_RollbackOnFailedCountUpdate(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", [][2]int64{{1, 2}})
+	repo := NewBlock(&pkg.Postgres{DB: db}, graph)
+
+	expectBlockInsert(mock)
+	mock.ExpectExec("DELETE FROM followers WHERE follower_id = \\$1 AND followed_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET following_count = following_count \\+ \\$1 WHERE id = \\$2").
+		WithArgs(-1, int64(1)).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	// Act
+	block, err := repo.Insert(ctx, domain.Block{BlockerID: 1, BlockedID: 2})
+
+	// Assert: nothing is committed and the graph keeps the edge
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), block.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	following, _ := graph.Following(ctx, 1)
+	assert.Equal(t, []int64{2}, following)
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+type DraftRepository interface {
+	SelectByID(ctx context.Context, id int64) (domain.Draft, error)
+	SelectByUserID(ctx context.Context, userID int64) ([]domain.Draft, error)
+	Insert(ctx context.Context, draft domain.Draft) (domain.Draft, error)
+	UpdateByID(ctx context.Context, id int64, draft domain.Draft) (domain.Draft, error)
+	Delete(ctx context.Context, id int64) error
+	Claim(ctx context.Context, id, userID int64) (domain.Draft, error)
+}
+
+type Draft struct {
+	db *pkg.Postgres
+}
+
+func NewDraft(db *pkg.Postgres) Draft {
+	return Draft{
+		db: db,
+	}
+}
+
+func (d Draft) SelectByID(ctx context.Context, id int64) (domain.Draft, error) {
+
+	var draft domain.Draft
+
+	row := d.db.QueryRowContext(ctx, "SELECT id, user_id, content, created_at, updated_at FROM drafts WHERE id = $1", id)
+
+	err := row.Scan(&draft.ID, &draft.UserID, &draft.Content, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		// If no rows found, return empty draft (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.Draft{}, nil
+		}
+		return domain.Draft{}, err
+	}
+
+	return draft, nil
+}
+
+func (d Draft) SelectByUserID(ctx context.Context, userID int64) ([]domain.Draft, error) {
+
+	rows, err := d.db.QueryContext(ctx, "SELECT id, user_id, content, created_at, updated_at FROM drafts WHERE user_id = $1 ORDER BY updated_at DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drafts []domain.Draft
+	for rows.Next() {
+		var draft domain.Draft
+		err := rows.Scan(&draft.ID, &draft.UserID, &draft.Content, &draft.CreatedAt, &draft.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+func (d Draft) Insert(ctx context.Context, draft domain.Draft) (domain.Draft, error) {
+
+	var newDraft domain.Draft
+
+	row := d.db.QueryRowContext(ctx, "INSERT INTO drafts (user_id, content) VALUES ($1, $2) RETURNING id, user_id, content, created_at, updated_at", draft.UserID, draft.Content)
+
+	err := row.Scan(&newDraft.ID, &newDraft.UserID, &newDraft.Content, &newDraft.CreatedAt, &newDraft.UpdatedAt)
+	if err != nil {
+		return domain.Draft{}, err
+	}
+
+	return newDraft, nil
+}
+
+func (d Draft) UpdateByID(ctx context.Context, id int64, draft domain.Draft) (domain.Draft, error) {
+
+	var updatedDraft domain.Draft
+
+	row := d.db.QueryRowContext(ctx, "UPDATE drafts SET content = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 RETURNING id, user_id, content, created_at, updated_at", draft.Content, id)
+
+	err := row.Scan(&updatedDraft.ID, &updatedDraft.UserID, &updatedDraft.Content, &updatedDraft.CreatedAt, &updatedDraft.UpdatedAt)
+	if err != nil {
+		return domain.Draft{}, err
+	}
+
+	return updatedDraft, nil
+}
+
+func (d Draft) Delete(ctx context.Context, id int64) error {
+
+	result, err := d.db.ExecContext(ctx, "DELETE FROM drafts WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("draft not found")
+	}
+
+	return nil
+}
+
+// Claim deletes a draft of a user and returns it, so only one of concurrent requests
+// gets it. If it is already gone, an empty draft (ID will be 0) is returned without error.
+func (d Draft) Claim(ctx context.Context, id, userID int64) (domain.Draft, error) {
+
+	var draft domain.Draft
+
+	row := d.db.QueryRowContext(ctx, "DELETE FROM drafts WHERE id = $1 AND user_id = $2 RETURNING id, user_id, content, created_at, updated_at", id, userID)
+
+	err := row.Scan(&draft.ID, &draft.UserID, &draft.Content, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Draft{}, nil
+		}
+		return domain.Draft{}, err
+	}
+
+	return draft, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDraft_Claim_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDraft(&pkg.Postgres{DB: db})
+
+	mock.ExpectQuery("DELETE FROM drafts WHERE id = \\$1 AND user_id = \\$2 RETURNING id, user_id, content, created_at, updated_at").
+		WithArgs(int64(3), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "content", "created_at", "updated_at"}).
+			AddRow(3, 2, "almost ready", time.Now(), time.Now()))
+
+	// Act
+	draft, err := repo.Claim(context.Background(), 3, 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), draft.ID)
+	assert.Equal(t, "almost ready", draft.Content)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDraft_Claim_AlreadyClaimed(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDraft(&pkg.Postgres{DB: db})
+
+	// A concurrent request deleted it first
+	mock.ExpectQuery("DELETE FROM drafts WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(3), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "content", "created_at", "updated_at"}))
+
+	// Act
+	draft, err := repo.Claim(context.Background(), 3, 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), draft.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
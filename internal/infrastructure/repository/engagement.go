@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+// EngagementRepository reads the interactions between users and tweets (replies and
+// retweets), used to rank timelines.
+type EngagementRepository interface {
+	SelectEngagementCounts(ctx context.Context, tweetIDs []int64) (map[int64]domain.TweetEngagement, error)
+	SelectInteractionCounts(ctx context.Context, userID int64, since time.Time) (map[int64]int, error)
+	SelectNetworkEngagedTweetIDs(ctx context.Context, userID int64, since time.Time, limit int, excludeUserIDs []int64) (map[int64]int, error)
+}
+
+type Engagement struct {
+	db *pkg.Postgres
+}
+
+func NewEngagement(db *pkg.Postgres) Engagement {
+	return Engagement{
+		db: db,
+	}
+}
+
+// SelectEngagementCounts returns how many replies and retweets each tweet got, by tweet ID.
+// Tweets nobody interacted with are missing from the result.
+func (e Engagement) SelectEngagementCounts(ctx context.Context, tweetIDs []int64) (map[int64]domain.TweetEngagement, error) {
+
+	counts := make(map[int64]domain.TweetEngagement)
+	if len(tweetIDs) == 0 {
+		return counts, nil
+	}
+
+	// A tweet is either a reply or a retweet, never both
+	query := `
+		SELECT COALESCE(reply_to_tweet_id, retweet_of_id) AS target_id, COUNT(reply_to_tweet_id), COUNT(retweet_of_id)
+		FROM tweets
+		WHERE reply_to_tweet_id = ANY($1) OR retweet_of_id = ANY($1)
+		GROUP BY target_id
+	`
+
+	rows, err := e.db.QueryContext(ctx, query, pq.Array(tweetIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tweetID int64
+		var engagement domain.TweetEngagement
+		if err := rows.Scan(&tweetID, &engagement.Replies, &engagement.Retweets); err != nil {
+			return nil, err
+		}
+		counts[tweetID] = engagement
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// SelectInteractionCounts returns how many tweets of each author userID replied to or
+// retweeted since the given time, by author ID.
+func (e Engagement) SelectInteractionCounts(ctx context.Context, userID int64, since time.Time) (map[int64]int, error) {
+
+	query := `
+		SELECT t.user_id, COUNT(*)
+		FROM tweets e
+		INNER JOIN tweets t ON t.id = COALESCE(e.reply_to_tweet_id, e.retweet_of_id)
+		WHERE e.user_id = $1
+		  AND e.created_at > $2
+		  AND t.user_id <> $1
+		GROUP BY t.user_id
+	`
+
+	return e.selectCounts(ctx, query, userID, since)
+}
+
+// SelectNetworkEngagedTweetIDs returns tweets from outside userID's network (written by
+// users they don't follow) that the users they follow replied to or retweeted since the
+// given time. The result maps each tweet ID to how many followed users engaged with it,
+// keeping the limit most engaged tweets. Tweets written by excludeUserIDs or by protected
+// accounts are left out.
+func (e Engagement) SelectNetworkEngagedTweetIDs(ctx context.Context, userID int64, since time.Time, limit int, excludeUserIDs []int64) (map[int64]int, error) {
+
+	query := `
+		SELECT t.id, COUNT(DISTINCT e.user_id) AS engaged_by
+		FROM tweets e
+		INNER JOIN tweets t ON t.id = COALESCE(e.reply_to_tweet_id, e.retweet_of_id)
+		INNER JOIN users u ON u.id = t.user_id
+		WHERE e.user_id IN (SELECT followed_id FROM followers WHERE follower_id = $1)
+		  AND e.created_at > $2
+		  AND t.user_id <> $1
+		  AND t.user_id NOT IN (SELECT followed_id FROM followers WHERE follower_id = $1)
+		  AND t.user_id <> ALL($3)
+		  AND NOT u.protected
+		GROUP BY t.id
+		ORDER BY engaged_by DESC, t.id DESC
+		LIMIT $4
+	`
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	return e.selectCounts(ctx, query, userID, since, pq.Array(excludeUserIDs), limit)
+}
+
+// selectCounts runs a query returning (id, count) rows and maps each ID to its count.
+func (e Engagement) selectCounts(ctx context.Context, query string, args ...any) (map[int64]int, error) {
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngagement_SelectEngagementCounts_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEngagement(&pkg.Postgres{DB: db})
+
+	rows := sqlmock.NewRows([]string{"target_id", "replies", "retweets"}).
+		AddRow(int64(10), 2, 1).
+		AddRow(int64(11), 0, 4)
+
+	mock.ExpectQuery("WHERE reply_to_tweet_id = ANY\\(\\$1\\) OR retweet_of_id = ANY\\(\\$1\\)").
+		WithArgs("{10,11,12}").
+		WillReturnRows(rows)
+
+	// Act
+	counts, err := repo.SelectEngagementCounts(context.Background(), []int64{10, 11, 12})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]domain.TweetEngagement{
+		10: {Replies: 2, Retweets: 1},
+		11: {Retweets: 4},
+	}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEngagement_SelectEngagementCounts_NoTweets(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEngagement(&pkg.Postgres{DB: db})
+
+	// Act
+	counts, err := repo.SelectEngagementCounts(context.Background(), nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+type FollowImportRepository interface {
+	Insert(ctx context.Context, followImport domain.FollowImport) (domain.FollowImport, error)
+	SelectByID(ctx context.Context, id int64) (domain.FollowImport, error)
+	ClaimPending(ctx context.Context, limit int, leaseTimeout time.Duration) ([]domain.FollowImport, error)
+	RecordResult(ctx context.Context, id int64, target, outcome, reason string) error
+	MarkCompleted(ctx context.Context, id int64) error
+}
+
+type FollowImport struct {
+	db *pkg.Postgres
+}
+
+func NewFollowImport(db *pkg.Postgres) FollowImport {
+	return FollowImport{
+		db: db,
+	}
+}
+
+const followImportColumns = "id, user_id, targets, status, processed_count, followed_count, requested_count, skipped_count, failed_count, created_at, updated_at"
+
+// followImportCounters maps the outcome of a target to the counter it increments.
+var followImportCounters = map[string]string{
+	domain.FollowImportFollowed:  "followed_count",
+	domain.FollowImportRequested: "requested_count",
+	domain.FollowImportSkipped:   "skipped_count",
+	domain.FollowImportFailed:    "failed_count",
+}
+
+func (f FollowImport) Insert(ctx context.Context, followImport domain.FollowImport) (domain.FollowImport, error) {
+
+	row := f.db.QueryRowContext(ctx,
+		"INSERT INTO follow_imports (user_id, targets) VALUES ($1, $2) RETURNING "+followImportColumns,
+		followImport.UserID, pq.Array(followImport.Targets))
+
+	return scanFollowImport(row)
+}
+
+// SelectByID returns an import with its failures.
+func (f FollowImport) SelectByID(ctx context.Context, id int64) (domain.FollowImport, error) {
+
+	row := f.db.QueryRowContext(ctx, "SELECT "+followImportColumns+" FROM follow_imports WHERE id = $1", id)
+
+	followImport, err := scanFollowImport(row)
+	if err != nil {
+		// If no rows found, return empty import (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.FollowImport{}, nil
+		}
+		return domain.FollowImport{}, err
+	}
+
+	rows, err := f.db.QueryContext(ctx, "SELECT target, reason FROM follow_import_failures WHERE import_id = $1 ORDER BY id ASC", id)
+	if err != nil {
+		return domain.FollowImport{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var failure domain.FollowImportFailure
+		if err := rows.Scan(&failure.Target, &failure.Reason); err != nil {
+			return domain.FollowImport{}, err
+		}
+		followImport.Failures = append(followImport.Failures, failure)
+	}
+
+	if err = rows.Err(); err != nil {
+		return domain.FollowImport{}, err
+	}
+
+	return followImport, nil
+}
+
+// ClaimPending marks up to limit pending imports as processing and returns them.
+// FOR UPDATE SKIP LOCKED lets several workers claim concurrently without ever
+// picking the same row. Imports of a worker that died are claimed again once
+// leaseTimeout has passed without progress.
+func (f FollowImport) ClaimPending(ctx context.Context, limit int, leaseTimeout time.Duration) ([]domain.FollowImport, error) {
+
+	query := `
+		UPDATE follow_imports
+		SET status = 'processing', claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id
+			FROM follow_imports
+			WHERE status = 'pending' OR (status = 'processing' AND claimed_at < CURRENT_TIMESTAMP - $2 * INTERVAL '1 second')
+			ORDER BY id ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + followImportColumns
+
+	rows, err := f.db.QueryContext(ctx, query, limit, leaseTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followImports []domain.FollowImport
+	for rows.Next() {
+		followImport, err := scanFollowImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		followImports = append(followImports, followImport)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return followImports, nil
+}
+
+// RecordResult saves the outcome of the next target of an import: the progress and the
+// matching counter are incremented, the lease is renewed and failures are kept with their reason.
+func (f FollowImport) RecordResult(ctx context.Context, id int64, target, outcome, reason string) error {
+
+	counter, ok := followImportCounters[outcome]
+	if !ok {
+		return fmt.Errorf("unknown follow import outcome %q", outcome)
+	}
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE follow_imports SET processed_count = processed_count + 1, "+counter+" = "+counter+" + 1, claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+		id)
+	if err != nil {
+		return err
+	}
+
+	if outcome == domain.FollowImportFailed {
+		_, err = tx.ExecContext(ctx, "INSERT INTO follow_import_failures (import_id, target, reason) VALUES ($1, $2, $3)", id, target, reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (f FollowImport) MarkCompleted(ctx context.Context, id int64) error {
+
+	_, err := f.db.ExecContext(ctx,
+		"UPDATE follow_imports SET status = 'completed', updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+		id)
+
+	return err
+}
+
+// scanFollowImport scans a single row selected with followImportColumns.
+func scanFollowImport(row interface{ Scan(dest ...any) error }) (domain.FollowImport, error) {
+
+	var followImport domain.FollowImport
+
+	err := row.Scan(&followImport.ID, &followImport.UserID, pq.Array(&followImport.Targets), &followImport.Status,
+		&followImport.ProcessedCount, &followImport.FollowedCount, &followImport.RequestedCount, &followImport.SkippedCount,
+		&followImport.FailedCount, &followImport.CreatedAt, &followImport.UpdatedAt)
+	if err != nil {
+		return domain.FollowImport{}, err
+	}
+
+	return followImport, nil
+}
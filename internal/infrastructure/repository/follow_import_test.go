@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var followImportColumnNames = []string{"id", "user_id", "targets", "status", "processed_count", "followed_count", "requested_count", "skipped_count", "failed_count", "created_at", "updated_at"}
+
+func TestFollowImport_ClaimPending(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollowImport(&pkg.Postgres{DB: db})
+
+	// Imports of a worker whose lease expired are claimed again with their progress
+	mock.ExpectQuery("UPDATE follow_imports SET status = 'processing', claimed_at = CURRENT_TIMESTAMP"+
+		".+WHERE status = 'pending' OR \\(status = 'processing' AND claimed_at < CURRENT_TIMESTAMP - \\$2 \\* INTERVAL '1 second'\\)"+
+		".+ORDER BY id ASC LIMIT \\$1 FOR UPDATE SKIP LOCKED \\)").
+		WithArgs(5, float64(60)).
+		WillReturnRows(sqlmock.NewRows(followImportColumnNames).
+			AddRow(9, 1, "{alice,bob,4}", domain.FollowImportProcessing, 2, 1, 1, 0, 0, time.Now(), time.Now()))
+
+	// Act
+	followImports, err := repo.ClaimPending(context.Background(), 5, time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, followImports, 1)
+	assert.Equal(t, []string{"alice", "bob", "4"}, followImports[0].Targets)
+	assert.Equal(t, 2, followImports[0].ProcessedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollowImport_RecordResult_Followed(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollowImport(&pkg.Postgres{DB: db})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE follow_imports SET processed_count = processed_count \\+ 1, followed_count = followed_count \\+ 1, claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1").
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	err = repo.RecordResult(context.Background(), 9, "alice", domain.FollowImportFollowed, "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollowImport_RecordResult_Failed(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollowImport(&pkg.Postgres{DB: db})
+
+	// The failure is kept with its reason, in the same transaction as the progress
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE follow_imports SET processed_count = processed_count \\+ 1, failed_count = failed_count \\+ 1").
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO follow_import_failures \\(import_id, target, reason\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs(int64(9), "carol", "user not found").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	// Act
+	err = repo.RecordResult(context.Background(), 9, "carol", domain.FollowImportFailed, "user not found")
+
+	// Assert: nothing is recorded, the target is processed again when the import resumes
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollowImport_RecordResult_UnknownOutcome(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollowImport(&pkg.Postgres{DB: db})
+
+	// Act
+	err = repo.RecordResult(context.Background(), 9, "alice", "ignored", "")
+
+	// Assert
+	assert.EqualError(t, err, `unknown follow import outcome "ignored"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+type FollowRequestRepository interface {
+	Insert(ctx context.Context, followRequest domain.FollowRequest) (domain.FollowRequest, error)
+	Delete(ctx context.Context, requesterID, targetID int64) error
+	SelectByRequesterAndTarget(ctx context.Context, requesterID, targetID int64) (domain.FollowRequest, error)
+	SelectPendingPage(ctx context.Context, targetID, maxID int64, limit int) ([]domain.FollowRequest, error)
+	Approve(ctx context.Context, requesterID, targetID int64) (domain.Follower, error)
+	ApproveAll(ctx context.Context, targetID int64) ([]int64, error)
+}
+
+type FollowRequest struct {
+	db    *pkg.Postgres
+	graph SocialGraph
+}
+
+func NewFollowRequest(db *pkg.Postgres, graph SocialGraph) FollowRequest {
+	return FollowRequest{
+		db:    db,
+		graph: graph,
+	}
+}
+
+func (f FollowRequest) Insert(ctx context.Context, followRequest domain.FollowRequest) (domain.FollowRequest, error) {
+
+	var newFollowRequest domain.FollowRequest
+
+	row := f.db.QueryRowContext(ctx,
+		"INSERT INTO follow_requests (requester_id, target_id) VALUES ($1, $2) RETURNING id, requester_id, target_id, created_at",
+		followRequest.RequesterID, followRequest.TargetID)
+
+	err := row.Scan(&newFollowRequest.ID, &newFollowRequest.RequesterID, &newFollowRequest.TargetID, &newFollowRequest.CreatedAt)
+	if err != nil {
+		return domain.FollowRequest{}, err
+	}
+
+	return newFollowRequest, nil
+}
+
+func (f FollowRequest) Delete(ctx context.Context, requesterID, targetID int64) error {
+
+	result, err := f.db.ExecContext(ctx,
+		"DELETE FROM follow_requests WHERE requester_id = $1 AND target_id = $2",
+		requesterID, targetID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("follow request not found")
+	}
+
+	return nil
+}
+
+func (f FollowRequest) SelectByRequesterAndTarget(ctx context.Context, requesterID, targetID int64) (domain.FollowRequest, error) {
+
+	var followRequest domain.FollowRequest
+
+	row := f.db.QueryRowContext(ctx,
+		"SELECT id, requester_id, target_id, created_at FROM follow_requests WHERE requester_id = $1 AND target_id = $2",
+		requesterID, targetID)
+
+	err := row.Scan(&followRequest.ID, &followRequest.RequesterID, &followRequest.TargetID, &followRequest.CreatedAt)
+	if err != nil {
+		// If no rows found, return empty follow request (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.FollowRequest{}, nil
+		}
+		return domain.FollowRequest{}, err
+	}
+
+	return followRequest, nil
+}
+
+// SelectPendingPage returns the requests waiting for a user's approval, most recent first,
+// using keyset pagination: only rows older than maxID are returned (0 starts from the newest).
+func (f FollowRequest) SelectPendingPage(ctx context.Context, targetID, maxID int64, limit int) ([]domain.FollowRequest, error) {
+
+	query := `
+		SELECT id, requester_id, target_id, created_at
+		FROM follow_requests
+		WHERE target_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := f.db.QueryContext(ctx, query, targetID, maxID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followRequests []domain.FollowRequest
+	for rows.Next() {
+		var followRequest domain.FollowRequest
+		if err := rows.Scan(&followRequest.ID, &followRequest.RequesterID, &followRequest.TargetID, &followRequest.CreatedAt); err != nil {
+			return nil, err
+		}
+		followRequests = append(followRequests, followRequest)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return followRequests, nil
+}
+
+// Approve turns a pending request into a follow relationship: the request is removed,
+// the relationship created and the follow counts updated in the same transaction.
+func (f FollowRequest) Approve(ctx context.Context, requesterID, targetID int64) (domain.Follower, error) {
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"DELETE FROM follow_requests WHERE requester_id = $1 AND target_id = $2",
+		requesterID, targetID)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domain.Follower{}, err
+	}
+
+	if rowsAffected == 0 {
+		return domain.Follower{}, fmt.Errorf("follow request not found")
+	}
+
+	var newFollower domain.Follower
+
+	row := tx.QueryRowContext(ctx,
+		"INSERT INTO followers (follower_id, followed_id) VALUES ($1, $2) RETURNING id, follower_id, followed_id, created_at",
+		requesterID, targetID)
+
+	err = row.Scan(&newFollower.ID, &newFollower.FollowerID, &newFollower.FollowedID, &newFollower.CreatedAt)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+
+	if err = updateFollowCounts(ctx, tx, requesterID, targetID, 1); err != nil {
+		return domain.Follower{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Follower{}, err
+	}
+
+	addGraphEdge(ctx, f.graph, requesterID, targetID)
+
+	return newFollower, nil
+}
+
+// ApproveAll approves every pending request to follow targetID, when the account stops
+// being protected. It works like Approve in a single transaction and returns the IDs of
+// the new followers.
+func (f FollowRequest) ApproveAll(ctx context.Context, targetID int64) ([]int64, error) {
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH approved AS (
+			DELETE FROM follow_requests WHERE target_id = $1 RETURNING requester_id
+		)
+		INSERT INTO followers (follower_id, followed_id)
+		SELECT requester_id, $1 FROM approved
+		ON CONFLICT DO NOTHING
+		RETURNING follower_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var followerIDs []int64
+	for rows.Next() {
+		var followerID int64
+		if err := rows.Scan(&followerID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		followerIDs = append(followerIDs, followerID)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(followerIDs) == 0 {
+		return nil, tx.Commit()
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET following_count = following_count + 1 WHERE id = ANY($1)", pq.Array(followerIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET followers_count = followers_count + $1 WHERE id = $2", len(followerIDs), targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, followerID := range followerIDs {
+		addGraphEdge(ctx, f.graph, followerID, targetID)
+	}
+
+	return followerIDs, nil
+}
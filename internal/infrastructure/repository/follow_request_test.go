@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowRequest_Approve_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", nil)
+	repo := NewFollowRequest(&pkg.Postgres{DB: db}, graph)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM follow_requests WHERE requester_id = \\$1 AND target_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO followers \\(follower_id, followed_id\\) VALUES \\(\\$1, \\$2\\) RETURNING id, follower_id, followed_id, created_at").
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "follower_id", "followed_id", "created_at"}).AddRow(7, 1, 2, time.Now()))
+	mock.ExpectExec("UPDATE users SET following_count = following_count \\+ \\$1 WHERE id = \\$2").
+		WithArgs(1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET followers_count = followers_count \\+ \\$1 WHERE id = \\$2").
+		WithArgs(1, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	follower, err := repo.Approve(ctx, 1, 2)
+
+	// Assert: the relationship is in the graph once committed
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), follower.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	following, _ := graph.Following(ctx, 1)
+	assert.Equal(t, []int64{2}, following)
+}
+
+func TestFollowRequest_Approve_NotFound(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", nil)
+	repo := NewFollowRequest(&pkg.Postgres{DB: db}, graph)
+
+	// A request rejected or cancelled meanwhile creates no relationship
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM follow_requests WHERE requester_id = \\$1 AND target_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Act
+	follower, err := repo.Approve(ctx, 1, 2)
+
+	// Assert
+	assert.EqualError(t, err, "follow request not found")
+	assert.Equal(t, int64(0), follower.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	following, _ := graph.Following(ctx, 1)
+	assert.Empty(t, following)
+}
+
+func TestFollowRequest_Delete(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollowRequest(&pkg.Postgres{DB: db}, nil)
+
+	mock.ExpectExec("DELETE FROM follow_requests WHERE requester_id = \\$1 AND target_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err = repo.Delete(context.Background(), 1, 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"twitter-demo/internal/domain"
 	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
 )
 
 type FollowerRepository interface {
@@ -13,37 +16,70 @@ type FollowerRepository interface {
 	Delete(ctx context.Context, followerID, followedID int64) error
 	SelectByFollowerAndFollowed(ctx context.Context, followerID, followedID int64) (domain.Follower, error)
 	SelectFollowerIDsByFollowedID(ctx context.Context, followedID int64) ([]int64, error)
+	SelectFollowersPage(ctx context.Context, followedID, maxID int64, limit int) ([]domain.Follower, error)
+	SelectFollowingPage(ctx context.Context, followerID, maxID int64, limit int) ([]domain.Follower, error)
+	SelectFollowedIDsAmong(ctx context.Context, followerID int64, ids []int64) ([]int64, error)
+	SelectFollowerIDsPage(ctx context.Context, afterID int64, limit int) ([]int64, error)
+	SelectFriendsOfFriends(ctx context.Context, userID int64, limit, maxFollowedBy int) ([]domain.Suggestion, error)
 }
 
 type Follower struct {
-	db *pkg.Postgres
+	db    *pkg.Postgres
+	graph SocialGraph
 }
 
-func NewFollower(db *pkg.Postgres) Follower {
+func NewFollower(db *pkg.Postgres, graph SocialGraph) Follower {
 	return Follower{
-		db: db,
+		db:    db,
+		graph: graph,
 	}
 }
 
+// Insert creates a follow relationship and updates the denormalized counts of
+// both users in the same transaction.
 func (f Follower) Insert(ctx context.Context, follower domain.Follower) (domain.Follower, error) {
 
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+	defer tx.Rollback()
+
 	var newFollower domain.Follower
 
-	row := f.db.QueryRowContext(ctx,
+	row := tx.QueryRowContext(ctx,
 		"INSERT INTO followers (follower_id, followed_id) VALUES ($1, $2) RETURNING id, follower_id, followed_id, created_at",
 		follower.FollowerID, follower.FollowedID)
 
-	err := row.Scan(&newFollower.ID, &newFollower.FollowerID, &newFollower.FollowedID, &newFollower.CreatedAt)
+	err = row.Scan(&newFollower.ID, &newFollower.FollowerID, &newFollower.FollowedID, &newFollower.CreatedAt)
 	if err != nil {
 		return domain.Follower{}, err
 	}
 
+	if err = updateFollowCounts(ctx, tx, follower.FollowerID, follower.FollowedID, 1); err != nil {
+		return domain.Follower{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Follower{}, err
+	}
+
+	addGraphEdge(ctx, f.graph, follower.FollowerID, follower.FollowedID)
+
 	return newFollower, nil
 }
 
+// Delete removes a follow relationship and updates the denormalized counts of
+// both users in the same transaction.
 func (f Follower) Delete(ctx context.Context, followerID, followedID int64) error {
 
-	result, err := f.db.ExecContext(ctx,
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
 		"DELETE FROM followers WHERE follower_id = $1 AND followed_id = $2",
 		followerID, followedID)
 
@@ -60,9 +96,47 @@ func (f Follower) Delete(ctx context.Context, followerID, followedID int64) erro
 		return fmt.Errorf("follower relationship not found")
 	}
 
+	if err = updateFollowCounts(ctx, tx, followerID, followedID, -1); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	removeGraphEdge(ctx, f.graph, followerID, followedID)
+
 	return nil
 }
 
+// updateFollowCounts adds delta to the following count of the follower and
+// to the followers count of the followed user.
+func updateFollowCounts(ctx context.Context, tx *sql.Tx, followerID, followedID int64, delta int) error {
+
+	_, err := tx.ExecContext(ctx, "UPDATE users SET following_count = following_count + $1 WHERE id = $2", delta, followerID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET followers_count = followers_count + $1 WHERE id = $2", delta, followedID)
+	return err
+}
+
+// addGraphEdge mirrors a committed follow in the social graph. The relationship is already
+// stored, so a failure is only logged: the graph catches up when it is reloaded.
+func addGraphEdge(ctx context.Context, graph SocialGraph, followerID, followedID int64) {
+	if err := graph.AddEdge(ctx, followerID, followedID); err != nil {
+		log.Printf("Failed to add edge %d -> %d to the social graph: %v", followerID, followedID, err)
+	}
+}
+
+// removeGraphEdge mirrors a committed unfollow in the social graph, see addGraphEdge.
+func removeGraphEdge(ctx context.Context, graph SocialGraph, followerID, followedID int64) {
+	if err := graph.RemoveEdge(ctx, followerID, followedID); err != nil {
+		log.Printf("Failed to remove edge %d -> %d from the social graph: %v", followerID, followedID, err)
+	}
+}
+
 func (f Follower) SelectByFollowerAndFollowed(ctx context.Context, followerID, followedID int64) (domain.Follower, error) {
 
 	var follower domain.Follower
@@ -83,28 +157,123 @@ func (f Follower) SelectByFollowerAndFollowed(ctx context.Context, followerID, f
 	return follower, nil
 }
 
+// SelectFollowerIDsByFollowedID returns who follows a user, read from the social graph.
 func (f Follower) SelectFollowerIDsByFollowedID(ctx context.Context, followedID int64) ([]int64, error) {
-	rows, err := f.db.QueryContext(ctx,
-		"SELECT follower_id FROM followers WHERE followed_id = $1",
-		followedID)
+	return f.graph.Followers(ctx, followedID)
+}
+
+// SelectFollowersPage returns who follows a user, most recent first, using keyset
+// pagination on the relationship ID: only rows older than maxID are returned (0 starts from the newest).
+func (f Follower) SelectFollowersPage(ctx context.Context, followedID, maxID int64, limit int) ([]domain.Follower, error) {
+
+	query := `
+		SELECT id, follower_id, followed_id, created_at
+		FROM followers
+		WHERE followed_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	return f.selectFollowers(ctx, query, followedID, maxID, limit)
+}
+
+// SelectFollowingPage returns who a user follows, most recent first, using keyset
+// pagination on the relationship ID: only rows older than maxID are returned (0 starts from the newest).
+func (f Follower) SelectFollowingPage(ctx context.Context, followerID, maxID int64, limit int) ([]domain.Follower, error) {
+
+	query := `
+		SELECT id, follower_id, followed_id, created_at
+		FROM followers
+		WHERE follower_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	return f.selectFollowers(ctx, query, followerID, maxID, limit)
+}
+
+func (f Follower) selectFollowers(ctx context.Context, query string, args ...any) ([]domain.Follower, error) {
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followers []domain.Follower
+	for rows.Next() {
+		var follower domain.Follower
+		if err := rows.Scan(&follower.ID, &follower.FollowerID, &follower.FollowedID, &follower.CreatedAt); err != nil {
+			return nil, err
+		}
+		followers = append(followers, follower)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return followers, nil
+}
+
+// SelectFollowedIDsAmong returns which of the given users are followed by followerID.
+func (f Follower) SelectFollowedIDsAmong(ctx context.Context, followerID int64, ids []int64) ([]int64, error) {
+
+	if len(ids) == 0 {
+		return []int64{}, nil
+	}
+
+	return selectIDs(ctx, f.db, "SELECT followed_id FROM followers WHERE follower_id = $1 AND followed_id = ANY($2)", followerID, pq.Array(ids))
+}
+
+// SelectFollowerIDsPage returns the users that follow at least one account, ordered by ID,
+// starting after afterID. Only they can get friends-of-friends suggestions.
+func (f Follower) SelectFollowerIDsPage(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	return selectIDs(ctx, f.db, "SELECT DISTINCT follower_id FROM followers WHERE follower_id > $1 ORDER BY follower_id LIMIT $2", afterID, limit)
+}
+
+// SelectFriendsOfFriends returns the accounts followed by the people userID follows,
+// ranked by how many of them follow each account. Accounts userID already follows or
+// asked to follow, muted accounts, blocks in either direction and userID itself are excluded.
+// Up to maxFollowedBy of the mutual connections, most recently followed first, are returned with each suggestion.
+func (f Follower) SelectFriendsOfFriends(ctx context.Context, userID int64, limit, maxFollowedBy int) ([]domain.Suggestion, error) {
+
+	query := `
+		SELECT f2.followed_id, COUNT(*) AS mutual_count, (ARRAY_AGG(f1.followed_id ORDER BY f1.id DESC))[1:$3]
+		FROM followers f1
+		INNER JOIN followers f2 ON f2.follower_id = f1.followed_id
+		WHERE f1.follower_id = $1
+		  AND f2.followed_id <> $1
+		  AND NOT EXISTS (SELECT 1 FROM followers f3 WHERE f3.follower_id = $1 AND f3.followed_id = f2.followed_id)
+		  AND NOT EXISTS (SELECT 1 FROM follow_requests r WHERE r.requester_id = $1 AND r.target_id = f2.followed_id)
+		  AND NOT EXISTS (SELECT 1 FROM mutes m WHERE m.muter_id = $1 AND m.muted_id = f2.followed_id)
+		  AND NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id = $1 AND b.blocked_id = f2.followed_id) OR (b.blocker_id = f2.followed_id AND b.blocked_id = $1)
+		  )
+		GROUP BY f2.followed_id
+		ORDER BY mutual_count DESC, f2.followed_id DESC
+		LIMIT $2
+	`
 
+	rows, err := f.db.QueryContext(ctx, query, userID, limit, maxFollowedBy)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var followerIDs []int64
+	var suggestions []domain.Suggestion
 	for rows.Next() {
-		var followerID int64
-		if err := rows.Scan(&followerID); err != nil {
+		var suggestion domain.Suggestion
+		if err := rows.Scan(&suggestion.UserID, &suggestion.MutualCount, pq.Array(&suggestion.FollowedByIDs)); err != nil {
 			return nil, err
 		}
-		followerIDs = append(followerIDs, followerID)
+		suggestions = append(suggestions, suggestion)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return followerIDs, nil
+	return suggestions, nil
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollower_SelectFriendsOfFriends(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewFollower(&pkg.Postgres{DB: db}, NewPostgresGraph(&pkg.Postgres{DB: db}))
+
+	// Followed and requested accounts, mutes and blocks in both directions are excluded
+	mock.ExpectQuery("SELECT f2.followed_id, COUNT\\(\\*\\) AS mutual_count, \\(ARRAY_AGG\\(f1.followed_id ORDER BY f1.id DESC\\)\\)\\[1:\\$3\\]"+
+		".+f2.followed_id <> \\$1"+
+		".+NOT EXISTS \\(SELECT 1 FROM followers f3 WHERE f3.follower_id = \\$1 AND f3.followed_id = f2.followed_id\\)"+
+		".+NOT EXISTS \\(SELECT 1 FROM follow_requests r WHERE r.requester_id = \\$1 AND r.target_id = f2.followed_id\\)"+
+		".+NOT EXISTS \\(SELECT 1 FROM mutes m WHERE m.muter_id = \\$1 AND m.muted_id = f2.followed_id\\)"+
+		".+\\(b.blocker_id = \\$1 AND b.blocked_id = f2.followed_id\\) OR \\(b.blocker_id = f2.followed_id AND b.blocked_id = \\$1\\)"+
+		".+ORDER BY mutual_count DESC, f2.followed_id DESC").
+		WithArgs(int64(1), 20, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"followed_id", "mutual_count", "followed_by"}).
+			AddRow(42, 5, "{7,3,9}").
+			AddRow(8, 1, "{3}"))
+
+	// Act
+	suggestions, err := repo.SelectFriendsOfFriends(context.Background(), 1, 20, 3)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Suggestion{
+		{UserID: 42, MutualCount: 5, FollowedByIDs: []int64{7, 3, 9}},
+		{UserID: 8, MutualCount: 1, FollowedByIDs: []int64{3}},
+	}, suggestions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"twitter-demo/pkg"
+)
+
+// SocialGraph answers traversal questions about the follow relationships, where an
+// edge goes from a follower to the user they follow.
+type SocialGraph interface {
+	AddEdge(ctx context.Context, followerID, followedID int64) error
+	RemoveEdge(ctx context.Context, followerID, followedID int64) error
+	Following(ctx context.Context, userID int64) ([]int64, error)
+	Followers(ctx context.Context, userID int64) ([]int64, error)
+	Mutuals(ctx context.Context, userID int64) ([]int64, error)
+	WithinHops(ctx context.Context, userID int64, hops int) (map[int64]int, error)
+	Degree(ctx context.Context, userID int64) (followers int, following int, err error)
+}
+
+// PostgresGraph is the social graph stored in the followers table.
+type PostgresGraph struct {
+	db *pkg.Postgres
+}
+
+func NewPostgresGraph(db *pkg.Postgres) PostgresGraph {
+	return PostgresGraph{
+		db: db,
+	}
+}
+
+// AddEdge does nothing: the followers table is the graph and the edge was written
+// by the transaction that created the relationship.
+func (p PostgresGraph) AddEdge(ctx context.Context, followerID, followedID int64) error {
+	return nil
+}
+
+// RemoveEdge does nothing: the followers table is the graph and the edge was deleted
+// by the transaction that removed the relationship.
+func (p PostgresGraph) RemoveEdge(ctx context.Context, followerID, followedID int64) error {
+	return nil
+}
+
+// Following returns the users userID follows.
+func (p PostgresGraph) Following(ctx context.Context, userID int64) ([]int64, error) {
+	return selectIDs(ctx, p.db, "SELECT followed_id FROM followers WHERE follower_id = $1", userID)
+}
+
+// Followers returns the users following userID.
+func (p PostgresGraph) Followers(ctx context.Context, userID int64) ([]int64, error) {
+	return selectIDs(ctx, p.db, "SELECT follower_id FROM followers WHERE followed_id = $1", userID)
+}
+
+// Mutuals returns the users userID follows and who follow userID back.
+func (p PostgresGraph) Mutuals(ctx context.Context, userID int64) ([]int64, error) {
+
+	query := `
+		SELECT f1.followed_id
+		FROM followers f1
+		INNER JOIN followers f2 ON f2.follower_id = f1.followed_id AND f2.followed_id = f1.follower_id
+		WHERE f1.follower_id = $1
+	`
+
+	return selectIDs(ctx, p.db, query, userID)
+}
+
+// WithinHops returns the users reachable from userID by following at most hops edges,
+// with the smallest number of hops needed to reach each of them. userID itself is left out.
+func (p PostgresGraph) WithinHops(ctx context.Context, userID int64, hops int) (map[int64]int, error) {
+
+	query := `
+		WITH RECURSIVE reachable(user_id, hops) AS (
+			SELECT followed_id, 1 FROM followers WHERE follower_id = $1
+			UNION
+			SELECT f.followed_id, r.hops + 1
+			FROM reachable r
+			INNER JOIN followers f ON f.follower_id = r.user_id
+			WHERE r.hops < $2
+		)
+		SELECT user_id, MIN(hops) FROM reachable WHERE user_id <> $1 GROUP BY user_id
+	`
+
+	reachable := make(map[int64]int)
+	if hops <= 0 {
+		return reachable, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, userID, hops)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var distance int
+		if err := rows.Scan(&id, &distance); err != nil {
+			return nil, err
+		}
+		reachable[id] = distance
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reachable, nil
+}
+
+// Degree counts the edges of userID: how many users follow them and how many they follow.
+func (p PostgresGraph) Degree(ctx context.Context, userID int64) (int, int, error) {
+
+	var followers, following int
+
+	row := p.db.QueryRowContext(ctx,
+		"SELECT (SELECT COUNT(*) FROM followers WHERE followed_id = $1), (SELECT COUNT(*) FROM followers WHERE follower_id = $1)",
+		userID)
+
+	if err := row.Scan(&followers, &following); err != nil {
+		return 0, 0, err
+	}
+
+	return followers, following, nil
+}
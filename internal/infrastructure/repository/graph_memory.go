@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"twitter-demo/pkg"
+)
+
+// MemoryGraph is the social graph kept in an in-process adjacency list, in both directions.
+// It is loaded from the followers table (or from its snapshot on disk) and then kept up to date
+// by the writes of this process. Writes made by other processes are picked up when it is reloaded,
+// or as soon as their events are consumed by the worker.
+type MemoryGraph struct {
+	mu           sync.RWMutex
+	following    map[int64]map[int64]struct{}
+	followers    map[int64]map[int64]struct{}
+	db           *pkg.Postgres
+	snapshotPath string
+
+	// refreshMu serializes the reloads. While one is running, the edits are recorded
+	// in pending too, and replayed on the reloaded graph before it replaces this one.
+	refreshMu  sync.Mutex
+	refreshing bool
+	pending    []graphEdit
+}
+
+// graphEdit is an edge added or removed while the graph was being reloaded.
+type graphEdit struct {
+	followerID int64
+	followedID int64
+	removed    bool
+}
+
+// NewMemoryGraph creates an empty graph. snapshotPath is where it is persisted, empty to keep it in memory only.
+func NewMemoryGraph(db *pkg.Postgres, snapshotPath string) *MemoryGraph {
+	return &MemoryGraph{
+		following:    make(map[int64]map[int64]struct{}),
+		followers:    make(map[int64]map[int64]struct{}),
+		db:           db,
+		snapshotPath: snapshotPath,
+	}
+}
+
+// Load fills the graph from its snapshot when there is one, so that startup doesn't
+// scan the followers table, and from Postgres otherwise.
+func (m *MemoryGraph) Load(ctx context.Context) error {
+
+	if m.snapshotPath != "" {
+		err := m.LoadSnapshot()
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to load graph snapshot %s, loading from Postgres: %v", m.snapshotPath, err)
+		}
+	}
+
+	return m.Refresh(ctx)
+}
+
+// Refresh reloads every edge from the followers table and persists the result.
+func (m *MemoryGraph) Refresh(ctx context.Context) error {
+
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	// Record the edits made from now on, the rows read may be older than them
+	m.mu.Lock()
+	m.refreshing = true
+	m.pending = nil
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.refreshing = false
+		m.pending = nil
+		m.mu.Unlock()
+	}()
+
+	rows, err := m.db.QueryContext(ctx, "SELECT follower_id, followed_id FROM followers")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	following := make(map[int64]map[int64]struct{})
+	followers := make(map[int64]map[int64]struct{})
+
+	for rows.Next() {
+		var followerID, followedID int64
+		if err := rows.Scan(&followerID, &followedID); err != nil {
+			return err
+		}
+		addToAdjacency(following, followerID, followedID)
+		addToAdjacency(followers, followedID, followerID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for _, edit := range m.pending {
+		if edit.removed {
+			removeFromAdjacency(following, edit.followerID, edit.followedID)
+			removeFromAdjacency(followers, edit.followedID, edit.followerID)
+		} else {
+			addToAdjacency(following, edit.followerID, edit.followedID)
+			addToAdjacency(followers, edit.followedID, edit.followerID)
+		}
+	}
+	m.following = following
+	m.followers = followers
+	m.mu.Unlock()
+
+	if m.snapshotPath == "" {
+		return nil
+	}
+
+	return m.SaveSnapshot()
+}
+
+// Run reloads the graph every interval until ctx is done.
+func (m *MemoryGraph) Run(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Refresh(ctx); err != nil {
+				log.Printf("Failed to refresh social graph: %v", err)
+			}
+		}
+	}
+}
+
+// SaveSnapshot writes the edges to the snapshot file. The file is replaced atomically.
+func (m *MemoryGraph) SaveSnapshot() error {
+
+	m.mu.RLock()
+	edges := make([][2]int64, 0, len(m.following))
+	for followerID, followedIDs := range m.following {
+		for followedID := range followedIDs {
+			edges = append(edges, [2]int64{followerID, followedID})
+		}
+	}
+	m.mu.RUnlock()
+
+	// A unique file in the same directory, so concurrent saves don't share it and the
+	// rename stays on the same filesystem
+	file, err := os.CreateTemp(filepath.Dir(m.snapshotPath), filepath.Base(m.snapshotPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	if err := gob.NewEncoder(file).Encode(edges); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, m.snapshotPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// LoadSnapshot replaces the graph with the edges of the snapshot file.
+func (m *MemoryGraph) LoadSnapshot() error {
+
+	file, err := os.Open(m.snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var edges [][2]int64
+	if err := gob.NewDecoder(file).Decode(&edges); err != nil {
+		return err
+	}
+
+	following := make(map[int64]map[int64]struct{})
+	followers := make(map[int64]map[int64]struct{})
+	for _, edge := range edges {
+		addToAdjacency(following, edge[0], edge[1])
+		addToAdjacency(followers, edge[1], edge[0])
+	}
+
+	m.mu.Lock()
+	m.following = following
+	m.followers = followers
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *MemoryGraph) AddEdge(ctx context.Context, followerID, followedID int64) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addToAdjacency(m.following, followerID, followedID)
+	addToAdjacency(m.followers, followedID, followerID)
+
+	if m.refreshing {
+		m.pending = append(m.pending, graphEdit{followerID: followerID, followedID: followedID})
+	}
+
+	return nil
+}
+
+func (m *MemoryGraph) RemoveEdge(ctx context.Context, followerID, followedID int64) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removeFromAdjacency(m.following, followerID, followedID)
+	removeFromAdjacency(m.followers, followedID, followerID)
+
+	if m.refreshing {
+		m.pending = append(m.pending, graphEdit{followerID: followerID, followedID: followedID, removed: true})
+	}
+
+	return nil
+}
+
+func (m *MemoryGraph) Following(ctx context.Context, userID int64) ([]int64, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return sortedIDs(m.following[userID]), nil
+}
+
+func (m *MemoryGraph) Followers(ctx context.Context, userID int64) ([]int64, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return sortedIDs(m.followers[userID]), nil
+}
+
+func (m *MemoryGraph) Mutuals(ctx context.Context, userID int64) ([]int64, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mutuals := make(map[int64]struct{})
+	for followedID := range m.following[userID] {
+		if _, ok := m.followers[userID][followedID]; ok {
+			mutuals[followedID] = struct{}{}
+		}
+	}
+
+	return sortedIDs(mutuals), nil
+}
+
+// WithinHops walks the graph breadth first, so each user is reached with the smallest number of hops.
+func (m *MemoryGraph) WithinHops(ctx context.Context, userID int64, hops int) (map[int64]int, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	reachable := make(map[int64]int)
+	frontier := []int64{userID}
+
+	for distance := 1; distance <= hops && len(frontier) > 0; distance++ {
+		var next []int64
+		for _, id := range frontier {
+			for followedID := range m.following[id] {
+				if _, seen := reachable[followedID]; seen || followedID == userID {
+					continue
+				}
+				reachable[followedID] = distance
+				next = append(next, followedID)
+			}
+		}
+		frontier = next
+	}
+
+	return reachable, nil
+}
+
+func (m *MemoryGraph) Degree(ctx context.Context, userID int64) (int, int, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.followers[userID]), len(m.following[userID]), nil
+}
+
+func addToAdjacency(adjacency map[int64]map[int64]struct{}, from, to int64) {
+	neighbors, ok := adjacency[from]
+	if !ok {
+		neighbors = make(map[int64]struct{})
+		adjacency[from] = neighbors
+	}
+	neighbors[to] = struct{}{}
+}
+
+func removeFromAdjacency(adjacency map[int64]map[int64]struct{}, from, to int64) {
+	delete(adjacency[from], to)
+	if len(adjacency[from]) == 0 {
+		delete(adjacency, from)
+	}
+}
+
+func sortedIDs(set map[int64]struct{}) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
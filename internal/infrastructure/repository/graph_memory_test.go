@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMemoryGraph(t testing.TB, snapshotPath string, edges [][2]int64) *MemoryGraph {
+	graph := NewMemoryGraph(nil, snapshotPath)
+	for _, edge := range edges {
+		assert.NoError(t, graph.AddEdge(context.Background(), edge[0], edge[1]))
+	}
+	return graph
+}
+
+func TestMemoryGraph_Traversal(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", [][2]int64{{1, 2}, {2, 1}, {1, 3}, {3, 4}, {4, 5}, {2, 4}, {5, 1}})
+
+	// Act
+	following, _ := graph.Following(ctx, 1)
+	followers, _ := graph.Followers(ctx, 1)
+	mutuals, _ := graph.Mutuals(ctx, 1)
+	withinTwoHops, _ := graph.WithinHops(ctx, 1, 2)
+	withinThreeHops, _ := graph.WithinHops(ctx, 1, 3)
+	followersCount, followingCount, _ := graph.Degree(ctx, 1)
+
+	// Assert
+	assert.Equal(t, []int64{2, 3}, following)
+	assert.Equal(t, []int64{2, 5}, followers)
+	assert.Equal(t, []int64{2}, mutuals)
+	assert.Equal(t, map[int64]int{2: 1, 3: 1, 4: 2}, withinTwoHops)
+	assert.Equal(t, map[int64]int{2: 1, 3: 1, 4: 2, 5: 3}, withinThreeHops)
+	assert.Equal(t, 2, followersCount)
+	assert.Equal(t, 2, followingCount)
+}
+
+func TestMemoryGraph_RemoveEdge(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	graph := newTestMemoryGraph(t, "", [][2]int64{{1, 2}, {2, 1}})
+
+	// Act
+	assert.NoError(t, graph.RemoveEdge(ctx, 2, 1))
+	followers, _ := graph.Followers(ctx, 1)
+	mutuals, _ := graph.Mutuals(ctx, 1)
+	followersCount, followingCount, _ := graph.Degree(ctx, 2)
+
+	// Assert
+	assert.Empty(t, followers)
+	assert.Empty(t, mutuals)
+	assert.Equal(t, 1, followersCount)
+	assert.Equal(t, 0, followingCount)
+}
+
+func TestMemoryGraph_Snapshot(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	snapshotPath := filepath.Join(t.TempDir(), "graph.snapshot")
+	graph := newTestMemoryGraph(t, snapshotPath, [][2]int64{{1, 2}, {3, 2}, {2, 3}})
+
+	// Act
+	assert.NoError(t, graph.SaveSnapshot())
+	restored := NewMemoryGraph(nil, snapshotPath)
+	err := restored.Load(ctx)
+	followers, _ := restored.Followers(ctx, 2)
+	following, _ := restored.Following(ctx, 2)
+	files, _ := os.ReadDir(filepath.Dir(snapshotPath))
+
+	// Assert: only the snapshot is left in its directory
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, followers)
+	assert.Equal(t, []int64{3}, following)
+	assert.Len(t, files, 1)
+}
+
+func BenchmarkMemoryGraph_WithinHops(b *testing.B) {
+	ctx := context.Background()
+
+	// Every user follows the next 50 users, on 10000 users
+	var edges [][2]int64
+	for user := int64(1); user <= 10000; user++ {
+		for offset := int64(1); offset <= 50; offset++ {
+			edges = append(edges, [2]int64{user, (user+offset)%10000 + 1})
+		}
+	}
+	graph := newTestMemoryGraph(b, "", edges)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = graph.WithinHops(ctx, int64(i%10000)+1, 2)
+	}
+}
+
+func TestMemoryGraph_RefreshKeepsConcurrentEdits(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	graph := NewMemoryGraph(&pkg.Postgres{DB: db}, "")
+
+	// The rows are read before the edits below, so they still hold 1 -> 2 and miss 3 -> 4
+	mock.ExpectQuery("SELECT follower_id, followed_id FROM followers").
+		WillDelayFor(100 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"follower_id", "followed_id"}).AddRow(1, 2).AddRow(2, 1))
+
+	// Act
+	refreshed := make(chan error)
+	go func() { refreshed <- graph.Refresh(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		graph.mu.RLock()
+		defer graph.mu.RUnlock()
+		return graph.refreshing
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, graph.AddEdge(ctx, 3, 4))
+	assert.NoError(t, graph.RemoveEdge(ctx, 1, 2))
+	assert.NoError(t, <-refreshed)
+
+	following, _ := graph.Following(ctx, 3)
+	followers, _ := graph.Followers(ctx, 2)
+	mutuals, _ := graph.Mutuals(ctx, 1)
+
+	// Assert
+	assert.Equal(t, []int64{4}, following)
+	assert.Empty(t, followers)
+	assert.Empty(t, mutuals)
+	assert.Empty(t, graph.pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// BenchmarkPostgresGraph_WithinHops runs the same traversal as BenchmarkMemoryGraph_WithinHops
+// on Postgres. It needs a database to write to, e.g.
+// GRAPH_BENCHMARK_DSN="host=localhost user=postgres password=postgres dbname=twitter sslmode=disable".
+// The edges are stored in a graph_benchmark schema, dropped at the end.
+func BenchmarkPostgresGraph_WithinHops(b *testing.B) {
+	dsn := os.Getenv("GRAPH_BENCHMARK_DSN")
+	if dsn == "" {
+		b.Skip("GRAPH_BENCHMARK_DSN is not set")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("postgres", dsn+" search_path=graph_benchmark")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	// Every user follows the next 50 users, on 10000 users
+	setup := []string{
+		"DROP SCHEMA IF EXISTS graph_benchmark CASCADE",
+		"CREATE SCHEMA graph_benchmark",
+		"CREATE TABLE followers (follower_id INT NOT NULL, followed_id INT NOT NULL)",
+		"INSERT INTO followers SELECT u, (u + o) % 10000 + 1 FROM generate_series(1, 10000) u, generate_series(1, 50) o",
+		"CREATE INDEX ON followers (follower_id)",
+		"CREATE INDEX ON followers (followed_id)",
+		"ANALYZE followers",
+	}
+	for _, statement := range setup {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			b.Fatal(err)
+		}
+	}
+	defer db.ExecContext(ctx, "DROP SCHEMA graph_benchmark CASCADE")
+
+	graph := NewPostgresGraph(&pkg.Postgres{DB: db})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := graph.WithinHops(ctx, int64(i%10000)+1, 2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
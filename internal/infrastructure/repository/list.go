@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+type ListRepository interface {
+	SelectByID(ctx context.Context, id int64) (domain.List, error)
+	SelectByOwnerID(ctx context.Context, ownerID int64) ([]domain.List, error)
+	SelectSubscribedLists(ctx context.Context, userID int64) ([]domain.List, error)
+	Insert(ctx context.Context, list domain.List) (domain.List, error)
+	UpdateByID(ctx context.Context, id int64, list domain.List) (domain.List, error)
+	Delete(ctx context.Context, id int64) error
+	InsertMember(ctx context.Context, listID, userID int64) error
+	DeleteMember(ctx context.Context, listID, userID int64) error
+	IsMember(ctx context.Context, listID, userID int64) (bool, error)
+	SelectMemberIDs(ctx context.Context, listID int64) ([]int64, error)
+	SelectListIDsByMemberID(ctx context.Context, userID int64) ([]int64, error)
+	InsertSubscriber(ctx context.Context, listID, userID int64) error
+	DeleteSubscriber(ctx context.Context, listID, userID int64) error
+	IsSubscriber(ctx context.Context, listID, userID int64) (bool, error)
+}
+
+type List struct {
+	db *pkg.Postgres
+}
+
+func NewList(db *pkg.Postgres) List {
+	return List{
+		db: db,
+	}
+}
+
+const listColumns = "id, owner_id, name, description, private, members_count, subscribers_count, created_at, updated_at"
+
+func (l List) SelectByID(ctx context.Context, id int64) (domain.List, error) {
+
+	row := l.db.QueryRowContext(ctx, "SELECT "+listColumns+" FROM lists WHERE id = $1", id)
+
+	list, err := scanList(row)
+	if err != nil {
+		// If no rows found, return empty list (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.List{}, nil
+		}
+		return domain.List{}, err
+	}
+
+	return list, nil
+}
+
+func (l List) SelectByOwnerID(ctx context.Context, ownerID int64) ([]domain.List, error) {
+
+	rows, err := l.db.QueryContext(ctx, "SELECT "+listColumns+" FROM lists WHERE owner_id = $1 ORDER BY id DESC", ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLists(rows)
+}
+
+// SelectSubscribedLists returns the lists a user subscribed to, most recent subscription first.
+func (l List) SelectSubscribedLists(ctx context.Context, userID int64) ([]domain.List, error) {
+
+	query := `
+		SELECT l.id, l.owner_id, l.name, l.description, l.private, l.members_count, l.subscribers_count, l.created_at, l.updated_at
+		FROM lists l
+		INNER JOIN list_subscribers s ON s.list_id = l.id
+		WHERE s.user_id = $1
+		ORDER BY s.id DESC
+	`
+
+	rows, err := l.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLists(rows)
+}
+
+func (l List) Insert(ctx context.Context, list domain.List) (domain.List, error) {
+
+	row := l.db.QueryRowContext(ctx,
+		"INSERT INTO lists (owner_id, name, description, private) VALUES ($1, $2, $3, $4) RETURNING "+listColumns,
+		list.OwnerID, list.Name, list.Description, list.Private)
+
+	return scanList(row)
+}
+
+func (l List) UpdateByID(ctx context.Context, id int64, list domain.List) (domain.List, error) {
+
+	row := l.db.QueryRowContext(ctx,
+		"UPDATE lists SET name = $1, description = $2, private = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4 RETURNING "+listColumns,
+		list.Name, list.Description, list.Private, id)
+
+	return scanList(row)
+}
+
+// Delete removes a list along with its members and subscribers.
+func (l List) Delete(ctx context.Context, id int64) error {
+
+	result, err := l.db.ExecContext(ctx, "DELETE FROM lists WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("list not found")
+	}
+
+	return nil
+}
+
+// InsertMember adds a user to a list and updates its members count in the same transaction.
+func (l List) InsertMember(ctx context.Context, listID, userID int64) error {
+	return l.changeRelation(ctx, "INSERT INTO list_members (list_id, user_id) VALUES ($1, $2)", "members_count", listID, userID, 1)
+}
+
+// DeleteMember removes a user from a list and updates its members count in the same transaction.
+func (l List) DeleteMember(ctx context.Context, listID, userID int64) error {
+	return l.changeRelation(ctx, "DELETE FROM list_members WHERE list_id = $1 AND user_id = $2", "members_count", listID, userID, -1)
+}
+
+func (l List) IsMember(ctx context.Context, listID, userID int64) (bool, error) {
+	return l.exists(ctx, "SELECT EXISTS (SELECT 1 FROM list_members WHERE list_id = $1 AND user_id = $2)", listID, userID)
+}
+
+func (l List) SelectMemberIDs(ctx context.Context, listID int64) ([]int64, error) {
+	return selectIDs(ctx, l.db, "SELECT user_id FROM list_members WHERE list_id = $1 ORDER BY id DESC", listID)
+}
+
+// SelectListIDsByMemberID returns the lists a user is a member of, i.e. the list
+// timelines their tweets are fanned out to.
+func (l List) SelectListIDsByMemberID(ctx context.Context, userID int64) ([]int64, error) {
+	return selectIDs(ctx, l.db, "SELECT list_id FROM list_members WHERE user_id = $1", userID)
+}
+
+// InsertSubscriber subscribes a user to a list and updates its subscribers count in the same transaction.
+func (l List) InsertSubscriber(ctx context.Context, listID, userID int64) error {
+	return l.changeRelation(ctx, "INSERT INTO list_subscribers (list_id, user_id) VALUES ($1, $2)", "subscribers_count", listID, userID, 1)
+}
+
+// DeleteSubscriber unsubscribes a user from a list and updates its subscribers count in the same transaction.
+func (l List) DeleteSubscriber(ctx context.Context, listID, userID int64) error {
+	return l.changeRelation(ctx, "DELETE FROM list_subscribers WHERE list_id = $1 AND user_id = $2", "subscribers_count", listID, userID, -1)
+}
+
+func (l List) IsSubscriber(ctx context.Context, listID, userID int64) (bool, error) {
+	return l.exists(ctx, "SELECT EXISTS (SELECT 1 FROM list_subscribers WHERE list_id = $1 AND user_id = $2)", listID, userID)
+}
+
+// changeRelation runs a statement on a (list_id, user_id) relation and adds delta to
+// the given count column of the list, in one transaction.
+func (l List) changeRelation(ctx context.Context, statement, countColumn string, listID, userID int64, delta int) error {
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, statement, listID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("list relation not found")
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE lists SET "+countColumn+" = "+countColumn+" + $1 WHERE id = $2", delta, listID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l List) exists(ctx context.Context, query string, args ...any) (bool, error) {
+
+	var exists bool
+
+	if err := l.db.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// scanList scans a single row selected with listColumns.
+func scanList(row interface{ Scan(dest ...any) error }) (domain.List, error) {
+
+	var list domain.List
+
+	err := row.Scan(&list.ID, &list.OwnerID, &list.Name, &list.Description, &list.Private,
+		&list.MembersCount, &list.SubscribersCount, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	return list, nil
+}
+
+func scanLists(rows *sql.Rows) ([]domain.List, error) {
+
+	var lists []domain.List
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+// MentionRepository reads the tweets mentioning users. Mentions are written with their
+// tweet, see Tweet.Insert and Tweet.UpdateByID.
+type MentionRepository interface {
+	SelectMentions(ctx context.Context, userID, maxID int64, limit int, excludeUserIDs []int64) ([]domain.Mention, error)
+	SelectUserIDsByUsernames(ctx context.Context, usernames []string) ([]int64, error)
+}
+
+type Mention struct {
+	db *pkg.Postgres
+}
+
+func NewMention(db *pkg.Postgres) Mention {
+	return Mention{
+		db: db,
+	}
+}
+
+// SelectMentions returns the tweets mentioning userID, newest first, using keyset
+// pagination: only tweets older than maxID are returned (0 starts from the newest).
+// Tweets written by excludeUserIDs (e.g. muted or blocked users) are left out.
+func (m Mention) SelectMentions(ctx context.Context, userID, maxID int64, limit int, excludeUserIDs []int64) ([]domain.Mention, error) {
+
+	query := `
+		SELECT tweet_id, author_id
+		FROM tweet_mentions
+		WHERE user_id = $1
+		  AND ($2 = 0 OR tweet_id < $2)
+		  AND author_id <> ALL($3)
+		ORDER BY tweet_id DESC
+		LIMIT $4
+	`
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	rows, err := m.db.QueryContext(ctx, query, userID, maxID, pq.Array(excludeUserIDs), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mentions := []domain.Mention{}
+	for rows.Next() {
+		var mention domain.Mention
+		if err := rows.Scan(&mention.TweetID, &mention.AuthorID); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, mention)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mentions, nil
+}
+
+// SelectUserIDsByUsernames returns the IDs of the users with the given usernames.
+// Unknown usernames are ignored.
+func (m Mention) SelectUserIDsByUsernames(ctx context.Context, usernames []string) ([]int64, error) {
+
+	if len(usernames) == 0 {
+		return []int64{}, nil
+	}
+
+	return selectIDs(ctx, m.db, "SELECT id FROM users WHERE username = ANY($1)", pq.Array(usernames))
+}
+
+// replaceMentions stores the users mentioned in a tweet, replacing the previous ones.
+// Authors mentioning themselves and unknown usernames are ignored.
+func replaceMentions(ctx context.Context, tx *sql.Tx, tweet domain.Tweet) error {
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tweet_mentions WHERE tweet_id = $1", tweet.ID); err != nil {
+		return err
+	}
+
+	usernames := tweet.Mentions()
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO tweet_mentions (tweet_id, user_id, author_id) SELECT $1, id, $2 FROM users WHERE username = ANY($3) AND id <> $2",
+		tweet.ID, tweet.UserID, pq.Array(usernames))
+
+	return err
+}
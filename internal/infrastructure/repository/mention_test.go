@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMention_SelectMentions_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMention(&pkg.Postgres{DB: db})
+
+	rows := sqlmock.NewRows([]string{"tweet_id", "author_id"}).
+		AddRow(int64(42), int64(7)).
+		AddRow(int64(40), int64(8))
+
+	mock.ExpectQuery("FROM tweet_mentions").
+		WithArgs(int64(1), int64(50), "{3}", 20).
+		WillReturnRows(rows)
+
+	// Act
+	mentions, err := repo.SelectMentions(context.Background(), 1, 50, 20, []int64{3})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Mention{{TweetID: 42, AuthorID: 7}, {TweetID: 40, AuthorID: 8}}, mentions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMention_SelectUserIDsByUsernames_NoUsernames(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMention(&pkg.Postgres{DB: db})
+
+	// Act
+	ids, err := repo.SelectUserIDsByUsernames(context.Background(), nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+type MuteRepository interface {
+	Insert(ctx context.Context, mute domain.Mute) (domain.Mute, error)
+	Delete(ctx context.Context, muterID, mutedID int64) error
+	SelectByMuterAndMuted(ctx context.Context, muterID, mutedID int64) (domain.Mute, error)
+	SelectMutesPage(ctx context.Context, muterID, maxID int64, limit int) ([]domain.Mute, error)
+	SelectMutedIDs(ctx context.Context, muterID int64) ([]int64, error)
+	SelectMuterIDs(ctx context.Context, mutedID int64) ([]int64, error)
+}
+
+type Mute struct {
+	db *pkg.Postgres
+}
+
+func NewMute(db *pkg.Postgres) Mute {
+	return Mute{
+		db: db,
+	}
+}
+
+func (m Mute) Insert(ctx context.Context, mute domain.Mute) (domain.Mute, error) {
+
+	var newMute domain.Mute
+
+	row := m.db.QueryRowContext(ctx,
+		"INSERT INTO mutes (muter_id, muted_id) VALUES ($1, $2) RETURNING id, muter_id, muted_id, created_at",
+		mute.MuterID, mute.MutedID)
+
+	err := row.Scan(&newMute.ID, &newMute.MuterID, &newMute.MutedID, &newMute.CreatedAt)
+	if err != nil {
+		return domain.Mute{}, err
+	}
+
+	return newMute, nil
+}
+
+func (m Mute) Delete(ctx context.Context, muterID, mutedID int64) error {
+
+	result, err := m.db.ExecContext(ctx,
+		"DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2",
+		muterID, mutedID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("mute not found")
+	}
+
+	return nil
+}
+
+func (m Mute) SelectByMuterAndMuted(ctx context.Context, muterID, mutedID int64) (domain.Mute, error) {
+
+	var mute domain.Mute
+
+	row := m.db.QueryRowContext(ctx,
+		"SELECT id, muter_id, muted_id, created_at FROM mutes WHERE muter_id = $1 AND muted_id = $2",
+		muterID, mutedID)
+
+	err := row.Scan(&mute.ID, &mute.MuterID, &mute.MutedID, &mute.CreatedAt)
+	if err != nil {
+		// If no rows found, return empty mute (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.Mute{}, nil
+		}
+		return domain.Mute{}, err
+	}
+
+	return mute, nil
+}
+
+// SelectMutesPage returns the mutes made by a user, most recent first, using keyset
+// pagination: only rows older than maxID are returned (0 starts from the newest).
+func (m Mute) SelectMutesPage(ctx context.Context, muterID, maxID int64, limit int) ([]domain.Mute, error) {
+
+	query := `
+		SELECT id, muter_id, muted_id, created_at
+		FROM mutes
+		WHERE muter_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, muterID, maxID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []domain.Mute
+	for rows.Next() {
+		var mute domain.Mute
+		if err := rows.Scan(&mute.ID, &mute.MuterID, &mute.MutedID, &mute.CreatedAt); err != nil {
+			return nil, err
+		}
+		mutes = append(mutes, mute)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mutes, nil
+}
+
+// SelectMutedIDs returns the users muted by muterID.
+func (m Mute) SelectMutedIDs(ctx context.Context, muterID int64) ([]int64, error) {
+	return selectIDs(ctx, m.db, "SELECT muted_id FROM mutes WHERE muter_id = $1", muterID)
+}
+
+// SelectMuterIDs returns the users who muted mutedID.
+func (m Mute) SelectMuterIDs(ctx context.Context, mutedID int64) ([]int64, error) {
+	return selectIDs(ctx, m.db, "SELECT muter_id FROM mutes WHERE muted_id = $1", mutedID)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+type RelationshipRepository interface {
+	SelectRelationships(ctx context.Context, sourceID int64, targetIDs []int64) ([]domain.Relationship, error)
+}
+
+type Relationship struct {
+	db *pkg.Postgres
+}
+
+func NewRelationship(db *pkg.Postgres) Relationship {
+	return Relationship{
+		db: db,
+	}
+}
+
+// SelectRelationships returns the relationship of sourceID with each of the targets in a single
+// query, in the order of targetIDs. Targets that don't exist are left out.
+func (r Relationship) SelectRelationships(ctx context.Context, sourceID int64, targetIDs []int64) ([]domain.Relationship, error) {
+
+	if len(targetIDs) == 0 {
+		return []domain.Relationship{}, nil
+	}
+
+	query := `
+		SELECT
+			t.id,
+			EXISTS (SELECT 1 FROM followers WHERE follower_id = $1 AND followed_id = t.id),
+			EXISTS (SELECT 1 FROM followers WHERE follower_id = t.id AND followed_id = $1),
+			EXISTS (SELECT 1 FROM blocks WHERE blocker_id = $1 AND blocked_id = t.id),
+			EXISTS (SELECT 1 FROM blocks WHERE blocker_id = t.id AND blocked_id = $1),
+			EXISTS (SELECT 1 FROM mutes WHERE muter_id = $1 AND muted_id = t.id),
+			EXISTS (SELECT 1 FROM follow_requests WHERE requester_id = $1 AND target_id = t.id),
+			EXISTS (SELECT 1 FROM follow_requests WHERE requester_id = t.id AND target_id = $1)
+		FROM UNNEST($2::INT[]) WITH ORDINALITY AS t(id, position)
+		INNER JOIN users u ON u.id = t.id
+		ORDER BY t.position
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sourceID, pq.Array(targetIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	relationships := []domain.Relationship{}
+	for rows.Next() {
+		relationship := domain.Relationship{SourceID: sourceID}
+		err := rows.Scan(&relationship.TargetID, &relationship.Following, &relationship.FollowedBy,
+			&relationship.Blocking, &relationship.BlockedBy, &relationship.Muting,
+			&relationship.FollowRequestSent, &relationship.FollowRequestReceived)
+		if err != nil {
+			return nil, err
+		}
+		relationships = append(relationships, relationship)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relationships, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationship_SelectRelationships_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postgres := &pkg.Postgres{DB: db}
+	repo := NewRelationship(postgres)
+
+	columns := []string{"id", "following", "followed_by", "blocking", "blocked_by", "muting", "follow_request_sent", "follow_request_received"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(int64(3), true, true, false, false, true, false, false).
+		AddRow(int64(2), false, false, false, true, false, false, true)
+
+	mock.ExpectQuery("FROM UNNEST\\(\\$2::INT\\[\\]\\) WITH ORDINALITY").
+		WithArgs(int64(1), "{3,2}").
+		WillReturnRows(rows)
+
+	// Act
+	relationships, err := repo.SelectRelationships(context.Background(), 1, []int64{3, 2})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Relationship{
+		{SourceID: 1, TargetID: 3, Following: true, FollowedBy: true, Muting: true},
+		{SourceID: 1, TargetID: 2, BlockedBy: true, FollowRequestReceived: true},
+	}, relationships)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelationship_SelectRelationships_NoTargets(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRelationship(&pkg.Postgres{DB: db})
+
+	// Act
+	relationships, err := repo.SelectRelationships(context.Background(), 1, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, relationships)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
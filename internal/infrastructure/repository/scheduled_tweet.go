@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+type ScheduledTweetRepository interface {
+	SelectByID(ctx context.Context, id int64) (domain.ScheduledTweet, error)
+	SelectByUserID(ctx context.Context, userID int64, status string) ([]domain.ScheduledTweet, error)
+	Insert(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.ScheduledTweet, error)
+	Cancel(ctx context.Context, id int64) error
+	ClaimDue(ctx context.Context, limit int, leaseTimeout time.Duration) ([]domain.ScheduledTweet, error)
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
+
+type ScheduledTweet struct {
+	db *pkg.Postgres
+}
+
+func NewScheduledTweet(db *pkg.Postgres) ScheduledTweet {
+	return ScheduledTweet{
+		db: db,
+	}
+}
+
+const scheduledTweetColumns = "id, user_id, content, publish_at, status, tweet_id, error, created_at, updated_at"
+
+func (s ScheduledTweet) SelectByID(ctx context.Context, id int64) (domain.ScheduledTweet, error) {
+
+	row := s.db.QueryRowContext(ctx, "SELECT "+scheduledTweetColumns+" FROM scheduled_tweets WHERE id = $1", id)
+
+	scheduledTweet, err := scanScheduledTweet(row)
+	if err != nil {
+		// If no rows found, return empty scheduled tweet (ID will be 0) without error
+		if err == sql.ErrNoRows {
+			return domain.ScheduledTweet{}, nil
+		}
+		return domain.ScheduledTweet{}, err
+	}
+
+	return scheduledTweet, nil
+}
+
+// SelectByUserID returns the scheduled tweets of a user ordered by publish time.
+// An empty status returns them all.
+func (s ScheduledTweet) SelectByUserID(ctx context.Context, userID int64, status string) ([]domain.ScheduledTweet, error) {
+
+	query := `
+		SELECT ` + scheduledTweetColumns + `
+		FROM scheduled_tweets
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY publish_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledTweets(rows)
+}
+
+func (s ScheduledTweet) Insert(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.ScheduledTweet, error) {
+
+	row := s.db.QueryRowContext(ctx,
+		"INSERT INTO scheduled_tweets (user_id, content, publish_at) VALUES ($1, $2, $3) RETURNING "+scheduledTweetColumns,
+		scheduledTweet.UserID, scheduledTweet.Content, scheduledTweet.PublishAt)
+
+	return scanScheduledTweet(row)
+}
+
+// Cancel cancels a scheduled tweet that has not been picked up by the scheduler yet.
+func (s ScheduledTweet) Cancel(ctx context.Context, id int64) error {
+
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE scheduled_tweets SET status = 'canceled', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = 'pending'",
+		id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("scheduled tweet is no longer pending")
+	}
+
+	return nil
+}
+
+// ClaimDue marks up to limit due tweets as publishing and returns them.
+// FOR UPDATE SKIP LOCKED lets several workers claim concurrently without ever
+// picking the same row. Rows claimed by a worker that died are claimed again
+// once leaseTimeout has passed.
+func (s ScheduledTweet) ClaimDue(ctx context.Context, limit int, leaseTimeout time.Duration) ([]domain.ScheduledTweet, error) {
+
+	query := `
+		UPDATE scheduled_tweets
+		SET status = 'publishing', claimed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id
+			FROM scheduled_tweets
+			WHERE publish_at <= CURRENT_TIMESTAMP
+			  AND (status = 'pending' OR (status = 'publishing' AND claimed_at < CURRENT_TIMESTAMP - $2 * INTERVAL '1 second'))
+			ORDER BY publish_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledTweetColumns
+
+	rows, err := s.db.QueryContext(ctx, query, limit, leaseTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledTweets(rows)
+}
+
+// MarkFailed records why a scheduled tweet could not be published. A tweet published
+// meanwhile by a worker that reclaimed it is left as it is.
+func (s ScheduledTweet) MarkFailed(ctx context.Context, id int64, reason string) error {
+
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE scheduled_tweets SET status = 'failed', error = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'publishing'",
+		reason, id)
+
+	return err
+}
+
+// scanScheduledTweet scans a single row selected with scheduledTweetColumns.
+func scanScheduledTweet(row interface{ Scan(dest ...any) error }) (domain.ScheduledTweet, error) {
+
+	var scheduledTweet domain.ScheduledTweet
+	var tweetID sql.NullInt64
+	var reason sql.NullString
+
+	err := row.Scan(&scheduledTweet.ID, &scheduledTweet.UserID, &scheduledTweet.Content, &scheduledTweet.PublishAt,
+		&scheduledTweet.Status, &tweetID, &reason, &scheduledTweet.CreatedAt, &scheduledTweet.UpdatedAt)
+	if err != nil {
+		return domain.ScheduledTweet{}, err
+	}
+
+	scheduledTweet.TweetID = tweetID.Int64
+	scheduledTweet.Error = reason.String
+
+	return scheduledTweet, nil
+}
+
+func scanScheduledTweets(rows *sql.Rows) ([]domain.ScheduledTweet, error) {
+
+	var scheduledTweets []domain.ScheduledTweet
+	for rows.Next() {
+		scheduledTweet, err := scanScheduledTweet(rows)
+		if err != nil {
+			return nil, err
+		}
+		scheduledTweets = append(scheduledTweets, scheduledTweet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return scheduledTweets, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var scheduledTweetColumnNames = []string{"id", "user_id", "content", "publish_at", "status", "tweet_id", "error", "created_at", "updated_at"}
+
+func TestScheduledTweet_ClaimDue(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScheduledTweet(&pkg.Postgres{DB: db})
+	publishAt := time.Now().Add(-time.Minute)
+
+	// Due tweets are pending, or claimed by a worker whose lease expired. Rows locked by
+	// another worker are skipped rather than waited for.
+	mock.ExpectQuery("UPDATE scheduled_tweets SET status = 'publishing', claimed_at = CURRENT_TIMESTAMP"+
+		".+WHERE publish_at <= CURRENT_TIMESTAMP"+
+		".+AND \\(status = 'pending' OR \\(status = 'publishing' AND claimed_at < CURRENT_TIMESTAMP - \\$2 \\* INTERVAL '1 second'\\)\\)"+
+		".+ORDER BY publish_at ASC LIMIT \\$1 FOR UPDATE SKIP LOCKED \\)").
+		WithArgs(10, float64(300)).
+		WillReturnRows(sqlmock.NewRows(scheduledTweetColumnNames).
+			AddRow(1, 2, "hello", publishAt, "publishing", nil, nil, publishAt, time.Now()).
+			AddRow(3, 4, "reclaimed", publishAt, "publishing", nil, nil, publishAt, time.Now()))
+
+	// Act
+	claimed, err := repo.ClaimDue(context.Background(), 10, 5*time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, int64(1), claimed[0].ID)
+	assert.Equal(t, "reclaimed", claimed[1].Content)
+	assert.Equal(t, int64(0), claimed[1].TweetID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduledTweet_ClaimDue_NothingDue(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScheduledTweet(&pkg.Postgres{DB: db})
+
+	mock.ExpectQuery("UPDATE scheduled_tweets SET status = 'publishing'").
+		WithArgs(10, float64(300)).
+		WillReturnRows(sqlmock.NewRows(scheduledTweetColumnNames))
+
+	// Act
+	claimed, err := repo.ClaimDue(context.Background(), 10, 5*time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
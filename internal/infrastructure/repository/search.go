@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
+)
+
+// SearchRepository searches tweets by content with the Postgres full-text search.
+type SearchRepository interface {
+	SearchTweets(ctx context.Context, query domain.SearchQuery, viewerID int64, excludeUserIDs []int64, cursor domain.SearchCursor, limit int) ([]domain.SearchResult, error)
+}
+
+type Search struct {
+	db *pkg.Postgres
+}
+
+func NewSearch(db *pkg.Postgres) Search {
+	return Search{
+		db: db,
+	}
+}
+
+// SearchTweets returns the tweets matching a query, sorted by query.Sort, after cursor
+// (the zero cursor starts from the first result). Tweets written by excludeUserIDs (e.g.
+// muted or blocked users) and by protected accounts viewerID doesn't follow are left out.
+func (s Search) SearchTweets(ctx context.Context, query domain.SearchQuery, viewerID int64, excludeUserIDs []int64, cursor domain.SearchCursor, limit int) ([]domain.SearchResult, error) {
+
+	statement, args := buildSearchQuery(query, viewerID, excludeUserIDs, cursor, limit)
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []domain.SearchResult{}
+	for rows.Next() {
+		var result domain.SearchResult
+		tweet := &result.Tweet
+		err := rows.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ReplyToTweetID, &tweet.RetweetOfID, &tweet.EditCount, &tweet.CreatedAt, &tweet.UpdatedAt, &result.Rank)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// buildSearchQuery builds the SQL of a search. Only the filters in use are added, so the
+// GIN index on search_vector is used when there is text to search for.
+func buildSearchQuery(query domain.SearchQuery, viewerID int64, excludeUserIDs []int64, cursor domain.SearchCursor, limit int) (string, []any) {
+
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	// Protected accounts are only visible to themselves and their followers
+	viewer := arg(viewerID)
+	conditions := []string{
+		"t.user_id <> ALL(" + arg(pq.Array(excludeUserIDs)) + ")",
+		"(NOT u.protected OR u.id = " + viewer + " OR EXISTS (SELECT 1 FROM followers f WHERE f.follower_id = " + viewer + " AND f.followed_id = u.id))",
+	}
+
+	rank := "0::real"
+	if query.Text != "" {
+		tsquery := "websearch_to_tsquery('english', " + arg(query.Text) + ")"
+		rank = "ts_rank(t.search_vector, " + tsquery + ")"
+		conditions = append(conditions, "t.search_vector @@ "+tsquery)
+	}
+
+	// The text search ignores the #, the hashtags themselves must be in the content
+	if len(query.Hashtags) > 0 {
+		patterns := make([]string, len(query.Hashtags))
+		for i, hashtag := range query.Hashtags {
+			patterns[i] = `(^|[^[:alnum:]_#])#` + hashtag + `([^[:alnum:]_]|$)`
+		}
+		conditions = append(conditions, "t.content ~* ALL("+arg(pq.Array(patterns))+")")
+	}
+
+	if query.From != "" {
+		conditions = append(conditions, "u.username = "+arg(query.From))
+	}
+
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "t.created_at >= "+arg(query.Since))
+	}
+
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "t.created_at < "+arg(query.Until))
+	}
+
+	if query.SinceID != 0 {
+		conditions = append(conditions, "t.id > "+arg(query.SinceID))
+	}
+
+	order := "t.id DESC"
+	if query.Sort == domain.SearchSortRelevance {
+		order = "rank DESC, t.id DESC"
+		if cursor.TweetID != 0 {
+			conditions = append(conditions, "("+rank+", t.id) < ("+arg(cursor.Rank)+"::real, "+arg(cursor.TweetID)+")")
+		}
+	} else if cursor.TweetID != 0 {
+		conditions = append(conditions, "t.id < "+arg(cursor.TweetID))
+	}
+
+	statement := `
+		SELECT t.id, t.user_id, t.content, COALESCE(t.reply_to_tweet_id, 0), COALESCE(t.retweet_of_id, 0), t.edit_count, t.created_at, t.updated_at, ` + rank + ` AS rank
+		FROM tweets t
+		JOIN users u ON u.id = t.user_id
+		WHERE ` + strings.Join(conditions, "\n\t\t  AND ") + `
+		ORDER BY ` + order + `
+		LIMIT ` + arg(limit)
+
+	return statement, args
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are the words too common to be worth indexing (the English stop set of Lucene).
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true,
+}
+
+// Analyze turns a text into the terms the index stores: it is split into lower-cased
+// words (# and @ are separators, so #golang and @alice are indexed as golang and alice),
+// stop words are dropped and the remaining words are stemmed. Queries are analyzed the
+// same way, so "running" finds "runs".
+func Analyze(text string) []string {
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		terms = append(terms, Stem(word))
+	}
+
+	return terms
+}
+
+// Stem reduces an English word to its stem with the Porter algorithm, e.g. "connections"
+// to "connect". Words with letters outside a-z, and words of up to two letters, are
+// returned as they are.
+func Stem(word string) string {
+
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+
+	return string(s.b)
+}
+
+// stemmer holds a word being stemmed. The conditions of the rules are computed on its
+// first k letters, the stem left when a suffix is removed.
+type stemmer struct {
+	b []byte
+}
+
+// consonant reports whether the letter at i is a consonant. y is a consonant at the start
+// of a word or after a vowel.
+func (s *stemmer) consonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.consonant(i-1)
+	default:
+		return true
+	}
+}
+
+// measure returns m, the number of vowel-consonant sequences of the first k letters:
+// [C](VC)^m[V].
+func (s *stemmer) measure(k int) int {
+
+	i := 0
+	for i < k && s.consonant(i) {
+		i++
+	}
+
+	m := 0
+	for i < k {
+		for i < k && !s.consonant(i) {
+			i++
+		}
+		if i >= k {
+			break
+		}
+		for i < k && s.consonant(i) {
+			i++
+		}
+		m++
+	}
+
+	return m
+}
+
+// hasVowel reports whether the first k letters contain a vowel.
+func (s *stemmer) hasVowel(k int) bool {
+	for i := 0; i < k; i++ {
+		if !s.consonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleConsonant reports whether the first k letters end with a double consonant, e.g. -tt.
+func (s *stemmer) doubleConsonant(k int) bool {
+	return k >= 2 && s.b[k-1] == s.b[k-2] && s.consonant(k-1)
+}
+
+// cvc reports whether the first k letters end with consonant-vowel-consonant, the last
+// one not being w, x or y, e.g. -hop.
+func (s *stemmer) cvc(k int) bool {
+	if k < 3 || !s.consonant(k-3) || s.consonant(k-2) || !s.consonant(k-1) {
+		return false
+	}
+	last := s.b[k-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+func (s *stemmer) endsWith(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replaceSuffix replaces the first suffix of rules the word ends with, when its stem has
+// a measure greater than minMeasure. The other rules are not tried once one matched.
+func (s *stemmer) replaceSuffix(rules [][2]string, minMeasure int) {
+	for _, rule := range rules {
+		if !s.endsWith(rule[0]) {
+			continue
+		}
+		k := len(s.b) - len(rule[0])
+		if s.measure(k) > minMeasure {
+			s.b = append(s.b[:k], rule[1]...)
+		}
+		return
+	}
+}
+
+// step1a removes plurals: caresses -> caress, ponies -> poni, cats -> cat.
+func (s *stemmer) step1a() {
+	switch {
+	case s.endsWith("sses"), s.endsWith("ies"):
+		s.b = s.b[:len(s.b)-2]
+	case s.endsWith("ss"):
+	case s.endsWith("s"):
+		s.b = s.b[:len(s.b)-1]
+	}
+}
+
+// step1b removes -ed and -ing: agreed -> agree, plastered -> plaster, hopping -> hop.
+func (s *stemmer) step1b() {
+
+	if s.endsWith("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.b = s.b[:len(s.b)-1]
+		}
+		return
+	}
+
+	var k int
+	switch {
+	case s.endsWith("ed") && s.hasVowel(len(s.b)-2):
+		k = len(s.b) - 2
+	case s.endsWith("ing") && s.hasVowel(len(s.b)-3):
+		k = len(s.b) - 3
+	default:
+		return
+	}
+	s.b = s.b[:k]
+
+	switch {
+	case s.endsWith("at"), s.endsWith("bl"), s.endsWith("iz"):
+		s.b = append(s.b, 'e')
+	case s.doubleConsonant(k):
+		if last := s.b[k-1]; last != 'l' && last != 's' && last != 'z' {
+			s.b = s.b[:k-1]
+		}
+	case s.measure(k) == 1 && s.cvc(k):
+		s.b = append(s.b, 'e')
+	}
+}
+
+// step1c turns a final y into i after a vowel: happy -> happi.
+func (s *stemmer) step1c() {
+	if s.endsWith("y") && s.hasVowel(len(s.b)-1) {
+		s.b[len(s.b)-1] = 'i'
+	}
+}
+
+// step2Rules map double suffixes to single ones. Longer suffixes come first.
+var step2Rules = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"},
+	{"abli", "able"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+	{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"},
+	{"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+}
+
+// step2 maps double suffixes: relational -> relate, generalization -> generalize.
+func (s *stemmer) step2() {
+	s.replaceSuffix(step2Rules, 0)
+}
+
+var step3Rules = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+// step3 handles -ic-, -full, -ness: hopeful -> hope, goodness -> good.
+func (s *stemmer) step3() {
+	s.replaceSuffix(step3Rules, 0)
+}
+
+// step4Rules are the suffixes removed by step4. -ement and -ment come before -ent.
+var step4Rules = [][2]string{
+	{"al", ""}, {"ance", ""}, {"ence", ""}, {"er", ""}, {"ic", ""}, {"able", ""}, {"ible", ""},
+	{"ant", ""}, {"ement", ""}, {"ment", ""}, {"ent", ""}, {"ou", ""}, {"ism", ""}, {"ate", ""},
+	{"iti", ""}, {"ous", ""}, {"ive", ""}, {"ize", ""},
+}
+
+// step4 removes the remaining suffixes of long stems: adjustment -> adjust.
+func (s *stemmer) step4() {
+
+	// -ion is only removed after s or t: adoption -> adopt, but not onion
+	if s.endsWith("ion") {
+		k := len(s.b) - 3
+		if k > 0 && (s.b[k-1] == 's' || s.b[k-1] == 't') && s.measure(k) > 1 {
+			s.b = s.b[:k]
+		}
+		return
+	}
+
+	s.replaceSuffix(step4Rules, 1)
+}
+
+// step5 removes a final -e and -ll of long stems: probate -> probat, controll -> control.
+func (s *stemmer) step5() {
+
+	if s.endsWith("e") {
+		k := len(s.b) - 1
+		if m := s.measure(k); m > 1 || (m == 1 && !s.cvc(k)) {
+			s.b = s.b[:k]
+		}
+	}
+
+	if k := len(s.b); s.measure(k) > 1 && s.doubleConsonant(k) && s.b[k-1] == 'l' {
+		s.b = s.b[:k-1]
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"rational":       "ration",
+		"generalization": "gener",
+		"running":        "run",
+		"connections":    "connect",
+		"adoption":       "adopt",
+		"controll":       "control",
+		"go":             "go",
+		"café":           "café",
+	}
+
+	for word, expected := range tests {
+		assert.Equal(t, expected, Stem(word), word)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	// Act
+	terms := Analyze("The Gophers are RUNNING to #GoLang meetups with @alice_b!")
+
+	// Assert
+	assert.Equal(t, []string{"gopher", "run", "golang", "meetup", "alice_b"}, terms)
+}
@@ -0,0 +1,668 @@
+package repository
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"twitter-demo/internal/domain"
+)
+
+const (
+	// bm25K1 controls how fast the score of a term saturates with its frequency in a tweet
+	bm25K1 = 1.2
+	// bm25B controls how much the score of a term is lowered in tweets longer than average
+	bm25B = 0.75
+	// manifestFile lists the segments of the index, oldest first
+	manifestFile = "manifest.json"
+	// segmentPattern names the segment files
+	segmentPattern = "segment-%06d.gob"
+)
+
+// SearchIndex is the inverted index of the search service.
+type SearchIndex interface {
+	Index(ctx context.Context, tweet domain.IndexedTweet) error
+	Delete(ctx context.Context, tweetID int64) error
+	Search(ctx context.Context, query string, excludeUserIDs, visibleProtectedIDs []int64, limit, offset int) ([]domain.IndexHit, int, error)
+	SetProtected(ctx context.Context, protected bool, userIDs ...int64) error
+	ProtectedUserIDs(ctx context.Context) ([]int64, error)
+	Flush(ctx context.Context) error
+	Reset(ctx context.Context) error
+	Stats(ctx context.Context) (domain.IndexStats, error)
+}
+
+// indexedDocument is a tweet stored in a segment, with its number of terms.
+type indexedDocument struct {
+	Tweet  domain.IndexedTweet
+	Length int
+}
+
+// posting records that a term occurs Frequency times in a tweet.
+type posting struct {
+	TweetID   int64
+	Frequency int
+}
+
+// segment is a set of tweets with their postings. Segments on disk are immutable: a tweet
+// changed or deleted later is shadowed by a newer segment, and its postings are skipped
+// until the segments are merged.
+type segment struct {
+	Name      string // File name, empty for the segment still in memory
+	Documents map[int64]indexedDocument
+	Postings  map[string][]posting
+	Deleted   map[int64]struct{} // Tombstones of the tweets removed from the older segments
+}
+
+// segmentFile is the encoding of a segment on disk.
+type segmentFile struct {
+	Documents []indexedDocument
+	Postings  map[string][]posting
+	Deleted   []int64
+}
+
+// manifest is the encoding of the list of segments on disk, and of the protected
+// authors. It is replaced atomically, so a flush or a merge interrupted halfway leaves
+// the previous list in place.
+type manifest struct {
+	Segments    []string `json:"segments"`
+	NextSegment int      `json:"next_segment"`
+	Protected   []int64  `json:"protected,omitempty"`
+}
+
+func newSegment() *segment {
+	return &segment{
+		Documents: make(map[int64]indexedDocument),
+		Postings:  make(map[string][]posting),
+		Deleted:   make(map[int64]struct{}),
+	}
+}
+
+// InvertedIndex is an in-process inverted index of tweets, ranked with BM25. Changes go
+// to an in-memory buffer, written to disk as a new segment when it is flushed: every
+// flushSize changes, and whenever Flush is called. Once there are more than maxSegments
+// segments on disk, they are merged into one. Every segment is kept in memory too, and
+// searches wait while a flush or a merge writes to disk.
+// The index also knows which authors are protected, so searches leave their tweets out
+// before ranking and paging them. Changes to it are written to disk right away.
+type InvertedIndex struct {
+	mu          sync.RWMutex
+	dir         string
+	flushSize   int
+	maxSegments int
+	segments    []*segment // On disk, oldest first
+	buffer      *segment   // Changes since the last flush
+	live        map[int64]*segment
+	totalLength int64 // Sum of the lengths of the live tweets, for the average length
+	nextSegment int
+	protected   map[int64]bool // Authors whose tweets are only found by their followers
+}
+
+// NewInvertedIndex creates an empty index persisted in dir.
+func NewInvertedIndex(dir string, flushSize, maxSegments int) *InvertedIndex {
+	return &InvertedIndex{
+		dir:         dir,
+		flushSize:   flushSize,
+		maxSegments: maxSegments,
+		buffer:      newSegment(),
+		live:        make(map[int64]*segment),
+		protected:   make(map[int64]bool),
+	}
+}
+
+// Load reads the segments listed by the manifest of the index directory, creating the
+// directory when it doesn't exist. Segment files left over by an interrupted merge are removed.
+func (x *InvertedIndex) Load() error {
+
+	if err := os.MkdirAll(x.dir, 0o755); err != nil {
+		return err
+	}
+
+	var m manifest
+	data, err := os.ReadFile(filepath.Join(x.dir, manifestFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("failed to parse index manifest: %w", err)
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.segments = nil
+	x.buffer = newSegment()
+	x.live = make(map[int64]*segment)
+	x.totalLength = 0
+	x.nextSegment = m.NextSegment
+	x.protected = make(map[int64]bool, len(m.Protected))
+	for _, userID := range m.Protected {
+		x.protected[userID] = true
+	}
+
+	listed := make(map[string]bool, len(m.Segments))
+	for _, name := range m.Segments {
+		seg, err := x.readSegment(name)
+		if err != nil {
+			return fmt.Errorf("failed to read index segment %s: %w", name, err)
+		}
+
+		// Replay the segments in order: each one shadows the tweets of the older ones
+		for tweetID := range seg.Deleted {
+			x.unlink(tweetID)
+		}
+		for tweetID, document := range seg.Documents {
+			x.unlink(tweetID)
+			x.live[tweetID] = seg
+			x.totalLength += int64(document.Length)
+		}
+
+		x.segments = append(x.segments, seg)
+		listed[name] = true
+	}
+
+	x.removeUnlistedSegments(listed)
+
+	return nil
+}
+
+// Index adds a tweet to the index, or replaces the indexed version when it is older.
+func (x *InvertedIndex) Index(ctx context.Context, tweet domain.IndexedTweet) error {
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if current, ok := x.live[tweet.ID]; ok && current.Documents[tweet.ID].Tweet.UpdatedAt.After(tweet.UpdatedAt) {
+		return nil
+	}
+
+	x.remove(tweet.ID)
+
+	terms := Analyze(tweet.Content)
+	frequencies := make(map[string]int, len(terms))
+	for _, term := range terms {
+		frequencies[term]++
+	}
+
+	x.buffer.Documents[tweet.ID] = indexedDocument{Tweet: tweet, Length: len(terms)}
+	for term, frequency := range frequencies {
+		x.buffer.Postings[term] = append(x.buffer.Postings[term], posting{TweetID: tweet.ID, Frequency: frequency})
+	}
+	x.live[tweet.ID] = x.buffer
+	x.totalLength += int64(len(terms))
+
+	return x.flushIfFull()
+}
+
+// Delete removes a tweet from the index. Unknown tweets are ignored.
+func (x *InvertedIndex) Delete(ctx context.Context, tweetID int64) error {
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.live[tweetID]; !ok {
+		return nil
+	}
+
+	x.remove(tweetID)
+
+	return x.flushIfFull()
+}
+
+// remove drops the live version of a tweet: from the buffer when it is there, with a
+// tombstone when it is on disk.
+func (x *InvertedIndex) remove(tweetID int64) {
+
+	current, ok := x.live[tweetID]
+	if !ok {
+		return
+	}
+
+	if current != x.buffer {
+		x.buffer.Deleted[tweetID] = struct{}{}
+		x.unlink(tweetID)
+		return
+	}
+
+	document := x.buffer.Documents[tweetID]
+	for _, term := range Analyze(document.Tweet.Content) {
+		postings := x.buffer.Postings[term]
+		for i, p := range postings {
+			if p.TweetID == tweetID {
+				postings = append(postings[:i], postings[i+1:]...)
+				break
+			}
+		}
+		if len(postings) == 0 {
+			delete(x.buffer.Postings, term)
+		} else {
+			x.buffer.Postings[term] = postings
+		}
+	}
+	delete(x.buffer.Documents, tweetID)
+	x.unlink(tweetID)
+}
+
+// unlink forgets the live version of a tweet, its postings are skipped from then on.
+func (x *InvertedIndex) unlink(tweetID int64) {
+	if current, ok := x.live[tweetID]; ok {
+		x.totalLength -= int64(current.Documents[tweetID].Length)
+		delete(x.live, tweetID)
+	}
+}
+
+// Search returns a page of the tweets matching any term of query, best BM25 score first
+// (newest first between equal scores), and how many tweets match in total. Tweets
+// written by excludeUserIDs are left out, and so are the tweets of protected authors
+// unless they are in visibleProtectedIDs.
+func (x *InvertedIndex) Search(ctx context.Context, query string, excludeUserIDs, visibleProtectedIDs []int64, limit, offset int) ([]domain.IndexHit, int, error) {
+
+	excluded := make(map[int64]bool, len(excludeUserIDs))
+	for _, userID := range excludeUserIDs {
+		excluded[userID] = true
+	}
+
+	visible := make(map[int64]bool, len(visibleProtectedIDs))
+	for _, userID := range visibleProtectedIDs {
+		visible[userID] = true
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if len(x.live) == 0 {
+		return []domain.IndexHit{}, 0, nil
+	}
+
+	tweetCount := float64(len(x.live))
+	averageLength := float64(x.totalLength) / tweetCount
+	if averageLength == 0 {
+		averageLength = 1
+	}
+
+	segments := append(x.segments[:len(x.segments):len(x.segments)], x.buffer)
+	scores := make(map[int64]float64)
+	seen := make(map[string]bool)
+
+	for _, term := range Analyze(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		// Only the postings of the live version of each tweet count
+		type match struct {
+			seg *segment
+			p   posting
+		}
+		var matches []match
+		for _, seg := range segments {
+			for _, p := range seg.Postings[term] {
+				if x.live[p.TweetID] == seg {
+					matches = append(matches, match{seg: seg, p: p})
+				}
+			}
+		}
+
+		idf := bm25IDF(len(matches), tweetCount)
+		for _, m := range matches {
+			document := m.seg.Documents[m.p.TweetID]
+			authorID := document.Tweet.UserID
+			if excluded[authorID] || (x.protected[authorID] && !visible[authorID]) {
+				continue
+			}
+			scores[m.p.TweetID] += idf * bm25TermWeight(m.p.Frequency, document.Length, averageLength)
+		}
+	}
+
+	tweetIDs := make([]int64, 0, len(scores))
+	for tweetID := range scores {
+		tweetIDs = append(tweetIDs, tweetID)
+	}
+	sort.Slice(tweetIDs, func(i, j int) bool {
+		if scores[tweetIDs[i]] != scores[tweetIDs[j]] {
+			return scores[tweetIDs[i]] > scores[tweetIDs[j]]
+		}
+		return tweetIDs[i] > tweetIDs[j]
+	})
+
+	total := len(tweetIDs)
+	if offset >= total {
+		return []domain.IndexHit{}, total, nil
+	}
+	tweetIDs = tweetIDs[offset:min(offset+limit, total)]
+
+	hits := make([]domain.IndexHit, len(tweetIDs))
+	for i, tweetID := range tweetIDs {
+		hits[i] = domain.IndexHit{Tweet: x.live[tweetID].Documents[tweetID].Tweet, Score: scores[tweetID]}
+	}
+
+	return hits, total, nil
+}
+
+// SetProtected records whether userIDs are protected accounts and writes it to disk.
+func (x *InvertedIndex) SetProtected(ctx context.Context, protected bool, userIDs ...int64) error {
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	changed := false
+	for _, userID := range userIDs {
+		if x.protected[userID] == protected {
+			continue
+		}
+		if protected {
+			x.protected[userID] = true
+		} else {
+			delete(x.protected, userID)
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return x.writeManifest(x.segmentNames())
+}
+
+// ProtectedUserIDs returns the authors recorded as protected accounts.
+func (x *InvertedIndex) ProtectedUserIDs(ctx context.Context) ([]int64, error) {
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	userIDs := make([]int64, 0, len(x.protected))
+	for userID := range x.protected {
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// bm25IDF is the inverse document frequency of a term found in matching of tweetCount
+// tweets. It stays positive for terms found in most tweets.
+func bm25IDF(matching int, tweetCount float64) float64 {
+	return math.Log(1 + (tweetCount-float64(matching)+0.5)/(float64(matching)+0.5))
+}
+
+// bm25TermWeight is the weight of a term occurring frequency times in a tweet of length terms.
+func bm25TermWeight(frequency, length int, averageLength float64) float64 {
+	f := float64(frequency)
+	return f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(length)/averageLength))
+}
+
+// Flush writes the buffered changes to a new segment, then merges the segments when
+// there are too many of them.
+func (x *InvertedIndex) Flush(ctx context.Context) error {
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.flush()
+}
+
+// flushIfFull flushes once the buffer holds flushSize changes. The caller holds the lock.
+func (x *InvertedIndex) flushIfFull() error {
+
+	if x.flushSize <= 0 || len(x.buffer.Documents)+len(x.buffer.Deleted) < x.flushSize {
+		return nil
+	}
+
+	return x.flush()
+}
+
+// flush writes the buffer to disk. The caller holds the lock.
+func (x *InvertedIndex) flush() error {
+
+	if len(x.buffer.Documents) == 0 && len(x.buffer.Deleted) == 0 {
+		return nil
+	}
+
+	name, err := x.writeSegment(x.buffer)
+	if err != nil {
+		return err
+	}
+
+	if err := x.writeManifest(append(x.segmentNames(), name)); err != nil {
+		return err
+	}
+
+	x.buffer.Name = name
+	x.segments = append(x.segments, x.buffer)
+	x.buffer = newSegment()
+
+	if x.maxSegments > 0 && len(x.segments) > x.maxSegments {
+		return x.merge()
+	}
+
+	return nil
+}
+
+// merge replaces the segments on disk by one segment holding their live tweets. Their
+// tombstones are dropped: there is no older segment left for them to apply to. The
+// caller holds the lock.
+func (x *InvertedIndex) merge() error {
+
+	merged := newSegment()
+	for _, seg := range x.segments {
+		for tweetID, document := range seg.Documents {
+			if x.live[tweetID] != seg {
+				continue
+			}
+			merged.Documents[tweetID] = document
+		}
+		for term, postings := range seg.Postings {
+			for _, p := range postings {
+				if x.live[p.TweetID] == seg {
+					merged.Postings[term] = append(merged.Postings[term], p)
+				}
+			}
+		}
+	}
+
+	name, err := x.writeSegment(merged)
+	if err != nil {
+		return err
+	}
+
+	if err := x.writeManifest([]string{name}); err != nil {
+		return err
+	}
+
+	merged.Name = name
+	for tweetID := range merged.Documents {
+		x.live[tweetID] = merged
+	}
+
+	previous := x.segments
+	x.segments = []*segment{merged}
+
+	for _, seg := range previous {
+		if err := os.Remove(filepath.Join(x.dir, seg.Name)); err != nil {
+			log.Printf("Failed to remove merged index segment %s: %v", seg.Name, err)
+		}
+	}
+
+	log.Printf("Merged %d index segments into %s (%d tweets)", len(previous), name, len(merged.Documents))
+
+	return nil
+}
+
+// Reset empties the index, forgets the protected authors and removes its segments from disk.
+func (x *InvertedIndex) Reset(ctx context.Context) error {
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.protected = make(map[int64]bool)
+	if err := x.writeManifest(nil); err != nil {
+		return err
+	}
+
+	x.segments = nil
+	x.buffer = newSegment()
+	x.live = make(map[int64]*segment)
+	x.totalLength = 0
+	x.removeUnlistedSegments(nil)
+
+	return nil
+}
+
+// Stats describes the index.
+func (x *InvertedIndex) Stats(ctx context.Context) (domain.IndexStats, error) {
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	terms := make(map[string]struct{})
+	for _, seg := range append(x.segments[:len(x.segments):len(x.segments)], x.buffer) {
+		for term := range seg.Postings {
+			terms[term] = struct{}{}
+		}
+	}
+
+	return domain.IndexStats{
+		Tweets:   len(x.live),
+		Terms:    len(terms),
+		Segments: len(x.segments),
+		Buffered: len(x.buffer.Documents) + len(x.buffer.Deleted),
+	}, nil
+}
+
+func (x *InvertedIndex) segmentNames() []string {
+	names := make([]string, len(x.segments))
+	for i, seg := range x.segments {
+		names[i] = seg.Name
+	}
+	return names
+}
+
+// writeSegment writes a segment to a new file and returns its name.
+func (x *InvertedIndex) writeSegment(seg *segment) (string, error) {
+
+	file := segmentFile{
+		Documents: make([]indexedDocument, 0, len(seg.Documents)),
+		Postings:  seg.Postings,
+		Deleted:   make([]int64, 0, len(seg.Deleted)),
+	}
+	for _, document := range seg.Documents {
+		file.Documents = append(file.Documents, document)
+	}
+	for tweetID := range seg.Deleted {
+		file.Deleted = append(file.Deleted, tweetID)
+	}
+
+	name := fmt.Sprintf(segmentPattern, x.nextSegment)
+	x.nextSegment++
+
+	err := writeFileAtomically(filepath.Join(x.dir, name), func(f *os.File) error {
+		return gob.NewEncoder(f).Encode(file)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write index segment %s: %w", name, err)
+	}
+
+	return name, nil
+}
+
+func (x *InvertedIndex) readSegment(name string) (*segment, error) {
+
+	f, err := os.Open(filepath.Join(x.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var file segmentFile
+	if err := gob.NewDecoder(f).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	seg := newSegment()
+	seg.Name = name
+	for _, document := range file.Documents {
+		seg.Documents[document.Tweet.ID] = document
+	}
+	if file.Postings != nil {
+		seg.Postings = file.Postings
+	}
+	for _, tweetID := range file.Deleted {
+		seg.Deleted[tweetID] = struct{}{}
+	}
+
+	return seg, nil
+}
+
+func (x *InvertedIndex) writeManifest(names []string) error {
+
+	protected := make([]int64, 0, len(x.protected))
+	for userID := range x.protected {
+		protected = append(protected, userID)
+	}
+	sort.Slice(protected, func(i, j int) bool { return protected[i] < protected[j] })
+
+	data, err := json.Marshal(manifest{Segments: names, NextSegment: x.nextSegment, Protected: protected})
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomically(filepath.Join(x.dir, manifestFile), func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write index manifest: %w", err)
+	}
+
+	return nil
+}
+
+// removeUnlistedSegments removes the segment files of the index directory that are not listed.
+func (x *InvertedIndex) removeUnlistedSegments(listed map[string]bool) {
+
+	names, err := filepath.Glob(filepath.Join(x.dir, strings.Replace(segmentPattern, "%06d", "*", 1)))
+	if err != nil {
+		return
+	}
+
+	for _, path := range names {
+		if listed[filepath.Base(path)] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("Failed to remove index segment %s: %v", path, err)
+		}
+	}
+}
+
+// writeFileAtomically writes a file with write, then moves it in place, so readers never
+// see it half written.
+func writeFileAtomically(path string, write func(f *os.File) error) error {
+
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
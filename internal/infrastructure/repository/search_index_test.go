@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTweet(id, userID int64, content string) domain.IndexedTweet {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(id) * time.Minute)
+	return domain.IndexedTweet{ID: id, UserID: userID, Content: content, CreatedAt: created, UpdatedAt: created}
+}
+
+func hitIDs(hits []domain.IndexHit) []int64 {
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Tweet.ID
+	}
+	return ids
+}
+
+func TestInvertedIndex_SearchRanksWithBM25(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	index := NewInvertedIndex(t.TempDir(), 0, 0)
+	assert.NoError(t, index.Index(ctx, newTestTweet(1, 10, "Learning Go this weekend")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(2, 11, "Go go go! Running the Go meetup tonight")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(3, 12, "Coffee first, then a long walk in the park with the dog")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(4, 13, "Runners run while we learn")))
+
+	// Act
+	goHits, goTotal, _ := index.Search(ctx, "go", nil, nil, 10, 0)
+	learnHits, _, _ := index.Search(ctx, "learned", nil, nil, 10, 0)
+	excludedHits, _, _ := index.Search(ctx, "go", []int64{11}, nil, 10, 0)
+	page, pageTotal, _ := index.Search(ctx, "go running", nil, nil, 1, 1)
+	noHits, noTotal, _ := index.Search(ctx, "tea", nil, nil, 10, 0)
+
+	// Assert
+	assert.Equal(t, []int64{2, 1}, hitIDs(goHits))
+	assert.Equal(t, 2, goTotal)
+	assert.Greater(t, goHits[0].Score, goHits[1].Score)
+	assert.Equal(t, []int64{1, 4}, hitIDs(learnHits), "stems match, the shorter tweet first")
+	assert.Equal(t, []int64{1}, hitIDs(excludedHits))
+	assert.Equal(t, 3, pageTotal)
+	assert.Len(t, page, 1)
+	assert.Empty(t, noHits)
+	assert.Equal(t, 0, noTotal)
+}
+
+func TestInvertedIndex_UpdateAndDelete(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	index := NewInvertedIndex(t.TempDir(), 0, 0)
+	original := newTestTweet(1, 10, "Hello world")
+	assert.NoError(t, index.Index(ctx, original))
+	assert.NoError(t, index.Index(ctx, newTestTweet(2, 10, "Hello again")))
+	assert.NoError(t, index.Flush(ctx))
+
+	edited := original
+	edited.Content = "Goodbye world"
+	edited.UpdatedAt = original.UpdatedAt.Add(time.Minute)
+
+	// Act
+	assert.NoError(t, index.Index(ctx, edited))
+	assert.NoError(t, index.Index(ctx, original)) // Replayed, older than the edit
+	assert.NoError(t, index.Delete(ctx, 2))
+	helloHits, _, _ := index.Search(ctx, "hello", nil, nil, 10, 0)
+	goodbyeHits, _, _ := index.Search(ctx, "goodbye", nil, nil, 10, 0)
+	stats, _ := index.Stats(ctx)
+
+	// Assert
+	assert.Empty(t, helloHits)
+	assert.Equal(t, []int64{1}, hitIDs(goodbyeHits))
+	assert.Equal(t, 1, stats.Tweets)
+	assert.Equal(t, 1, stats.Segments)
+	assert.Equal(t, 3, stats.Buffered) // The edit and the tombstones of both tweets
+}
+
+func TestInvertedIndex_PersistsAndMergesSegments(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	index := NewInvertedIndex(dir, 2, 2)
+	assert.NoError(t, index.Load())
+
+	// Act: every two changes make a segment, the third segment triggers a merge
+	assert.NoError(t, index.Index(ctx, newTestTweet(1, 10, "golang generics")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(2, 10, "golang channels")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(3, 11, "rust lifetimes")))
+	assert.NoError(t, index.Delete(ctx, 1))
+	assert.NoError(t, index.Index(ctx, newTestTweet(4, 11, "golang modules")))
+	assert.NoError(t, index.Flush(ctx))
+	mergedStats, _ := index.Stats(ctx)
+
+	assert.NoError(t, index.Delete(ctx, 2))
+	assert.NoError(t, index.Flush(ctx))
+
+	restored := NewInvertedIndex(dir, 2, 2)
+	err := restored.Load()
+	hits, _, _ := restored.Search(ctx, "golang", nil, nil, 10, 0)
+	stats, _ := restored.Stats(ctx)
+	segmentFiles, _ := filepath.Glob(filepath.Join(dir, "segment-*.gob"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, mergedStats.Segments)
+	assert.Equal(t, []int64{4}, hitIDs(hits))
+	assert.Equal(t, 2, stats.Tweets)
+	assert.Equal(t, 2, stats.Segments)
+	assert.Len(t, segmentFiles, 2)
+}
+
+func TestInvertedIndex_Reset(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	index := NewInvertedIndex(dir, 0, 0)
+	assert.NoError(t, index.Load())
+	assert.NoError(t, index.Index(ctx, newTestTweet(1, 10, "golang")))
+	assert.NoError(t, index.Flush(ctx))
+
+	// Act
+	assert.NoError(t, index.Reset(ctx))
+	restored := NewInvertedIndex(dir, 0, 0)
+	err := restored.Load()
+	stats, _ := restored.Stats(ctx)
+	entries, _ := os.ReadDir(dir)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Tweets)
+	assert.Len(t, entries, 1) // The manifest only
+}
+
+func TestInvertedIndex_LeavesOutProtectedAuthors(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	index := NewInvertedIndex(dir, 0, 0)
+	assert.NoError(t, index.Load())
+	assert.NoError(t, index.Index(ctx, newTestTweet(1, 10, "golang generics")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(2, 11, "golang channels")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(3, 11, "golang modules")))
+	assert.NoError(t, index.Index(ctx, newTestTweet(4, 12, "golang fuzzing")))
+
+	// Act
+	assert.NoError(t, index.SetProtected(ctx, true, 11, 12))
+	assert.NoError(t, index.SetProtected(ctx, false, 12))
+	hidden, hiddenTotal, _ := index.Search(ctx, "golang", nil, nil, 1, 1)
+	followed, followedTotal, _ := index.Search(ctx, "golang", nil, []int64{11}, 10, 0)
+	assert.NoError(t, index.Flush(ctx))
+
+	restored := NewInvertedIndex(dir, 0, 0)
+	err := restored.Load()
+	restoredHits, _, _ := restored.Search(ctx, "golang", nil, nil, 10, 0)
+	protectedIDs, _ := restored.ProtectedUserIDs(ctx)
+
+	// Assert: the total and the pages only count what the viewer can see
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hiddenTotal)
+	assert.Equal(t, []int64{1}, hitIDs(hidden))
+	assert.Equal(t, 4, followedTotal)
+	assert.ElementsMatch(t, []int64{4, 3, 2, 1}, hitIDs(followed))
+	assert.ElementsMatch(t, []int64{4, 1}, hitIDs(restoredHits))
+	assert.Equal(t, []int64{11}, protectedIDs)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch_SearchTweets_ByRelevance(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSearch(&pkg.Postgres{DB: db})
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "content", "reply_to_tweet_id", "retweet_of_id", "edit_count", "created_at", "updated_at", "rank"}).
+		AddRow(int64(9), int64(2), "Learning #golang", int64(0), int64(0), 0, createdAt, createdAt, float32(0.5))
+
+	query := domain.SearchQuery{Text: "golang", Hashtags: []string{"golang"}, From: "alice", Sort: domain.SearchSortRelevance}
+
+	mock.ExpectQuery("t.search_vector @@ websearch_to_tsquery\\('english', \\$3\\).*u.username = \\$5.*ORDER BY rank DESC, t.id DESC").
+		WithArgs(int64(1), "{3}", "golang", `{"(^|[^[:alnum:]_#])#golang([^[:alnum:]_]|$)"}`, "alice", float32(0.75), int64(10), 20).
+		WillReturnRows(rows)
+
+	// Act
+	results, err := repo.SearchTweets(context.Background(), query, 1, []int64{3}, domain.SearchCursor{TweetID: 10, Rank: 0.75}, 20)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, int64(9), results[0].Tweet.ID)
+	assert.Equal(t, float32(0.5), results[0].Rank)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearch_SearchTweets_ByRecency(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSearch(&pkg.Postgres{DB: db})
+
+	query := domain.SearchQuery{From: "alice", SinceID: 5, Sort: domain.SearchSortRecent}
+
+	mock.ExpectQuery("t.id > \\$4.*t.id < \\$5.*ORDER BY t.id DESC").
+		WithArgs(int64(0), "{}", "alice", int64(5), int64(10), 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "content", "reply_to_tweet_id", "retweet_of_id", "edit_count", "created_at", "updated_at", "rank"}))
+
+	// Act
+	results, err := repo.SearchTweets(context.Background(), query, 0, nil, domain.SearchCursor{TweetID: 10}, 20)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
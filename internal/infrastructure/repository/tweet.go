@@ -3,16 +3,30 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 	"twitter-demo/internal/domain"
 	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
 )
 
 type TweetRepository interface {
 	SelectByID(ctx context.Context, id int64) (domain.Tweet, error)
 	Insert(ctx context.Context, tweet domain.Tweet) (domain.Tweet, error)
-	UpdateByID(ctx context.Context, id int64, tweet domain.Tweet) (domain.Tweet, error)
-	SelectTimelineTweets(ctx context.Context, userID int64, limit, offset int) ([]domain.Tweet, error)
+	InsertScheduled(ctx context.Context, scheduledTweetID int64, tweet domain.Tweet) (domain.Tweet, error)
+	UpdateByID(ctx context.Context, id int64, tweet domain.Tweet, maxEdits int, window time.Duration) (domain.Tweet, error)
+	SelectTimelineTweets(ctx context.Context, userID int64, limit, offset int, excludeUserIDs []int64) ([]domain.Tweet, error)
+	SelectListTimelineTweets(ctx context.Context, listID int64, limit, offset int, excludeUserIDs []int64) ([]domain.Tweet, error)
+	SelectTimelineTweetIDs(ctx context.Context, userID int64, limit int, excludeUserIDs []int64) ([]int64, error)
+	SelectListTimelineTweetIDs(ctx context.Context, listID int64, limit int, excludeUserIDs []int64) ([]int64, error)
 	SelectTweetsByIDs(ctx context.Context, ids []int64) ([]domain.Tweet, error)
+	SelectRevisionsByTweetID(ctx context.Context, tweetID int64) ([]domain.TweetRevision, error)
+	SelectUserTweets(ctx context.Context, userID, maxID int64, limit int, filter domain.TweetFilter, excludeID int64) ([]domain.Tweet, error)
+	SelectTweetIDsByAuthors(ctx context.Context, authorIDs []int64, minID int64, limit int) ([]int64, error)
+	SelectTweetsAfter(ctx context.Context, afterID int64, limit int) ([]domain.Tweet, error)
+	SelectPinnedTweetID(ctx context.Context, userID int64) (int64, error)
+	UpsertPinnedTweet(ctx context.Context, userID, tweetID int64) error
+	DeletePinnedTweet(ctx context.Context, userID int64) error
 }
 
 type Tweet struct {
@@ -25,13 +39,15 @@ func NewTweet(db *pkg.Postgres) Tweet {
 	}
 }
 
-func (t Tweet) SelectByID(ctx context.Context, id int64) (domain.Tweet, error) {
+// tweetColumns is the column list scanned by scanTweet.
+// Optional references are returned as 0 instead of NULL.
+const tweetColumns = "id, user_id, content, COALESCE(reply_to_tweet_id, 0), COALESCE(retweet_of_id, 0), edit_count, created_at, updated_at"
 
-	var tweet domain.Tweet
+func (t Tweet) SelectByID(ctx context.Context, id int64) (domain.Tweet, error) {
 
-	row := t.db.QueryRowContext(ctx, "SELECT id, user_id, content, created_at, updated_at FROM tweets WHERE id = $1", id)
+	row := t.db.QueryRowContext(ctx, "SELECT "+tweetColumns+" FROM tweets WHERE id = $1", id)
 
-	err := row.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.CreatedAt, &tweet.UpdatedAt)
+	tweet, err := scanTweet(row)
 	if err != nil {
 		// If no rows found, return empty tweet (ID will be 0) without error
 		if err == sql.ErrNoRows {
@@ -43,66 +59,262 @@ func (t Tweet) SelectByID(ctx context.Context, id int64) (domain.Tweet, error) {
 	return tweet, nil
 }
 
+// Insert stores a new tweet along with the users it mentions, in one transaction.
 func (t Tweet) Insert(ctx context.Context, tweet domain.Tweet) (domain.Tweet, error) {
 
-	var newTweet domain.Tweet
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+	defer tx.Rollback()
+
+	newTweet, err := insertTweet(ctx, tx, tweet)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	return newTweet, nil
+}
 
-	row := t.db.QueryRowContext(ctx, "INSERT INTO tweets (user_id, content) VALUES ($1, $2) RETURNING id, user_id, content, created_at, updated_at", tweet.UserID, tweet.Content)
+// InsertScheduled publishes a scheduled tweet claimed by the scheduler: the tweet is
+// inserted and the scheduled tweet marked as published in one transaction, so a worker
+// reclaiming an expired lease can never publish it a second time. If the scheduled
+// tweet is no longer being published, an empty tweet (ID will be 0) is returned
+// without error.
+func (t Tweet) InsertScheduled(ctx context.Context, scheduledTweetID int64, tweet domain.Tweet) (domain.Tweet, error) {
 
-	err := row.Scan(&newTweet.ID, &newTweet.UserID, &newTweet.Content, &newTweet.CreatedAt, &newTweet.UpdatedAt)
+	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
 		return domain.Tweet{}, err
 	}
+	defer tx.Rollback()
+
+	// Lock the scheduled tweet, a worker publishing it concurrently waits for this one
+	var status string
+	row := tx.QueryRowContext(ctx, "SELECT status FROM scheduled_tweets WHERE id = $1 FOR UPDATE", scheduledTweetID)
+	if err = row.Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Tweet{}, nil
+		}
+		return domain.Tweet{}, err
+	}
+
+	if status != "publishing" {
+		return domain.Tweet{}, nil
+	}
+
+	newTweet, err := insertTweet(ctx, tx, tweet)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE scheduled_tweets SET status = 'published', tweet_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		newTweet.ID, scheduledTweetID)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	return newTweet, nil
+}
+
+// insertTweet inserts a tweet and its mentions within tx.
+func insertTweet(ctx context.Context, tx *sql.Tx, tweet domain.Tweet) (domain.Tweet, error) {
+
+	row := tx.QueryRowContext(ctx,
+		"INSERT INTO tweets (user_id, content, reply_to_tweet_id, retweet_of_id) VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0)) RETURNING "+tweetColumns,
+		tweet.UserID, tweet.Content, tweet.ReplyToTweetID, tweet.RetweetOfID)
+
+	newTweet, err := scanTweet(row)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if err = replaceMentions(ctx, tx, newTweet); err != nil {
+		return domain.Tweet{}, err
+	}
 
 	return newTweet, nil
 }
 
-func (t Tweet) UpdateByID(ctx context.Context, id int64, tweet domain.Tweet) (domain.Tweet, error) {
+// UpdateByID replaces the content of a tweet and stores the previous version as a
+// revision. Both writes happen in one transaction so history is never lost. The
+// mentions are replaced by the ones of the new content.
+// The tweet is only updated if it was edited fewer than maxEdits times and is younger
+// than window, checked by the UPDATE itself so concurrent edits can't go past the limit.
+// Otherwise an empty tweet (ID will be 0) is returned without error.
+func (t Tweet) UpdateByID(ctx context.Context, id int64, tweet domain.Tweet, maxEdits int, window time.Duration) (domain.Tweet, error) {
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+	defer tx.Rollback()
+
+	// Lock the tweet so the revision stored is the version this edit replaces
+	var revision domain.TweetRevision
+	row := tx.QueryRowContext(ctx, "SELECT content, updated_at FROM tweets WHERE id = $1 FOR UPDATE", id)
+	if err = row.Scan(&revision.Content, &revision.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Tweet{}, nil
+		}
+		return domain.Tweet{}, err
+	}
 
-	var updatedTweet domain.Tweet
+	row = tx.QueryRowContext(ctx,
+		"UPDATE tweets SET content = $1, edit_count = edit_count + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND edit_count < $3 AND created_at > CURRENT_TIMESTAMP - $4 * INTERVAL '1 second' RETURNING "+tweetColumns,
+		tweet.Content, id, maxEdits, window.Seconds())
 
-	row := t.db.QueryRowContext(ctx, "UPDATE tweets SET content = $1 WHERE id = $2 RETURNING id, user_id, content, created_at, updated_at", tweet.Content, id)
+	updatedTweet, err := scanTweet(row)
+	if err != nil {
+		// The edit window expired or the edits were used up
+		if err == sql.ErrNoRows {
+			return domain.Tweet{}, nil
+		}
+		return domain.Tweet{}, err
+	}
 
-	err := row.Scan(&updatedTweet.ID, &updatedTweet.UserID, &updatedTweet.Content, &updatedTweet.CreatedAt, &updatedTweet.UpdatedAt)
+	_, err = tx.ExecContext(ctx, "INSERT INTO tweet_revisions (tweet_id, content, created_at) VALUES ($1, $2, $3)", id, revision.Content, revision.CreatedAt)
 	if err != nil {
 		return domain.Tweet{}, err
 	}
 
+	if err = replaceMentions(ctx, tx, updatedTweet); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Tweet{}, err
+	}
+
 	return updatedTweet, nil
 }
 
-func (t Tweet) SelectTimelineTweets(ctx context.Context, userID int64, limit, offset int) ([]domain.Tweet, error) {
+func (t Tweet) SelectRevisionsByTweetID(ctx context.Context, tweetID int64) ([]domain.TweetRevision, error) {
+
+	rows, err := t.db.QueryContext(ctx, "SELECT id, tweet_id, content, created_at FROM tweet_revisions WHERE tweet_id = $1 ORDER BY id ASC", tweetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []domain.TweetRevision
+	for rows.Next() {
+		var revision domain.TweetRevision
+		err := rows.Scan(&revision.ID, &revision.TweetID, &revision.Content, &revision.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// SelectTimelineTweets returns the tweets of userID and of the users they follow, newest first.
+// Tweets written by excludeUserIDs (e.g. muted or blocked users, or userID) are left out.
+func (t Tweet) SelectTimelineTweets(ctx context.Context, userID int64, limit, offset int, excludeUserIDs []int64) ([]domain.Tweet, error) {
 
 	query := `
-		SELECT t.id, t.user_id, t.content, t.created_at, t.updated_at
+		SELECT t.id, t.user_id, t.content, COALESCE(t.reply_to_tweet_id, 0), COALESCE(t.retweet_of_id, 0), t.edit_count, t.created_at, t.updated_at
 		FROM tweets t
-		INNER JOIN followers f ON t.user_id = f.followed_id
-		WHERE f.follower_id = $1
+		WHERE (t.user_id = $1 OR t.user_id IN (SELECT followed_id FROM followers WHERE follower_id = $1))
+		  AND t.user_id <> ALL($4)
 		ORDER BY t.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := t.db.QueryContext(ctx, query, userID, limit, offset)
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	rows, err := t.db.QueryContext(ctx, query, userID, limit, offset, pq.Array(excludeUserIDs))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tweets []domain.Tweet
-	for rows.Next() {
-		var tweet domain.Tweet
-		err := rows.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.CreatedAt, &tweet.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		tweets = append(tweets, tweet)
+	return scanTweets(rows)
+}
+
+// SelectListTimelineTweets returns the tweets of the members of a list, newest first.
+// Tweets written by excludeUserIDs are left out.
+func (t Tweet) SelectListTimelineTweets(ctx context.Context, listID int64, limit, offset int, excludeUserIDs []int64) ([]domain.Tweet, error) {
+
+	query := `
+		SELECT t.id, t.user_id, t.content, COALESCE(t.reply_to_tweet_id, 0), COALESCE(t.retweet_of_id, 0), t.edit_count, t.created_at, t.updated_at
+		FROM tweets t
+		INNER JOIN list_members m ON t.user_id = m.user_id
+		WHERE m.list_id = $1
+		  AND t.user_id <> ALL($4)
+		ORDER BY t.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
 	}
 
-	if err = rows.Err(); err != nil {
+	rows, err := t.db.QueryContext(ctx, query, listID, limit, offset, pq.Array(excludeUserIDs))
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return tweets, nil
+	return scanTweets(rows)
+}
+
+// SelectTimelineTweetIDs returns the IDs of the newest tweets of userID and of the users they
+// follow, newest first. It is used to cache a whole timeline without loading the tweets.
+func (t Tweet) SelectTimelineTweetIDs(ctx context.Context, userID int64, limit int, excludeUserIDs []int64) ([]int64, error) {
+
+	query := `
+		SELECT t.id
+		FROM tweets t
+		WHERE (t.user_id = $1 OR t.user_id IN (SELECT followed_id FROM followers WHERE follower_id = $1))
+		  AND t.user_id <> ALL($3)
+		ORDER BY t.id DESC
+		LIMIT $2
+	`
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	return selectIDs(ctx, t.db, query, userID, limit, pq.Array(excludeUserIDs))
+}
+
+// SelectListTimelineTweetIDs returns the IDs of the newest tweets of the members of a list,
+// newest first.
+func (t Tweet) SelectListTimelineTweetIDs(ctx context.Context, listID int64, limit int, excludeUserIDs []int64) ([]int64, error) {
+
+	query := `
+		SELECT t.id
+		FROM tweets t
+		INNER JOIN list_members m ON t.user_id = m.user_id
+		WHERE m.list_id = $1
+		  AND t.user_id <> ALL($3)
+		ORDER BY t.id DESC
+		LIMIT $2
+	`
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []int64{}
+	}
+
+	return selectIDs(ctx, t.db, query, listID, limit, pq.Array(excludeUserIDs))
 }
 
 func (t Tweet) SelectTweetsByIDs(ctx context.Context, ids []int64) ([]domain.Tweet, error) {
@@ -113,29 +325,130 @@ func (t Tweet) SelectTweetsByIDs(ctx context.Context, ids []int64) ([]domain.Twe
 
 	// Build the query with ORDER BY to match cache order (newest first)
 	query := `
-		SELECT id, user_id, content, created_at, updated_at
+		SELECT ` + tweetColumns + `
 		FROM tweets
 		WHERE id = ANY($1)
 		ORDER BY id DESC
 	`
 
-	rows, err := t.db.QueryContext(ctx, query, ids)
+	rows, err := t.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanTweets(rows)
+}
+
+// SelectUserTweets returns the tweets written by a user, newest first, using keyset
+// pagination: only tweets older than maxID are returned (0 starts from the newest).
+// excludeID leaves out one tweet, e.g. the pinned one that is listed separately.
+func (t Tweet) SelectUserTweets(ctx context.Context, userID, maxID int64, limit int, filter domain.TweetFilter, excludeID int64) ([]domain.Tweet, error) {
+
+	query := `
+		SELECT ` + tweetColumns + `
+		FROM tweets
+		WHERE user_id = $1
+		  AND ($2 = 0 OR id < $2)
+		  AND ($3 OR reply_to_tweet_id IS NULL)
+		  AND ($4 OR retweet_of_id IS NULL)
+		  AND id <> $5
+		ORDER BY id DESC
+		LIMIT $6
+	`
+
+	rows, err := t.db.QueryContext(ctx, query, userID, maxID, filter.IncludeReplies, filter.IncludeRetweets, excludeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTweets(rows)
+}
+
+// SelectTweetIDsByAuthors returns the IDs of the newest tweets written by any of the given
+// users, newest first. Only tweets newer than minID are returned.
+func (t Tweet) SelectTweetIDsByAuthors(ctx context.Context, authorIDs []int64, minID int64, limit int) ([]int64, error) {
+
+	if len(authorIDs) == 0 {
+		return []int64{}, nil
+	}
+
+	return selectIDs(ctx, t.db, "SELECT id FROM tweets WHERE user_id = ANY($1) AND id > $2 ORDER BY id DESC LIMIT $3", pq.Array(authorIDs), minID, limit)
+}
+
+// SelectTweetsAfter returns the tweets after afterID, in ID order, to walk through every
+// tweet in batches.
+func (t Tweet) SelectTweetsAfter(ctx context.Context, afterID int64, limit int) ([]domain.Tweet, error) {
+
+	rows, err := t.db.QueryContext(ctx, "SELECT "+tweetColumns+" FROM tweets WHERE id > $1 ORDER BY id ASC LIMIT $2", afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTweets(rows)
+}
+
+// SelectPinnedTweetID returns the tweet pinned by a user, or 0 when there is none.
+func (t Tweet) SelectPinnedTweetID(ctx context.Context, userID int64) (int64, error) {
+
+	var tweetID int64
+
+	row := t.db.QueryRowContext(ctx, "SELECT tweet_id FROM pinned_tweets WHERE user_id = $1", userID)
+
+	err := row.Scan(&tweetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return tweetID, nil
+}
+
+func (t Tweet) UpsertPinnedTweet(ctx context.Context, userID, tweetID int64) error {
+
+	_, err := t.db.ExecContext(ctx,
+		"INSERT INTO pinned_tweets (user_id, tweet_id) VALUES ($1, $2) ON CONFLICT (user_id) DO UPDATE SET tweet_id = EXCLUDED.tweet_id, created_at = CURRENT_TIMESTAMP",
+		userID, tweetID)
+
+	return err
+}
+
+func (t Tweet) DeletePinnedTweet(ctx context.Context, userID int64) error {
+
+	_, err := t.db.ExecContext(ctx, "DELETE FROM pinned_tweets WHERE user_id = $1", userID)
+
+	return err
+}
+
+// scanTweet scans a single row selected with tweetColumns.
+func scanTweet(row interface{ Scan(dest ...any) error }) (domain.Tweet, error) {
+
+	var tweet domain.Tweet
+
+	err := row.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ReplyToTweetID, &tweet.RetweetOfID, &tweet.EditCount, &tweet.CreatedAt, &tweet.UpdatedAt)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	return tweet, nil
+}
+
+func scanTweets(rows *sql.Rows) ([]domain.Tweet, error) {
+
 	var tweets []domain.Tweet
 	for rows.Next() {
-		var tweet domain.Tweet
-		err := rows.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.CreatedAt, &tweet.UpdatedAt)
+		tweet, err := scanTweet(rows)
 		if err != nil {
 			return nil, err
 		}
 		tweets = append(tweets, tweet)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
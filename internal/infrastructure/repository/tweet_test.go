@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var tweetColumnNames = []string{"id", "user_id", "content", "reply_to_tweet_id", "retweet_of_id", "edit_count", "created_at", "updated_at"}
+
+func TestTweet_UpdateByID_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTweet(&pkg.Postgres{DB: db})
+	createdAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT content, updated_at FROM tweets WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"content", "updated_at"}).AddRow("hello wrold", createdAt))
+	mock.ExpectQuery("UPDATE tweets SET content = \\$1, edit_count = edit_count \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND edit_count < \\$3 AND created_at > CURRENT_TIMESTAMP - \\$4 \\* INTERVAL '1 second'").
+		WithArgs("hello world", int64(1), 5, float64(1800)).
+		WillReturnRows(sqlmock.NewRows(tweetColumnNames).AddRow(1, 2, "hello world", 0, 0, 1, createdAt, time.Now()))
+	mock.ExpectExec("INSERT INTO tweet_revisions \\(tweet_id, content, created_at\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs(int64(1), "hello wrold", createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM tweet_mentions WHERE tweet_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// Act
+	tweet, err := repo.UpdateByID(context.Background(), 1, domain.Tweet{Content: "hello world"}, 5, 30*time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tweet.ID)
+	assert.Equal(t, "hello world", tweet.Content)
+	assert.Equal(t, 1, tweet.EditCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTweet_UpdateByID_EditLimitReached(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTweet(&pkg.Postgres{DB: db})
+
+	// The UPDATE matches no row once the window expired or the edits are used up,
+	// and no revision is stored
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT content, updated_at FROM tweets WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"content", "updated_at"}).AddRow("hello", time.Now()))
+	mock.ExpectQuery("UPDATE tweets SET content").
+		WithArgs("hello again", int64(1), 5, float64(1800)).
+		WillReturnRows(sqlmock.NewRows(tweetColumnNames))
+	mock.ExpectRollback()
+
+	// Act
+	tweet, err := repo.UpdateByID(context.Background(), 1, domain.Tweet{Content: "hello again"}, 5, 30*time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), tweet.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTweet_UpdateByID_NotFound(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTweet(&pkg.Postgres{DB: db})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT content, updated_at FROM tweets WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"content", "updated_at"}))
+	mock.ExpectRollback()
+
+	// Act
+	tweet, err := repo.UpdateByID(context.Background(), 1, domain.Tweet{Content: "hello"}, 5, 30*time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), tweet.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTweet_InsertScheduled_Success(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTweet(&pkg.Postgres{DB: db})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM scheduled_tweets WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("publishing"))
+	mock.ExpectQuery("INSERT INTO tweets").
+		WithArgs(int64(2), "good morning", int64(0), int64(0)).
+		WillReturnRows(sqlmock.NewRows(tweetColumnNames).AddRow(1, 2, "good morning", 0, 0, 0, time.Now(), time.Now()))
+	mock.ExpectExec("DELETE FROM tweet_mentions WHERE tweet_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE scheduled_tweets SET status = 'published', tweet_id = \\$1").
+		WithArgs(int64(1), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	tweet, err := repo.InsertScheduled(context.Background(), 7, domain.Tweet{UserID: 2, Content: "good morning"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tweet.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTweet_InsertScheduled_AlreadyPublished(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTweet(&pkg.Postgres{DB: db})
+
+	// A worker that reclaimed the expired lease published it first, no tweet is inserted
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM scheduled_tweets WHERE id = \\$1 FOR UPDATE").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("published"))
+	mock.ExpectRollback()
+
+	// Act
+	tweet, err := repo.InsertScheduled(context.Background(), 7, domain.Tweet{UserID: 2, Content: "good morning"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), tweet.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
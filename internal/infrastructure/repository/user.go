@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"twitter-demo/internal/domain"
 	"twitter-demo/pkg"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
@@ -14,6 +16,8 @@ type UserRepository interface {
 	SelectByUsername(ctx context.Context, username string) (domain.User, error)
 	Insert(ctx context.Context, user domain.User) (domain.User, error)
 	UpdateByID(ctx context.Context, id int64, user domain.User) (domain.User, error)
+	SelectByIDs(ctx context.Context, ids []int64) ([]domain.User, error)
+	SelectIDsAfter(ctx context.Context, afterID int64, limit int) ([]int64, error)
 }
 
 type User struct {
@@ -28,7 +32,7 @@ func NewUser(db *pkg.Postgres) User {
 
 func (u User) SelectAll(ctx context.Context) ([]domain.User, error) {
 
-	rows, err := u.db.QueryContext(ctx, "SELECT id, username, email, password, created_at, updated_at FROM users")
+	rows, err := u.db.QueryContext(ctx, "SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users")
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +41,7 @@ func (u User) SelectAll(ctx context.Context) ([]domain.User, error) {
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FollowersCount, &user.FollowingCount, &user.Protected, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -51,9 +55,9 @@ func (u User) SelectByID(ctx context.Context, id int64) (domain.User, error) {
 
 	var user domain.User
 
-	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, created_at, updated_at FROM users WHERE id = $1", id)
+	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE id = $1", id)
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FollowersCount, &user.FollowingCount, &user.Protected, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		// If no rows found, return empty user (ID will be 0) without error
 		if err == sql.ErrNoRows {
@@ -69,9 +73,9 @@ func (u User) SelectByEmail(ctx context.Context, email string) (domain.User, err
 
 	var user domain.User
 
-	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, created_at, updated_at FROM users WHERE email = $1", email)
+	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE email = $1", email)
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FollowersCount, &user.FollowingCount, &user.Protected, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		// If no rows found, return empty user (ID will be 0) without error
 		if err == sql.ErrNoRows {
@@ -87,9 +91,9 @@ func (u User) SelectByUsername(ctx context.Context, username string) (domain.Use
 
 	var user domain.User
 
-	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, created_at, updated_at FROM users WHERE username = $1", username)
+	row := u.db.QueryRowContext(ctx, "SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE username = $1", username)
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FollowersCount, &user.FollowingCount, &user.Protected, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		// If no rows found, return empty user (ID will be 0) without error
 		if err == sql.ErrNoRows {
@@ -105,9 +109,9 @@ func (u User) Insert(ctx context.Context, user domain.User) (domain.User, error)
 
 	var newUser domain.User
 
-	row := u.db.QueryRowContext(ctx, "INSERT INTO users (username, email, password) VALUES ($1, $2, $3) RETURNING id, username, email, password, created_at, updated_at", user.Username, user.Email, user.Password)
+	row := u.db.QueryRowContext(ctx, "INSERT INTO users (username, email, password, protected) VALUES ($1, $2, $3, $4) RETURNING id, username, email, password, protected, created_at, updated_at", user.Username, user.Email, user.Password, user.Protected)
 
-	err := row.Scan(&newUser.ID, &newUser.Username, &newUser.Email, &newUser.Password, &newUser.CreatedAt, &newUser.UpdatedAt)
+	err := row.Scan(&newUser.ID, &newUser.Username, &newUser.Email, &newUser.Password, &newUser.Protected, &newUser.CreatedAt, &newUser.UpdatedAt)
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -119,12 +123,47 @@ func (u User) UpdateByID(ctx context.Context, id int64, user domain.User) (domai
 
 	var updatedUser domain.User
 
-	row := u.db.QueryRowContext(ctx, "UPDATE users SET username = $1, email = $2, password = $3 WHERE id = $4 RETURNING id, username, email, password, created_at, updated_at", user.Username, user.Email, user.Password, id)
+	row := u.db.QueryRowContext(ctx, "UPDATE users SET username = $1, email = $2, password = $3, protected = $4 WHERE id = $5 RETURNING id, username, email, password, followers_count, following_count, protected, created_at, updated_at", user.Username, user.Email, user.Password, user.Protected, id)
 
-	err := row.Scan(&updatedUser.ID, &updatedUser.Username, &updatedUser.Email, &updatedUser.Password, &updatedUser.CreatedAt, &updatedUser.UpdatedAt)
+	err := row.Scan(&updatedUser.ID, &updatedUser.Username, &updatedUser.Email, &updatedUser.Password, &updatedUser.FollowersCount, &updatedUser.FollowingCount, &updatedUser.Protected, &updatedUser.CreatedAt, &updatedUser.UpdatedAt)
 	if err != nil {
 		return domain.User{}, err
 	}
 
 	return updatedUser, nil
 }
+
+func (u User) SelectByIDs(ctx context.Context, ids []int64) ([]domain.User, error) {
+
+	if len(ids) == 0 {
+		return []domain.User{}, nil
+	}
+
+	rows, err := u.db.QueryContext(ctx, "SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FollowersCount, &user.FollowingCount, &user.Protected, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// SelectIDsAfter returns the IDs of the users after afterID, in ID order, to walk
+// through every user in batches.
+func (u User) SelectIDsAfter(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	return selectIDs(ctx, u.db, "SELECT id FROM users WHERE id > $1 ORDER BY id ASC LIMIT $2", afterID, limit)
+}
@@ -22,18 +22,20 @@ func TestUser_SelectByID_Success(t *testing.T) {
 	repo := NewUser(postgres)
 
 	expectedUser := domain.User{
-		ID:        1,
-		Username:  "testuser",
-		Email:     "test@example.com",
-		Password:  "hashedpassword",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             1,
+		Username:       "testuser",
+		Email:          "test@example.com",
+		Password:       "hashedpassword",
+		FollowersCount: 10,
+		FollowingCount: 3,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.Password, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "followers_count", "following_count", "protected", "created_at", "updated_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.Password, expectedUser.FollowersCount, expectedUser.FollowingCount, expectedUser.Protected, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 
-	mock.ExpectQuery("SELECT id, username, email, password, created_at, updated_at FROM users WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE id = \\$1").
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -45,6 +47,8 @@ func TestUser_SelectByID_Success(t *testing.T) {
 	assert.Equal(t, expectedUser.ID, user.ID)
 	assert.Equal(t, expectedUser.Username, user.Username)
 	assert.Equal(t, expectedUser.Email, user.Email)
+	assert.Equal(t, expectedUser.FollowersCount, user.FollowersCount)
+	assert.Equal(t, expectedUser.FollowingCount, user.FollowingCount)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -57,7 +61,7 @@ func TestUser_SelectByID_NotFound(t *testing.T) {
 	postgres := &pkg.Postgres{DB: db}
 	repo := NewUser(postgres)
 
-	mock.ExpectQuery("SELECT id, username, email, password, created_at, updated_at FROM users WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE id = \\$1").
 		WithArgs(int64(999)).
 		WillReturnError(sql.ErrNoRows)
 
@@ -88,10 +92,10 @@ func TestUser_SelectByEmail_Success(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.Password, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "followers_count", "following_count", "protected", "created_at", "updated_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.Password, expectedUser.FollowersCount, expectedUser.FollowingCount, expectedUser.Protected, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 
-	mock.ExpectQuery("SELECT id, username, email, password, created_at, updated_at FROM users WHERE email = \\$1").
+	mock.ExpectQuery("SELECT id, username, email, password, followers_count, following_count, protected, created_at, updated_at FROM users WHERE email = \\$1").
 		WithArgs("test@example.com").
 		WillReturnRows(rows)
 
@@ -120,11 +124,11 @@ func TestUser_Insert_Success(t *testing.T) {
 	}
 
 	expectedTime := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "created_at", "updated_at"}).
-		AddRow(int64(1), newUser.Username, newUser.Email, newUser.Password, expectedTime, expectedTime)
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "password", "protected", "created_at", "updated_at"}).
+		AddRow(int64(1), newUser.Username, newUser.Email, newUser.Password, newUser.Protected, expectedTime, expectedTime)
 
-	mock.ExpectQuery("INSERT INTO users \\(username, email, password\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, username, email, password, created_at, updated_at").
-		WithArgs(newUser.Username, newUser.Email, newUser.Password).
+	mock.ExpectQuery("INSERT INTO users \\(username, email, password, protected\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id, username, email, password, protected, created_at, updated_at").
+		WithArgs(newUser.Username, newUser.Email, newUser.Password, newUser.Protected).
 		WillReturnRows(rows)
 
 	// Act
@@ -153,8 +157,8 @@ func TestUser_Insert_Error(t *testing.T) {
 		Password: "hashedpassword",
 	}
 
-	mock.ExpectQuery("INSERT INTO users \\(username, email, password\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, username, email, password, created_at, updated_at").
-		WithArgs(newUser.Username, newUser.Email, newUser.Password).
+	mock.ExpectQuery("INSERT INTO users \\(username, email, password, protected\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id, username, email, password, protected, created_at, updated_at").
+		WithArgs(newUser.Username, newUser.Email, newUser.Password, newUser.Protected).
 		WillReturnError(sql.ErrConnDone)
 
 	// Act
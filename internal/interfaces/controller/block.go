@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BlockController interface {
+	BlockUser(ctx *gin.Context)
+	UnblockUser(ctx *gin.Context)
+	GetBlocks(ctx *gin.Context)
+}
+
+type Block struct {
+	blockUsecase usecase.BlockUsecase
+}
+
+func NewBlock(blockUsecase usecase.BlockUsecase) Block {
+	return Block{
+		blockUsecase: blockUsecase,
+	}
+}
+
+func (b Block) BlockUser(ctx *gin.Context) {
+
+	blockRequest := dto.BlockRequest{}
+	if err := ctx.ShouldBindJSON(&blockRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	block, err := b.blockUsecase.BlockUser(ctx, blockRequest.BlockerID, blockRequest.BlockedID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToBlockResponse(block))
+}
+
+func (b Block) UnblockUser(ctx *gin.Context) {
+
+	blockRequest := dto.BlockRequest{}
+	if err := ctx.ShouldBindJSON(&blockRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := b.blockUsecase.UnblockUser(ctx, blockRequest.BlockerID, blockRequest.BlockedID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully unblocked user"})
+}
+
+func (b Block) GetBlocks(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ConnectionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	blocks, nextCursor, err := b.blockUsecase.GetBlocks(ctx, userID, request.Limit, request.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToBlocksResponse(blocks, request.Limit, nextCursor))
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DraftController interface {
+	GetDrafts(ctx *gin.Context)
+	CreateDraft(ctx *gin.Context)
+	UpdateDraft(ctx *gin.Context)
+	DeleteDraft(ctx *gin.Context)
+	PublishDraft(ctx *gin.Context)
+}
+
+type Draft struct {
+	draftUsecase usecase.DraftUsecase
+}
+
+func NewDraft(draftUsecase usecase.DraftUsecase) Draft {
+	return Draft{
+		draftUsecase: draftUsecase,
+	}
+}
+
+func (d Draft) GetDrafts(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	drafts, err := d.draftUsecase.GetDrafts(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	draftResponses := make([]dto.DraftResponse, len(drafts))
+	for i, draft := range drafts {
+		draftResponses[i] = dto.ToDraftResponse(draft)
+	}
+
+	ctx.JSON(http.StatusOK, draftResponses)
+}
+
+func (d Draft) CreateDraft(ctx *gin.Context) {
+
+	createDraftRequest := dto.CreateDraftRequest{}
+	if err := ctx.ShouldBindJSON(&createDraftRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft := dto.ToDraftDomain(createDraftRequest)
+	newDraft, err := d.draftUsecase.CreateDraft(ctx, draft)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToDraftResponse(newDraft))
+}
+
+func (d Draft) UpdateDraft(ctx *gin.Context) {
+
+	updateDraftRequest := dto.UpdateDraftRequest{}
+	if err := ctx.ShouldBindJSON(&updateDraftRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	draft := dto.ToUpdateDraftDomain(updateDraftRequest)
+	updatedDraft, err := d.draftUsecase.UpdateDraft(ctx, id, draft)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToDraftResponse(updatedDraft))
+}
+
+func (d Draft) DeleteDraft(ctx *gin.Context) {
+
+	draftOwnerRequest := dto.DraftOwnerRequest{}
+	if err := ctx.ShouldBindJSON(&draftOwnerRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err = d.draftUsecase.DeleteDraft(ctx, id, draftOwnerRequest.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully deleted draft"})
+}
+
+func (d Draft) PublishDraft(ctx *gin.Context) {
+
+	draftOwnerRequest := dto.DraftOwnerRequest{}
+	if err := ctx.ShouldBindJSON(&draftOwnerRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	tweet, err := d.draftUsecase.PublishDraft(ctx, id, draftOwnerRequest.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToTweetResponse(tweet))
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowImportController interface {
+	CreateImport(ctx *gin.Context)
+	GetImport(ctx *gin.Context)
+	ProcessPendingImports(ctx context.Context) error
+}
+
+type FollowImport struct {
+	followImportUsecase usecase.FollowImportUsecase
+}
+
+func NewFollowImport(followImportUsecase usecase.FollowImportUsecase) FollowImport {
+	return FollowImport{
+		followImportUsecase: followImportUsecase,
+	}
+}
+
+// CreateImport accepts a JSON body with usernames and user IDs, or a multipart form
+// with user_id and a CSV file. The import is processed by the worker: 202 is returned
+// with the import, whose progress is read with GetImport.
+func (f FollowImport) CreateImport(ctx *gin.Context) {
+
+	var userID int64
+	var targets []string
+
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		var err error
+		userID, err = strconv.ParseInt(ctx.PostForm("user_id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		targets, err = dto.ParseFollowTargetsCSV(file)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid csv file: " + err.Error()})
+			return
+		}
+	} else {
+		createFollowImportRequest := dto.CreateFollowImportRequest{}
+		if err := ctx.ShouldBindJSON(&createFollowImportRequest); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID = createFollowImportRequest.UserID
+		targets = createFollowImportRequest.Targets()
+	}
+
+	followImport, err := f.followImportUsecase.CreateImport(ctx, userID, targets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, dto.ToFollowImportResponse(followImport))
+}
+
+func (f FollowImport) GetImport(ctx *gin.Context) {
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	followImport, err := f.followImportUsecase.GetImport(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToFollowImportResponse(followImport))
+}
+
+// ProcessPendingImports is the follow import tick handler run periodically by the worker.
+func (f FollowImport) ProcessPendingImports(ctx context.Context) error {
+
+	completed, err := f.followImportUsecase.ProcessPendingImports(ctx)
+	if err != nil {
+		log.Printf("Follow imports failed: %v", err)
+		return err
+	}
+
+	if completed > 0 {
+		log.Printf("Completed %d follow imports", completed)
+	}
+
+	return nil
+}
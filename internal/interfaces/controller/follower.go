@@ -1,7 +1,14 @@
 package controller
 
 import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
 	"twitter-demo/internal/interfaces/dto"
 	"twitter-demo/internal/usecase"
 
@@ -11,6 +18,12 @@ import (
 type FollowerController interface {
 	FollowUser(ctx *gin.Context)
 	UnfollowUser(ctx *gin.Context)
+	GetFollowers(ctx *gin.Context)
+	GetFollowing(ctx *gin.Context)
+	ExportFollowing(ctx *gin.Context)
+	GetFollowRequests(ctx *gin.Context)
+	ApproveFollowRequest(ctx *gin.Context)
+	RejectFollowRequest(ctx *gin.Context)
 }
 
 type Follower struct {
@@ -31,12 +44,18 @@ func (f Follower) FollowUser(ctx *gin.Context) {
 		return
 	}
 
-	follower, err := f.followerUsecase.FollowUser(ctx, followRequest.FollowerID, followRequest.FollowedID)
+	follower, pendingRequest, err := f.followerUsecase.FollowUser(ctx, followRequest.FollowerID, followRequest.FollowedID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Protected accounts have to approve the request first
+	if pendingRequest.ID != 0 {
+		ctx.JSON(http.StatusAccepted, dto.ToFollowRequestResponse(pendingRequest))
+		return
+	}
+
 	ctx.JSON(http.StatusCreated, dto.ToFollowerResponse(follower))
 }
 
@@ -56,3 +75,177 @@ func (f Follower) UnfollowUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "successfully unfollowed user"})
 }
+
+func (f Follower) GetFollowers(ctx *gin.Context) {
+	f.getConnections(ctx, f.followerUsecase.GetFollowers)
+}
+
+func (f Follower) GetFollowing(ctx *gin.Context) {
+	f.getConnections(ctx, f.followerUsecase.GetFollowing)
+}
+
+// ExportFollowing writes every account a user follows as a CSV file, most recent first.
+// Pages are read and written one at a time so the whole list is never held in memory.
+func (f Follower) ExportFollowing(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	// Read the first page before writing anything so errors still get a proper status
+	connections, nextCursor, err := f.followerUsecase.GetFollowing(ctx, userID, config.MaxLimit, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"following-%d.csv\"", userID))
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	_ = writer.Write(dto.ConnectionCSVHeader)
+
+	for {
+		for _, connection := range connections {
+			_ = writer.Write(dto.ToConnectionCSVRecord(connection))
+		}
+		writer.Flush()
+
+		if nextCursor == 0 {
+			break
+		}
+
+		connections, nextCursor, err = f.followerUsecase.GetFollowing(ctx, userID, config.MaxLimit, nextCursor)
+		if err != nil {
+			// Headers are already sent, the truncated file is all that can be returned
+			log.Printf("Failed to export following of user %d: %v", userID, err)
+			return
+		}
+	}
+
+	if err := writer.Error(); err != nil {
+		log.Printf("Failed to export following of user %d: %v", userID, err)
+	}
+}
+
+func (f Follower) GetFollowRequests(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ConnectionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	followRequests, nextCursor, err := f.followerUsecase.GetFollowRequests(ctx, userID, request.Limit, request.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToFollowRequestsResponse(followRequests, request.Limit, nextCursor))
+}
+
+func (f Follower) ApproveFollowRequest(ctx *gin.Context) {
+
+	targetID, requesterID, ok := parseFollowRequestParams(ctx)
+	if !ok {
+		return
+	}
+
+	follower, err := f.followerUsecase.ApproveFollowRequest(ctx, targetID, requesterID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToFollowerResponse(follower))
+}
+
+func (f Follower) RejectFollowRequest(ctx *gin.Context) {
+
+	targetID, requesterID, ok := parseFollowRequestParams(ctx)
+	if !ok {
+		return
+	}
+
+	err := f.followerUsecase.RejectFollowRequest(ctx, targetID, requesterID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "follow request rejected"})
+}
+
+// parseFollowRequestParams reads the owner and requester IDs from the URL,
+// writing a bad request response when they are invalid.
+func parseFollowRequestParams(ctx *gin.Context) (int64, int64, bool) {
+
+	targetID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, 0, false
+	}
+
+	requesterID, err := strconv.ParseInt(ctx.Param("requester_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid requester id"})
+		return 0, 0, false
+	}
+
+	return targetID, requesterID, true
+}
+
+// getConnections handles the followers and following lists, which only differ in the usecase method.
+func (f Follower) getConnections(ctx *gin.Context, list func(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Connection, int64, error)) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ConnectionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	connections, nextCursor, err := list(ctx, userID, request.Limit, request.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToConnectionsResponse(connections, request.Limit, nextCursor))
+}
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListController interface {
+	GetList(ctx *gin.Context)
+	GetUserLists(ctx *gin.Context)
+	GetSubscribedLists(ctx *gin.Context)
+	GetListMembers(ctx *gin.Context)
+	CreateList(ctx *gin.Context)
+	UpdateList(ctx *gin.Context)
+	DeleteList(ctx *gin.Context)
+	AddMember(ctx *gin.Context)
+	RemoveMember(ctx *gin.Context)
+	Subscribe(ctx *gin.Context)
+	Unsubscribe(ctx *gin.Context)
+}
+
+type List struct {
+	listUsecase usecase.ListUsecase
+}
+
+func NewList(listUsecase usecase.ListUsecase) List {
+	return List{
+		listUsecase: listUsecase,
+	}
+}
+
+func (l List) GetList(ctx *gin.Context) {
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.ViewerRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := l.listUsecase.GetList(ctx, id, request.ViewerID)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToListResponse(list))
+}
+
+func (l List) GetUserLists(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ViewerRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lists, err := l.listUsecase.GetUserLists(ctx, userID, request.ViewerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToListResponses(lists))
+}
+
+func (l List) GetSubscribedLists(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	lists, err := l.listUsecase.GetSubscribedLists(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToListResponses(lists))
+}
+
+func (l List) GetListMembers(ctx *gin.Context) {
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.ViewerRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := l.listUsecase.GetListMembers(ctx, id, request.ViewerID)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	memberResponses := make([]dto.UserSummaryResponse, len(members))
+	for i, member := range members {
+		memberResponses[i] = dto.ToUserSummaryResponse(member)
+	}
+
+	ctx.JSON(http.StatusOK, memberResponses)
+}
+
+func (l List) CreateList(ctx *gin.Context) {
+
+	createListRequest := dto.CreateListRequest{}
+	if err := ctx.ShouldBindJSON(&createListRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	newList, err := l.listUsecase.CreateList(ctx, dto.ToListDomain(createListRequest))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToListResponse(newList))
+}
+
+func (l List) UpdateList(ctx *gin.Context) {
+
+	updateListRequest := dto.UpdateListRequest{}
+	if err := ctx.ShouldBindJSON(&updateListRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	updatedList, err := l.listUsecase.UpdateList(ctx, id, dto.ToUpdateListDomain(updateListRequest))
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToListResponse(updatedList))
+}
+
+func (l List) DeleteList(ctx *gin.Context) {
+
+	listOwnerRequest := dto.ListOwnerRequest{}
+	if err := ctx.ShouldBindJSON(&listOwnerRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.listUsecase.DeleteList(ctx, id, listOwnerRequest.OwnerID); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully deleted list"})
+}
+
+func (l List) AddMember(ctx *gin.Context) {
+
+	listMemberRequest := dto.ListMemberRequest{}
+	if err := ctx.ShouldBindJSON(&listMemberRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.listUsecase.AddMember(ctx, id, listMemberRequest.OwnerID, listMemberRequest.UserID); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "successfully added member"})
+}
+
+func (l List) RemoveMember(ctx *gin.Context) {
+
+	listMemberRequest := dto.ListMemberRequest{}
+	if err := ctx.ShouldBindJSON(&listMemberRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.listUsecase.RemoveMember(ctx, id, listMemberRequest.OwnerID, listMemberRequest.UserID); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully removed member"})
+}
+
+func (l List) Subscribe(ctx *gin.Context) {
+
+	listSubscriberRequest := dto.ListSubscriberRequest{}
+	if err := ctx.ShouldBindJSON(&listSubscriberRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.listUsecase.Subscribe(ctx, id, listSubscriberRequest.UserID); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "successfully subscribed to list"})
+}
+
+func (l List) Unsubscribe(ctx *gin.Context) {
+
+	listSubscriberRequest := dto.ListSubscriberRequest{}
+	if err := ctx.ShouldBindJSON(&listSubscriberRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := l.listUsecase.Unsubscribe(ctx, id, listSubscriberRequest.UserID); err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully unsubscribed from list"})
+}
+
+// parseListID reads the list ID from the URL, writing a bad request response when it is invalid.
+func parseListID(ctx *gin.Context) (int64, bool) {
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// writeListError answers 404 for lists that don't exist or the caller can't see.
+func writeListError(ctx *gin.Context, err error) {
+
+	if errors.Is(err, usecase.ErrListNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MuteController interface {
+	MuteUser(ctx *gin.Context)
+	UnmuteUser(ctx *gin.Context)
+	GetMutes(ctx *gin.Context)
+}
+
+type Mute struct {
+	muteUsecase usecase.MuteUsecase
+}
+
+func NewMute(muteUsecase usecase.MuteUsecase) Mute {
+	return Mute{
+		muteUsecase: muteUsecase,
+	}
+}
+
+func (m Mute) MuteUser(ctx *gin.Context) {
+
+	muteRequest := dto.MuteRequest{}
+	if err := ctx.ShouldBindJSON(&muteRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mute, err := m.muteUsecase.MuteUser(ctx, muteRequest.MuterID, muteRequest.MutedID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToMuteResponse(mute))
+}
+
+func (m Mute) UnmuteUser(ctx *gin.Context) {
+
+	muteRequest := dto.MuteRequest{}
+	if err := ctx.ShouldBindJSON(&muteRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := m.muteUsecase.UnmuteUser(ctx, muteRequest.MuterID, muteRequest.MutedID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully unmuted user"})
+}
+
+func (m Mute) GetMutes(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ConnectionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	mutes, nextCursor, err := m.muteUsecase.GetMutes(ctx, userID, request.Limit, request.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToMutesResponse(mutes, request.Limit, nextCursor))
+}
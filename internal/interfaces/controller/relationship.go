@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelationshipController interface {
+	GetRelationships(ctx *gin.Context)
+}
+
+type Relationship struct {
+	relationshipUsecase usecase.RelationshipUsecase
+}
+
+func NewRelationship(relationshipUsecase usecase.RelationshipUsecase) Relationship {
+	return Relationship{
+		relationshipUsecase: relationshipUsecase,
+	}
+}
+
+func (r Relationship) GetRelationships(ctx *gin.Context) {
+
+	var request dto.RelationshipsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetIDs, err := request.TargetIDs()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(targetIDs) > usecase.MaxRelationshipTargets {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d targets can be checked at once", usecase.MaxRelationshipTargets)})
+		return
+	}
+
+	relationships, err := r.relationshipUsecase.GetRelationships(ctx, request.Source, targetIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToRelationshipsResponse(request.Source, relationships))
+}
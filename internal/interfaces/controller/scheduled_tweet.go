@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduledTweetController interface {
+	ScheduleTweet(ctx *gin.Context)
+	GetScheduledTweets(ctx *gin.Context)
+	CancelScheduledTweet(ctx *gin.Context)
+	PublishDueTweets(ctx context.Context) error
+}
+
+type ScheduledTweet struct {
+	scheduledTweetUsecase usecase.ScheduledTweetUsecase
+}
+
+func NewScheduledTweet(scheduledTweetUsecase usecase.ScheduledTweetUsecase) ScheduledTweet {
+	return ScheduledTweet{
+		scheduledTweetUsecase: scheduledTweetUsecase,
+	}
+}
+
+func (s ScheduledTweet) ScheduleTweet(ctx *gin.Context) {
+
+	createScheduledTweetRequest := dto.CreateScheduledTweetRequest{}
+	if err := ctx.ShouldBindJSON(&createScheduledTweetRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledTweet := dto.ToScheduledTweetDomain(createScheduledTweetRequest)
+	newScheduledTweet, err := s.scheduledTweetUsecase.ScheduleTweet(ctx, scheduledTweet)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.ToScheduledTweetResponse(newScheduledTweet))
+}
+
+func (s ScheduledTweet) GetScheduledTweets(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.ScheduledTweetsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledTweets, err := s.scheduledTweetUsecase.GetScheduledTweets(ctx, userID, request.Status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledTweetResponses := make([]dto.ScheduledTweetResponse, len(scheduledTweets))
+	for i, scheduledTweet := range scheduledTweets {
+		scheduledTweetResponses[i] = dto.ToScheduledTweetResponse(scheduledTweet)
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTweetResponses)
+}
+
+func (s ScheduledTweet) CancelScheduledTweet(ctx *gin.Context) {
+
+	cancelScheduledTweetRequest := dto.CancelScheduledTweetRequest{}
+	if err := ctx.ShouldBindJSON(&cancelScheduledTweetRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	err = s.scheduledTweetUsecase.CancelScheduledTweet(ctx, id, cancelScheduledTweetRequest.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully canceled scheduled tweet"})
+}
+
+// PublishDueTweets is the scheduler tick handler run periodically by the worker.
+func (s ScheduledTweet) PublishDueTweets(ctx context.Context) error {
+
+	published, err := s.scheduledTweetUsecase.PublishDueTweets(ctx)
+	if err != nil {
+		log.Printf("Scheduler failed: %v", err)
+		return err
+	}
+
+	if published > 0 {
+		log.Printf("Published %d scheduled tweets", published)
+	}
+
+	return nil
+}
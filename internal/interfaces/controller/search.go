@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController interface {
+	SearchTweets(ctx *gin.Context)
+}
+
+type Search struct {
+	searchUsecase   usecase.SearchUsecase
+	timelineUsecase usecase.TimelineUsecase
+}
+
+func NewSearch(searchUsecase usecase.SearchUsecase, timelineUsecase usecase.TimelineUsecase) Search {
+	return Search{
+		searchUsecase:   searchUsecase,
+		timelineUsecase: timelineUsecase,
+	}
+}
+
+func (s Search) SearchTweets(ctx *gin.Context) {
+	// Get the query, filters and pagination parameters from query string
+	var request dto.SearchTweetsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	tweets, nextCursor, err := s.searchUsecase.SearchTweets(ctx, request.Query, request.ViewerID, request.Sort, request.SinceID, request.Cursor, request.Limit)
+	if errors.Is(err, usecase.ErrInvalidSearchQuery) || errors.Is(err, usecase.ErrInvalidSearchCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	authors, err := s.timelineUsecase.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, dto.ToSearchTweetsResponse(tweets, authors, request.Limit, nextCursor))
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchIndexController interface {
+	SearchTweets(ctx *gin.Context)
+	GetIndexStats(ctx *gin.Context)
+	HandleEvent(ctx context.Context, key, value []byte) error
+	FlushIndex(ctx context.Context) error
+}
+
+type SearchIndex struct {
+	searchIndexUsecase usecase.SearchIndexUsecase
+}
+
+func NewSearchIndex(searchIndexUsecase usecase.SearchIndexUsecase) SearchIndex {
+	return SearchIndex{
+		searchIndexUsecase: searchIndexUsecase,
+	}
+}
+
+func (s SearchIndex) SearchTweets(ctx *gin.Context) {
+	// Get the query and pagination parameters from query string
+	var request dto.SearchIndexRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	hits, total, err := s.searchIndexUsecase.SearchTweets(ctx, request.Query, request.ViewerID, request.Limit, request.Offset)
+	if errors.Is(err, usecase.ErrInvalidSearchQuery) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, dto.ToSearchIndexResponse(hits, total, request.Limit, request.Offset))
+}
+
+func (s SearchIndex) GetIndexStats(ctx *gin.Context) {
+
+	stats, err := s.searchIndexUsecase.GetIndexStats(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToIndexStatsResponse(stats))
+}
+
+// HandleEvent is the Kafka message handler of the search service. New and edited tweets
+// are indexed, deleted ones are removed from the index, and users turning protected or
+// public are recorded so searches hide or show their tweets. Other events are ignored.
+func (s SearchIndex) HandleEvent(ctx context.Context, key, value []byte) error {
+
+	var event dto.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	switch event.Type {
+	case dto.TweetCreatedEvent:
+		var tweetData dto.TweetCreatedEventData
+		if err := parseEventData(event, &tweetData); err != nil {
+			return err
+		}
+
+		tweet := domain.IndexedTweet{
+			ID:        tweetData.TweetID,
+			UserID:    tweetData.UserID,
+			Content:   tweetData.Content,
+			CreatedAt: tweetData.CreatedAt,
+			UpdatedAt: tweetData.CreatedAt,
+		}
+		if err := s.searchIndexUsecase.IndexTweet(ctx, tweet); err != nil {
+			log.Printf("Failed to index tweet %d: %v", tweetData.TweetID, err)
+			return err
+		}
+
+	case dto.TweetUpdatedEvent:
+		var tweetData dto.TweetUpdatedEventData
+		if err := parseEventData(event, &tweetData); err != nil {
+			return err
+		}
+
+		tweet := domain.IndexedTweet{
+			ID:        tweetData.TweetID,
+			UserID:    tweetData.UserID,
+			Content:   tweetData.Content,
+			CreatedAt: tweetData.CreatedAt,
+			UpdatedAt: tweetData.UpdatedAt,
+		}
+		if err := s.searchIndexUsecase.IndexTweet(ctx, tweet); err != nil {
+			log.Printf("Failed to reindex tweet %d: %v", tweetData.TweetID, err)
+			return err
+		}
+
+	case dto.TweetDeletedEvent:
+		var tweetData dto.TweetDeletedEventData
+		if err := parseEventData(event, &tweetData); err != nil {
+			return err
+		}
+
+		if err := s.searchIndexUsecase.DeleteTweet(ctx, tweetData.TweetID); err != nil {
+			log.Printf("Failed to remove tweet %d from index: %v", tweetData.TweetID, err)
+			return err
+		}
+
+	case dto.UserUpdatedEvent:
+		var userData dto.UserUpdatedEventData
+		if err := parseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		if err := s.searchIndexUsecase.SetAuthorProtected(ctx, userData.UserID, userData.Protected); err != nil {
+			log.Printf("Failed to update protected state of user %d in index: %v", userData.UserID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FlushIndex is the flush tick handler run periodically by the search service.
+func (s SearchIndex) FlushIndex(ctx context.Context) error {
+
+	if err := s.searchIndexUsecase.FlushIndex(ctx); err != nil {
+		log.Printf("Index flush failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// parseEventData decodes the data of an event into the struct of its type.
+func parseEventData(event dto.Event, data any) error {
+
+	dataBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	if err := json.Unmarshal(dataBytes, data); err != nil {
+		return fmt.Errorf("failed to parse event data: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SuggestionController interface {
+	GetSuggestions(ctx *gin.Context)
+	RefreshSuggestions(ctx context.Context) error
+}
+
+type Suggestion struct {
+	suggestionUsecase usecase.SuggestionUsecase
+}
+
+func NewSuggestion(suggestionUsecase usecase.SuggestionUsecase) Suggestion {
+	return Suggestion{
+		suggestionUsecase: suggestionUsecase,
+	}
+}
+
+func (s Suggestion) GetSuggestions(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var request dto.SuggestionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestions, err := s.suggestionUsecase.GetSuggestions(ctx, userID, request.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToSuggestionsResponse(suggestions))
+}
+
+// RefreshSuggestions is the suggestions tick handler run periodically by the worker.
+func (s Suggestion) RefreshSuggestions(ctx context.Context) error {
+
+	processed, err := s.suggestionUsecase.RefreshSuggestions(ctx)
+	if errors.Is(err, usecase.ErrRefreshInProgress) {
+		log.Print("Suggestions refresh skipped, another worker is running it")
+		return nil
+	}
+	if err != nil {
+		log.Printf("Suggestions refresh failed after %d users: %v", processed, err)
+		return err
+	}
+
+	log.Printf("Refreshed suggestions for %d users", processed)
+
+	return nil
+}
@@ -3,11 +3,13 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
 	"twitter-demo/internal/interfaces/dto"
 	"twitter-demo/internal/usecase"
 
@@ -16,16 +18,25 @@ import (
 
 type TimelineController interface {
 	GetTimeline(ctx *gin.Context)
+	GetUserTweets(ctx *gin.Context)
+	GetListTimeline(ctx *gin.Context)
+	GetMentions(ctx *gin.Context)
+	HandleEvent(ctx context.Context, key, value []byte) error
 	HandleTweetCreated(ctx context.Context, key, value []byte) error
+	HandleTweetUpdated(ctx context.Context, key, value []byte) error
+	HandleRelationshipChanged(ctx context.Context, key, value []byte) error
+	PruneActivity(ctx context.Context) error
 }
 
 type Timeline struct {
-	timelineUsecase usecase.TimelineUsecase
+	timelineUsecase    usecase.TimelineUsecase
+	socialGraphUsecase usecase.SocialGraphUsecase
 }
 
-func NewTimeline(timelineUsecase usecase.TimelineUsecase) Timeline {
+func NewTimeline(timelineUsecase usecase.TimelineUsecase, socialGraphUsecase usecase.SocialGraphUsecase) Timeline {
 	return Timeline{
-		timelineUsecase: timelineUsecase,
+		timelineUsecase:    timelineUsecase,
+		socialGraphUsecase: socialGraphUsecase,
 	}
 }
 
@@ -58,18 +69,195 @@ func (t Timeline) GetTimeline(ctx *gin.Context) {
 		request.Offset = 0
 	}
 
-	// Get timeline tweets
-	tweets, err := t.timelineUsecase.GetTimeline(ctx, userID, request.Limit, request.Offset)
+	// Get timeline tweets, newest first or ranked
+	var tweets []domain.Tweet
+	var snapshot string
+	if request.Mode == dto.TimelineModeRanked {
+		tweets, snapshot, err = t.timelineUsecase.GetRankedTimeline(ctx, userID, request.Limit, request.Offset, request.Snapshot, request.ExcludeSelf)
+	} else {
+		tweets, err = t.timelineUsecase.GetTimeline(ctx, userID, request.Limit, request.Offset, request.ExcludeSelf)
+	}
+	if errors.Is(err, usecase.ErrRankingSnapshotExpired) {
+		ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return response
+	authors, err := t.timelineUsecase.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.ToTimelineResponse(tweets, authors, request.Limit, request.Offset)
+	response.Snapshot = snapshot
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (t Timeline) GetUserTweets(ctx *gin.Context) {
+	// Get user ID from URL parameter
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	// Get pagination and filter parameters from query string
+	var request dto.UserTweetsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	// Replies are hidden and retweets shown unless asked otherwise, like a profile's main tab
+	filter := domain.TweetFilter{IncludeReplies: false, IncludeRetweets: true}
+	if request.IncludeReplies != nil {
+		filter.IncludeReplies = *request.IncludeReplies
+	}
+	if request.IncludeRetweets != nil {
+		filter.IncludeRetweets = *request.IncludeRetweets
+	}
+
+	// Get the user's tweets
+	tweets, pinnedTweetID, nextCursor, err := t.timelineUsecase.GetUserTweets(ctx, userID, request.ViewerID, request.Limit, request.Cursor, filter)
+	if errors.Is(err, usecase.ErrProtectedAccount) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Return response
-	response := dto.ToTimelineResponse(tweets, request.Limit, request.Offset)
+	response := dto.ToUserTweetsResponse(tweets, pinnedTweetID, request.Limit, nextCursor)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (t Timeline) GetMentions(ctx *gin.Context) {
+	// Get user ID from URL parameter
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	// Get pagination parameters from query string
+	var request dto.MentionsRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	// Get the tweets mentioning the user
+	tweets, nextCursor, err := t.timelineUsecase.GetMentions(ctx, userID, request.Limit, request.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	authors, err := t.timelineUsecase.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return response
+	response := dto.ToMentionsResponse(tweets, authors, request.Limit, nextCursor)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (t Timeline) GetListTimeline(ctx *gin.Context) {
+	// Get list ID from URL parameter
+	listID, ok := parseListID(ctx)
+	if !ok {
+		return
+	}
+
+	// Get pagination parameters from query string
+	var request dto.ListTimelineRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values if not provided
+	if request.Limit <= 0 {
+		request.Limit = config.DefaultLimit
+	}
+
+	if request.Limit > config.MaxLimit {
+		request.Limit = config.MaxLimit
+	}
+
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	// Get list timeline tweets
+	tweets, err := t.timelineUsecase.GetListTimeline(ctx, listID, request.ViewerID, request.Limit, request.Offset)
+	if err != nil {
+		writeListError(ctx, err)
+		return
+	}
+
+	// Return response
+	authors, err := t.timelineUsecase.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.ToTimelineResponse(tweets, authors, request.Limit, request.Offset)
 	ctx.JSON(http.StatusOK, response)
 }
 
+// HandleEvent is the Kafka message handler for every topic consumed by the worker.
+// It routes each event to its handler based on the event type.
+func (t Timeline) HandleEvent(ctx context.Context, key, value []byte) error {
+
+	var event dto.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	switch event.Type {
+	case dto.TweetCreatedEvent:
+		return t.HandleTweetCreated(ctx, key, value)
+	case dto.TweetUpdatedEvent:
+		return t.HandleTweetUpdated(ctx, key, value)
+	case dto.UserFollowedEvent, dto.UserUnfollowedEvent, dto.UserBlockedEvent, dto.UserUnblockedEvent, dto.UserMutedEvent, dto.UserUnmutedEvent:
+		return t.HandleRelationshipChanged(ctx, key, value)
+	default:
+		log.Printf("Ignoring event type: %s", event.Type)
+		return nil
+	}
+}
+
 // HandleTweetCreated is the Kafka message handler for tweet.created events.
 // It implements the Fan-Out pattern by distributing the tweet to all followers' timelines.
 func (t Timeline) HandleTweetCreated(ctx context.Context, key, value []byte) error {
@@ -101,6 +289,20 @@ func (t Timeline) HandleTweetCreated(ctx context.Context, key, value []byte) err
 	log.Printf("Tweet ID: %d, Author: %d", tweetData.TweetID, tweetData.UserID)
 	log.Printf("   Content: %s", tweetData.Content)
 
+	// Cache the tweet first, so the timelines it lands in are hydrated without SQL
+	tweet := domain.Tweet{
+		ID:             tweetData.TweetID,
+		UserID:         tweetData.UserID,
+		Content:        tweetData.Content,
+		ReplyToTweetID: tweetData.ReplyToTweetID,
+		RetweetOfID:    tweetData.RetweetOfID,
+		CreatedAt:      tweetData.CreatedAt,
+		UpdatedAt:      tweetData.CreatedAt,
+	}
+	if err := t.timelineUsecase.CacheTweet(ctx, tweet); err != nil {
+		log.Printf("Failed to cache tweet: %v", err)
+	}
+
 	// Fan-Out: Distribute tweet to all followers' timelines
 	if err := t.timelineUsecase.FanOutTweet(ctx, tweetData.UserID, tweetData.TweetID); err != nil {
 		log.Printf("Fan-Out failed: %v", err)
@@ -108,5 +310,160 @@ func (t Timeline) HandleTweetCreated(ctx context.Context, key, value []byte) err
 	}
 
 	log.Println("Fan-Out completed successfully")
+
+	// Keep the author's profile list up to date
+	if err := t.timelineUsecase.PushUserTweet(ctx, tweet); err != nil {
+		log.Printf("Failed to update user tweets cache: %v", err)
+	}
+
+	// Add the tweet to the mentions of the users it mentions
+	if err := t.timelineUsecase.PushMentions(ctx, tweet); err != nil {
+		log.Printf("Failed to update mentions cache: %v", err)
+	}
+
+	return nil
+}
+
+// HandleTweetUpdated is the Kafka message handler for tweet.updated events.
+// The cached copy of the edited tweet is replaced by the new version.
+func (t Timeline) HandleTweetUpdated(ctx context.Context, key, value []byte) error {
+	log.Printf("Received TweetUpdatedEvent - Key: %s", string(key))
+
+	var event dto.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	var tweetData dto.TweetUpdatedEventData
+	if err := json.Unmarshal(dataBytes, &tweetData); err != nil {
+		return fmt.Errorf("failed to parse tweet data: %w", err)
+	}
+
+	if err := t.timelineUsecase.RefreshTweet(ctx, tweetData.TweetID); err != nil {
+		log.Printf("Failed to refresh cached tweet: %v", err)
+		return err
+	}
+
+	log.Printf("Refreshed cached tweet %d", tweetData.TweetID)
+
+	// The edit may have added or removed mentions
+	previous := domain.Tweet{ID: tweetData.TweetID, UserID: tweetData.UserID, Content: tweetData.PreviousContent}
+	current := domain.Tweet{ID: tweetData.TweetID, UserID: tweetData.UserID, Content: tweetData.Content}
+	if err := t.timelineUsecase.InvalidateMentions(ctx, previous, current); err != nil {
+		log.Printf("Failed to invalidate mentions: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// HandleRelationshipChanged is the Kafka message handler for follow, block and mute events.
+// A new follow (or an approved follow request) backfills the follower's cached timeline
+// with the recent tweets of the followed user, unless it comes from a bulk import. For the other events the cached timelines
+// of the affected users are dropped so they are rebuilt with the new relationship applied.
+// A block affects both users, an unfollow or a mute only the user who made it.
+// Follows, unfollows and blocks are also applied to the social graph of the worker.
+func (t Timeline) HandleRelationshipChanged(ctx context.Context, key, value []byte) error {
+	log.Printf("Received relationship event - Key: %s", string(key))
+
+	var event dto.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	var relationshipData dto.UserRelationshipEventData
+	if err := json.Unmarshal(dataBytes, &relationshipData); err != nil {
+		return fmt.Errorf("failed to parse relationship data: %w", err)
+	}
+
+	// The relationship may have changed in another process, keep the graph of this one in sync
+	if err := t.applyToSocialGraph(ctx, event.Type, relationshipData); err != nil {
+		log.Printf("Failed to apply %s to the social graph: %v", event.Type, err)
+	}
+
+	// Follows, unfollows and blocks (which remove follows) change the follow counts
+	if event.Type == dto.UserFollowedEvent || event.Type == dto.UserUnfollowedEvent || event.Type == dto.UserBlockedEvent {
+		if err := t.timelineUsecase.InvalidateProfiles(ctx, relationshipData.UserID, relationshipData.TargetUserID); err != nil {
+			log.Printf("Failed to invalidate profiles: %v", err)
+		}
+	}
+
+	if event.Type == dto.UserFollowedEvent {
+		if relationshipData.Bulk {
+			log.Printf("Skipping backfill of user %d, bulk import in progress", relationshipData.UserID)
+			return nil
+		}
+
+		if err := t.timelineUsecase.BackfillTimeline(ctx, relationshipData.UserID, relationshipData.TargetUserID); err != nil {
+			log.Printf("Backfill failed: %v", err)
+			return err
+		}
+
+		log.Printf("Backfilled timeline of user %d with tweets of user %d", relationshipData.UserID, relationshipData.TargetUserID)
+		return nil
+	}
+
+	userIDs := []int64{relationshipData.UserID}
+	if event.Type == dto.UserBlockedEvent || event.Type == dto.UserUnblockedEvent {
+		userIDs = append(userIDs, relationshipData.TargetUserID)
+	}
+
+	// Mutes and blocks change who the users hide, drop it before the timelines are rebuilt
+	if event.Type != dto.UserUnfollowedEvent {
+		if err := t.timelineUsecase.InvalidateHiddenUsers(ctx, userIDs...); err != nil {
+			log.Printf("Failed to invalidate hidden users: %v", err)
+			return err
+		}
+	}
+
+	for _, userID := range userIDs {
+		if err := t.timelineUsecase.InvalidateTimeline(ctx, userID); err != nil {
+			log.Printf("Failed to invalidate timeline: %v", err)
+			return err
+		}
+	}
+
+	log.Printf("%s: invalidated timelines of users %v", event.Type, userIDs)
+
+	return nil
+}
+
+// applyToSocialGraph applies the follows, unfollows and blocks to the social graph.
+func (t Timeline) applyToSocialGraph(ctx context.Context, eventType dto.EventType, relationshipData dto.UserRelationshipEventData) error {
+
+	switch eventType {
+	case dto.UserFollowedEvent:
+		return t.socialGraphUsecase.ApplyFollow(ctx, relationshipData.UserID, relationshipData.TargetUserID)
+	case dto.UserUnfollowedEvent:
+		return t.socialGraphUsecase.ApplyUnfollow(ctx, relationshipData.UserID, relationshipData.TargetUserID)
+	case dto.UserBlockedEvent:
+		return t.socialGraphUsecase.ApplyBlock(ctx, relationshipData.UserID, relationshipData.TargetUserID)
+	}
+
+	return nil
+}
+
+// PruneActivity removes the users who left the active and online windows from their
+// sets. It is run periodically by the worker.
+func (t Timeline) PruneActivity(ctx context.Context) error {
+
+	removed, err := t.timelineUsecase.PruneActivity(ctx)
+	if err != nil {
+		log.Printf("Activity pruning failed after %d users: %v", removed, err)
+		return err
+	}
+
+	log.Printf("Pruned %d inactive users", removed)
+
 	return nil
 }
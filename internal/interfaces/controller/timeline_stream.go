@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimelineStreamController interface {
+	StreamTimeline(ctx *gin.Context)
+}
+
+type TimelineStream struct {
+	streamUsecase   usecase.TimelineStreamUsecase
+	timelineUsecase usecase.TimelineUsecase
+	config          config.StreamConfig
+}
+
+func NewTimelineStream(streamUsecase usecase.TimelineStreamUsecase, timelineUsecase usecase.TimelineUsecase, streamConfig config.StreamConfig) TimelineStream {
+	return TimelineStream{
+		streamUsecase:   streamUsecase,
+		timelineUsecase: timelineUsecase,
+		config:          streamConfig,
+	}
+}
+
+// StreamTimeline pushes the tweets landing in a user's timeline as server-sent events.
+// A client reconnecting with the Last-Event-ID header (or the last_event_id query
+// parameter) first gets the events it missed. A client too slow to keep up is
+// disconnected and resumes the same way.
+func (t TimelineStream) StreamTimeline(ctx *gin.Context) {
+	// Get user ID from URL parameter
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	subscription, err := t.streamUsecase.Subscribe(ctx, userID, lastEventID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Keep proxies such as nginx from buffering the events
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	if err := t.writeEvents(ctx, subscription.Backlog, &lastEventID); err != nil {
+		log.Printf("Failed to write timeline stream of user %d: %v", userID, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(t.config.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Too slow to keep up, the client reconnects from its last event
+				return
+			}
+
+			if err := t.writeEvents(ctx, []domain.TimelineEvent{event}, &lastEventID); err != nil {
+				log.Printf("Failed to write timeline stream of user %d: %v", userID, err)
+				return
+			}
+
+		case <-heartbeat.C:
+			t.streamUsecase.KeepAlive(ctx, userID)
+
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// writeEvents sends the tweets of the events after lastEventID, with their author, and
+// moves lastEventID to the last event sent. Events already sent, received both in the
+// backlog and live, are skipped.
+func (t TimelineStream) writeEvents(ctx *gin.Context, events []domain.TimelineEvent, lastEventID *string) error {
+
+	pending := make([]domain.TimelineEvent, 0, len(events))
+	tweetIDs := make([]int64, 0, len(events))
+	for _, event := range events {
+		if event.After(*lastEventID) {
+			pending = append(pending, event)
+			tweetIDs = append(tweetIDs, event.TweetID)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	tweets, err := t.timelineUsecase.HydrateTweets(ctx, tweetIDs)
+	if err != nil {
+		return err
+	}
+
+	authors, err := t.timelineUsecase.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		return err
+	}
+
+	responses := dto.ToTimelineResponse(tweets, authors, len(tweets), 0).Tweets
+	byID := make(map[int64]dto.TweetResponse, len(responses))
+	for _, response := range responses {
+		byID[response.ID] = response
+	}
+
+	for _, event := range pending {
+		*lastEventID = event.ID
+
+		// Tweets deleted since they were streamed are skipped
+		response, ok := byID[event.TweetID]
+		if !ok {
+			continue
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(ctx.Writer, "id: %s\nevent: tweet\ndata: %s\n\n", event.ID, data); err != nil {
+			return err
+		}
+	}
+
+	ctx.Writer.Flush()
+
+	return nil
+}
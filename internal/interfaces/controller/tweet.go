@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"twitter-demo/internal/interfaces/dto"
@@ -11,8 +12,12 @@ import (
 
 type TweetController interface {
 	GetTweetByID(ctx *gin.Context)
+	GetTweetHistory(ctx *gin.Context)
 	CreateTweet(ctx *gin.Context)
 	UpdateTweetByID(ctx *gin.Context)
+	ValidateTweet(ctx *gin.Context)
+	PinTweet(ctx *gin.Context)
+	UnpinTweet(ctx *gin.Context)
 }
 
 type Tweet struct {
@@ -34,7 +39,17 @@ func (t Tweet) GetTweetByID(ctx *gin.Context) {
 		return
 	}
 
-	tweet, err := t.tweetUsecase.GetTweetByID(ctx, id)
+	var request dto.ViewerRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweet, err := t.tweetUsecase.GetTweetByID(ctx, id, request.ViewerID)
+	if errors.Is(err, usecase.ErrProtectedAccount) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,6 +63,39 @@ func (t Tweet) GetTweetByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dto.ToTweetResponse(tweet))
 }
 
+func (t Tweet) GetTweetHistory(ctx *gin.Context) {
+
+	idString := ctx.Param("id")
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var request dto.ViewerRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweet, revisions, err := t.tweetUsecase.GetTweetHistory(ctx, id, request.ViewerID)
+	if errors.Is(err, usecase.ErrProtectedAccount) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tweet.ID == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "tweet not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToTweetHistoryResponse(tweet, revisions))
+}
+
 func (t Tweet) CreateTweet(ctx *gin.Context) {
 
 	createTweetRequest := dto.CreateTweetRequest{}
@@ -83,6 +131,10 @@ func (t Tweet) UpdateTweetByID(ctx *gin.Context) {
 
 	tweet := dto.ToUpdateTweetDomain(updateTweetRequest)
 	updatedTweet, err := t.tweetUsecase.UpdateTweetByID(ctx, id, tweet)
+	if errors.Is(err, usecase.ErrEditLimit) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -90,3 +142,60 @@ func (t Tweet) UpdateTweetByID(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, dto.ToTweetResponse(updatedTweet))
 }
+
+// ValidateTweet measures draft content without publishing it, so clients can show
+// the remaining characters while the user is composing.
+func (t Tweet) ValidateTweet(ctx *gin.Context) {
+
+	validateTweetRequest := dto.ValidateTweetRequest{}
+	if err := ctx.ShouldBindJSON(&validateTweetRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	length, err := t.tweetUsecase.ValidateContent(validateTweetRequest.Content)
+
+	ctx.JSON(http.StatusOK, dto.ToValidateTweetResponse(length, err))
+}
+
+func (t Tweet) PinTweet(ctx *gin.Context) {
+
+	pinTweetRequest := dto.PinTweetRequest{}
+	if err := ctx.ShouldBindJSON(&pinTweetRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	err = t.tweetUsecase.PinTweet(ctx, userID, pinTweetRequest.TweetID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully pinned tweet"})
+}
+
+func (t Tweet) UnpinTweet(ctx *gin.Context) {
+
+	userIDString := ctx.Param("id")
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	err = t.tweetUsecase.UnpinTweet(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "successfully unpinned tweet"})
+}
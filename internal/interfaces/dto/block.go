@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+type BlockRequest struct {
+	BlockerID int64 `json:"blocker_id" binding:"required"`
+	BlockedID int64 `json:"blocked_id" binding:"required"`
+}
+
+type BlockResponse struct {
+	ID        int64     `json:"id"`
+	BlockerID int64     `json:"blocker_id"`
+	BlockedID int64     `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToBlockResponse(block domain.Block) BlockResponse {
+	return BlockResponse{
+		ID:        block.ID,
+		BlockerID: block.BlockerID,
+		BlockedID: block.BlockedID,
+		CreatedAt: block.CreatedAt,
+	}
+}
+
+type BlocksResponse struct {
+	Blocks     []BlockResponse `json:"blocks"`
+	Limit      int             `json:"limit"`
+	NextCursor int64           `json:"next_cursor,omitempty"`
+}
+
+func ToBlocksResponse(blocks []domain.Block, limit int, nextCursor int64) BlocksResponse {
+	blockResponses := make([]BlockResponse, 0, len(blocks))
+	for _, block := range blocks {
+		blockResponses = append(blockResponses, ToBlockResponse(block))
+	}
+
+	return BlocksResponse{
+		Blocks:     blockResponses,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
+
+type MuteRequest struct {
+	MuterID int64 `json:"muter_id" binding:"required"`
+	MutedID int64 `json:"muted_id" binding:"required"`
+}
+
+type MuteResponse struct {
+	ID        int64     `json:"id"`
+	MuterID   int64     `json:"muter_id"`
+	MutedID   int64     `json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToMuteResponse(mute domain.Mute) MuteResponse {
+	return MuteResponse{
+		ID:        mute.ID,
+		MuterID:   mute.MuterID,
+		MutedID:   mute.MutedID,
+		CreatedAt: mute.CreatedAt,
+	}
+}
+
+type MutesResponse struct {
+	Mutes      []MuteResponse `json:"mutes"`
+	Limit      int            `json:"limit"`
+	NextCursor int64          `json:"next_cursor,omitempty"`
+}
+
+func ToMutesResponse(mutes []domain.Mute, limit int, nextCursor int64) MutesResponse {
+	muteResponses := make([]MuteResponse, 0, len(mutes))
+	for _, mute := range mutes {
+		muteResponses = append(muteResponses, ToMuteResponse(mute))
+	}
+
+	return MutesResponse{
+		Mutes:      muteResponses,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
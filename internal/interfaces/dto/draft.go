@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+type CreateDraftRequest struct {
+	UserID  int64  `json:"user_id" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
+type UpdateDraftRequest struct {
+	UserID  int64  `json:"user_id" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
+type DraftOwnerRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
+type DraftResponse struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToDraftResponse(draft domain.Draft) DraftResponse {
+	return DraftResponse{
+		ID:        draft.ID,
+		UserID:    draft.UserID,
+		Content:   draft.Content,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+	}
+}
+
+func ToDraftDomain(request CreateDraftRequest) domain.Draft {
+	return domain.Draft{
+		UserID:  request.UserID,
+		Content: request.Content,
+	}
+}
+
+func ToUpdateDraftDomain(request UpdateDraftRequest) domain.Draft {
+	return domain.Draft{
+		UserID:  request.UserID,
+		Content: request.Content,
+	}
+}
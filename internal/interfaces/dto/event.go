@@ -8,6 +8,24 @@ type EventType string
 const (
 	// TweetCreatedEvent is published when a new tweet is created
 	TweetCreatedEvent EventType = "tweet.created"
+	// TweetUpdatedEvent is published when the content of a tweet is edited
+	TweetUpdatedEvent EventType = "tweet.updated"
+	// TweetDeletedEvent is published when a tweet is deleted
+	TweetDeletedEvent EventType = "tweet.deleted"
+	// UserFollowedEvent is published when a follow relationship is created, directly or by approving a follow request
+	UserFollowedEvent EventType = "user.followed"
+	// UserUnfollowedEvent is published when a user stops following another user
+	UserUnfollowedEvent EventType = "user.unfollowed"
+	// UserBlockedEvent is published when a user blocks another user
+	UserBlockedEvent EventType = "user.blocked"
+	// UserUnblockedEvent is published when a user removes a block
+	UserUnblockedEvent EventType = "user.unblocked"
+	// UserMutedEvent is published when a user mutes another user
+	UserMutedEvent EventType = "user.muted"
+	// UserUnmutedEvent is published when a user removes a mute
+	UserUnmutedEvent EventType = "user.unmuted"
+	// UserUpdatedEvent is published when a user changes their profile
+	UserUpdatedEvent EventType = "user.updated"
 )
 
 // Event is a generic event wrapper for all domain events
@@ -20,10 +38,48 @@ type Event struct {
 // TweetCreatedEventData contains the data for a tweet.created event
 // This is used for the Fan-Out pattern to distribute tweets to followers' timelines
 type TweetCreatedEventData struct {
+	TweetID        int64     `json:"tweet_id"`
+	UserID         int64     `json:"user_id"`
+	Content        string    `json:"content"`
+	ReplyToTweetID int64     `json:"reply_to_tweet_id,omitempty"`
+	RetweetOfID    int64     `json:"retweet_of_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TweetUpdatedEventData contains the data for a tweet.updated event
+// Consumers holding a copy of the tweet (caches, search indexes) use it to refresh it
+type TweetUpdatedEventData struct {
+	TweetID         int64     `json:"tweet_id"`
+	UserID          int64     `json:"user_id"`
+	Content         string    `json:"content"`
+	PreviousContent string    `json:"previous_content"` // To update what depended on it, e.g. mentions
+	EditCount       int       `json:"edit_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TweetDeletedEventData contains the data for a tweet.deleted event
+// Consumers holding a copy of the tweet (caches, search indexes) use it to drop it
+type TweetDeletedEventData struct {
 	TweetID   int64     `json:"tweet_id"`
 	UserID    int64     `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// UserRelationshipEventData contains the data for follow, block and mute events
+// Timelines of the affected users are rebuilt so the change is reflected in cache
+type UserRelationshipEventData struct {
+	UserID       int64 `json:"user_id"`        // The one who follows, blocks or mutes
+	TargetUserID int64 `json:"target_user_id"` // The one who is followed, blocked or muted
+	Bulk         bool  `json:"bulk,omitempty"` // Set on follows made by a bulk import, backfilled once when the import completes
+}
+
+// UserUpdatedEventData contains the data for a user.updated event
+// Consumers filtering tweets by the visibility of their author (search indexes) use it
+type UserUpdatedEventData struct {
+	UserID    int64     `json:"user_id"`
+	Protected bool      `json:"protected"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // NewEvent creates a new Event with the current timestamp
@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+// CreateFollowImportRequest lists the accounts to follow by username, by ID or both.
+// The same import can be sent as a multipart form with user_id and a CSV file instead.
+type CreateFollowImportRequest struct {
+	UserID    int64    `json:"user_id" binding:"required"`
+	Usernames []string `json:"usernames"`
+	UserIDs   []int64  `json:"user_ids"`
+}
+
+// Targets returns the usernames and IDs of the request as import targets.
+func (r CreateFollowImportRequest) Targets() []string {
+	targets := make([]string, 0, len(r.Usernames)+len(r.UserIDs))
+	targets = append(targets, r.Usernames...)
+	for _, id := range r.UserIDs {
+		targets = append(targets, strconv.FormatInt(id, 10))
+	}
+	return targets
+}
+
+// ParseFollowTargetsCSV reads the import targets from the first column of a CSV file,
+// usernames or user IDs. A header row named "id" or "username" is skipped, so a file
+// produced by the following export can be imported as is.
+func ParseFollowTargetsCSV(reader io.Reader) ([]string, error) {
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	var targets []string
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) == 0 {
+			continue
+		}
+
+		target := strings.TrimSpace(record[0])
+		if len(targets) == 0 && (strings.EqualFold(target, "id") || strings.EqualFold(target, "username")) {
+			continue
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+type FollowImportFailureResponse struct {
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+type FollowImportResponse struct {
+	ID        int64                         `json:"id"`
+	UserID    int64                         `json:"user_id"`
+	Status    string                        `json:"status"`
+	Total     int                           `json:"total"`
+	Processed int                           `json:"processed"`
+	Followed  int                           `json:"followed"`
+	Requested int                           `json:"requested"`
+	Skipped   int                           `json:"skipped"`
+	Failed    int                           `json:"failed"`
+	Failures  []FollowImportFailureResponse `json:"failures"`
+	CreatedAt time.Time                     `json:"created_at"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+}
+
+func ToFollowImportResponse(followImport domain.FollowImport) FollowImportResponse {
+	failures := make([]FollowImportFailureResponse, 0, len(followImport.Failures))
+	for _, failure := range followImport.Failures {
+		failures = append(failures, FollowImportFailureResponse{
+			Target: failure.Target,
+			Reason: failure.Reason,
+		})
+	}
+
+	return FollowImportResponse{
+		ID:        followImport.ID,
+		UserID:    followImport.UserID,
+		Status:    followImport.Status,
+		Total:     len(followImport.Targets),
+		Processed: followImport.ProcessedCount,
+		Followed:  followImport.FollowedCount,
+		Requested: followImport.RequestedCount,
+		Skipped:   followImport.SkippedCount,
+		Failed:    followImport.FailedCount,
+		Failures:  failures,
+		CreatedAt: followImport.CreatedAt,
+		UpdatedAt: followImport.UpdatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"strconv"
 	"time"
 	"twitter-demo/internal/domain"
 )
@@ -37,3 +38,93 @@ func ToFollowerDomain(request FollowRequest) domain.Follower {
 		FollowedID: request.FollowedID,
 	}
 }
+
+type ConnectionsRequest struct {
+	Limit  int   `form:"limit"`
+	Cursor int64 `form:"cursor"`
+}
+
+type ConnectionResponse struct {
+	User       UserSummaryResponse `json:"user"`
+	FollowedAt time.Time           `json:"followed_at"`
+}
+
+type ConnectionsResponse struct {
+	Users      []ConnectionResponse `json:"users"`
+	Limit      int                  `json:"limit"`
+	NextCursor int64                `json:"next_cursor,omitempty"`
+}
+
+func ToConnectionsResponse(connections []domain.Connection, limit int, nextCursor int64) ConnectionsResponse {
+	connectionResponses := make([]ConnectionResponse, 0, len(connections))
+	for _, connection := range connections {
+		connectionResponses = append(connectionResponses, ConnectionResponse{
+			User:       ToUserSummaryResponse(connection.User),
+			FollowedAt: connection.FollowedAt,
+		})
+	}
+
+	return ConnectionsResponse{
+		Users:      connectionResponses,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
+
+// ConnectionCSVHeader is the header row of a connections CSV export.
+var ConnectionCSVHeader = []string{"id", "username", "followed_at"}
+
+func ToConnectionCSVRecord(connection domain.Connection) []string {
+	return []string{
+		strconv.FormatInt(connection.User.ID, 10),
+		connection.User.Username,
+		connection.FollowedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type FollowRequestResponse struct {
+	ID          int64     `json:"id"`
+	RequesterID int64     `json:"requester_id"`
+	TargetID    int64     `json:"target_id"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToFollowRequestResponse(followRequest domain.FollowRequest) FollowRequestResponse {
+	return FollowRequestResponse{
+		ID:          followRequest.ID,
+		RequesterID: followRequest.RequesterID,
+		TargetID:    followRequest.TargetID,
+		Status:      "pending",
+		CreatedAt:   followRequest.CreatedAt,
+	}
+}
+
+type PendingFollowRequestResponse struct {
+	ID          int64               `json:"id"`
+	Requester   UserSummaryResponse `json:"requester"`
+	RequestedAt time.Time           `json:"requested_at"`
+}
+
+type FollowRequestsResponse struct {
+	Requests   []PendingFollowRequestResponse `json:"requests"`
+	Limit      int                            `json:"limit"`
+	NextCursor int64                          `json:"next_cursor,omitempty"`
+}
+
+func ToFollowRequestsResponse(followRequests []domain.FollowRequest, limit int, nextCursor int64) FollowRequestsResponse {
+	requestResponses := make([]PendingFollowRequestResponse, 0, len(followRequests))
+	for _, followRequest := range followRequests {
+		requestResponses = append(requestResponses, PendingFollowRequestResponse{
+			ID:          followRequest.ID,
+			Requester:   ToUserSummaryResponse(followRequest.Requester),
+			RequestedAt: followRequest.CreatedAt,
+		})
+	}
+
+	return FollowRequestsResponse{
+		Requests:   requestResponses,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
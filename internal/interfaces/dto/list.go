@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+type CreateListRequest struct {
+	OwnerID     int64  `json:"owner_id" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+type UpdateListRequest struct {
+	OwnerID     int64  `json:"owner_id" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+type ListOwnerRequest struct {
+	OwnerID int64 `json:"owner_id" binding:"required"`
+}
+
+type ListMemberRequest struct {
+	OwnerID int64 `json:"owner_id" binding:"required"`
+	UserID  int64 `json:"user_id" binding:"required"`
+}
+
+type ListSubscriberRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
+type ListTimelineRequest struct {
+	Limit    int   `form:"limit"`
+	Offset   int   `form:"offset"`
+	ViewerID int64 `form:"viewer_id"`
+}
+
+type ListResponse struct {
+	ID               int64     `json:"id"`
+	OwnerID          int64     `json:"owner_id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Private          bool      `json:"private"`
+	MembersCount     int       `json:"members_count"`
+	SubscribersCount int       `json:"subscribers_count"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func ToListResponse(list domain.List) ListResponse {
+	return ListResponse{
+		ID:               list.ID,
+		OwnerID:          list.OwnerID,
+		Name:             list.Name,
+		Description:      list.Description,
+		Private:          list.Private,
+		MembersCount:     list.MembersCount,
+		SubscribersCount: list.SubscribersCount,
+		CreatedAt:        list.CreatedAt,
+		UpdatedAt:        list.UpdatedAt,
+	}
+}
+
+func ToListResponses(lists []domain.List) []ListResponse {
+	listResponses := make([]ListResponse, len(lists))
+	for i, list := range lists {
+		listResponses[i] = ToListResponse(list)
+	}
+	return listResponses
+}
+
+func ToListDomain(request CreateListRequest) domain.List {
+	return domain.List{
+		OwnerID:     request.OwnerID,
+		Name:        request.Name,
+		Description: request.Description,
+		Private:     request.Private,
+	}
+}
+
+func ToUpdateListDomain(request UpdateListRequest) domain.List {
+	return domain.List{
+		OwnerID:     request.OwnerID,
+		Name:        request.Name,
+		Description: request.Description,
+		Private:     request.Private,
+	}
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"twitter-demo/internal/domain"
+)
+
+type RelationshipsRequest struct {
+	Source  int64  `form:"source" binding:"required"`
+	Targets string `form:"targets" binding:"required"` // Comma separated user IDs
+}
+
+// TargetIDs parses the comma separated targets of the request.
+func (r RelationshipsRequest) TargetIDs() ([]int64, error) {
+
+	var targetIDs []int64
+	for _, idString := range strings.Split(r.Targets, ",") {
+		idString = strings.TrimSpace(idString)
+		if idString == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(idString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target id %q", idString)
+		}
+		targetIDs = append(targetIDs, id)
+	}
+
+	return targetIDs, nil
+}
+
+type RelationshipResponse struct {
+	TargetID              int64 `json:"target_id"`
+	Following             bool  `json:"following"`
+	FollowedBy            bool  `json:"followed_by"`
+	Mutual                bool  `json:"mutual"`
+	Blocking              bool  `json:"blocking"`
+	BlockedBy             bool  `json:"blocked_by"`
+	Muting                bool  `json:"muting"`
+	FollowRequestSent     bool  `json:"follow_request_sent"`
+	FollowRequestReceived bool  `json:"follow_request_received"`
+}
+
+type RelationshipsResponse struct {
+	SourceID      int64                  `json:"source_id"`
+	Relationships []RelationshipResponse `json:"relationships"`
+}
+
+func ToRelationshipsResponse(sourceID int64, relationships []domain.Relationship) RelationshipsResponse {
+	relationshipResponses := make([]RelationshipResponse, 0, len(relationships))
+	for _, relationship := range relationships {
+		relationshipResponses = append(relationshipResponses, RelationshipResponse{
+			TargetID:              relationship.TargetID,
+			Following:             relationship.Following,
+			FollowedBy:            relationship.FollowedBy,
+			Mutual:                relationship.Following && relationship.FollowedBy,
+			Blocking:              relationship.Blocking,
+			BlockedBy:             relationship.BlockedBy,
+			Muting:                relationship.Muting,
+			FollowRequestSent:     relationship.FollowRequestSent,
+			FollowRequestReceived: relationship.FollowRequestReceived,
+		})
+	}
+
+	return RelationshipsResponse{
+		SourceID:      sourceID,
+		Relationships: relationshipResponses,
+	}
+}
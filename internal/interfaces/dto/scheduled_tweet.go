@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+type CreateScheduledTweetRequest struct {
+	UserID    int64     `json:"user_id" binding:"required"`
+	Content   string    `json:"content" binding:"required"`
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+type CancelScheduledTweetRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
+type ScheduledTweetsRequest struct {
+	Status string `form:"status"`
+}
+
+type ScheduledTweetResponse struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Content   string    `json:"content"`
+	PublishAt time.Time `json:"publish_at"`
+	Status    string    `json:"status"`
+	TweetID   int64     `json:"tweet_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToScheduledTweetResponse(scheduledTweet domain.ScheduledTweet) ScheduledTweetResponse {
+	return ScheduledTweetResponse{
+		ID:        scheduledTweet.ID,
+		UserID:    scheduledTweet.UserID,
+		Content:   scheduledTweet.Content,
+		PublishAt: scheduledTweet.PublishAt,
+		Status:    scheduledTweet.Status,
+		TweetID:   scheduledTweet.TweetID,
+		Error:     scheduledTweet.Error,
+		CreatedAt: scheduledTweet.CreatedAt,
+		UpdatedAt: scheduledTweet.UpdatedAt,
+	}
+}
+
+func ToScheduledTweetDomain(request CreateScheduledTweetRequest) domain.ScheduledTweet {
+	return domain.ScheduledTweet{
+		UserID:    request.UserID,
+		Content:   request.Content,
+		PublishAt: request.PublishAt,
+	}
+}
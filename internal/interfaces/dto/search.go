@@ -0,0 +1,28 @@
+package dto
+
+import "twitter-demo/internal/domain"
+
+type SearchTweetsRequest struct {
+	Query    string `form:"q" binding:"required"`
+	Sort     string `form:"sort" binding:"omitempty,oneof=relevance recent"` // domain.SearchSortRelevance (default) or domain.SearchSortRecent
+	SinceID  int64  `form:"since_id"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit"`
+	ViewerID int64  `form:"viewer_id"`
+}
+
+type SearchTweetsResponse struct {
+	Tweets     []TweetResponse `json:"tweets"`
+	Limit      int             `json:"limit"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ToSearchTweetsResponse converts a page of search results, embedding the author of each
+// tweet found in authors (by user ID).
+func ToSearchTweetsResponse(tweets []domain.Tweet, authors map[int64]domain.User, limit int, nextCursor string) SearchTweetsResponse {
+	return SearchTweetsResponse{
+		Tweets:     ToTimelineResponse(tweets, authors, limit, 0).Tweets,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+type SearchIndexRequest struct {
+	Query    string `form:"q" binding:"required"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+	ViewerID int64  `form:"viewer_id"`
+}
+
+type SearchHitResponse struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Score     float64   `json:"score"`
+}
+
+type SearchIndexResponse struct {
+	Tweets []SearchHitResponse `json:"tweets"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+	Total  int                 `json:"total"` // Tweets matching the query, across pages
+}
+
+func ToSearchIndexResponse(hits []domain.IndexHit, total, limit, offset int) SearchIndexResponse {
+	tweets := make([]SearchHitResponse, len(hits))
+	for i, hit := range hits {
+		tweets[i] = SearchHitResponse{
+			ID:        hit.Tweet.ID,
+			UserID:    hit.Tweet.UserID,
+			Content:   hit.Tweet.Content,
+			CreatedAt: hit.Tweet.CreatedAt,
+			UpdatedAt: hit.Tweet.UpdatedAt,
+			Score:     hit.Score,
+		}
+	}
+
+	return SearchIndexResponse{
+		Tweets: tweets,
+		Limit:  limit,
+		Offset: offset,
+		Total:  total,
+	}
+}
+
+type IndexStatsResponse struct {
+	Tweets   int `json:"tweets"`
+	Terms    int `json:"terms"`
+	Segments int `json:"segments"`
+	Buffered int `json:"buffered"`
+}
+
+func ToIndexStatsResponse(stats domain.IndexStats) IndexStatsResponse {
+	return IndexStatsResponse{
+		Tweets:   stats.Tweets,
+		Terms:    stats.Terms,
+		Segments: stats.Segments,
+		Buffered: stats.Buffered,
+	}
+}
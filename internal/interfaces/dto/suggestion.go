@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"twitter-demo/internal/domain"
+)
+
+type SuggestionsRequest struct {
+	Limit int `form:"limit"`
+}
+
+type SuggestionResponse struct {
+	User        UserSummaryResponse   `json:"user"`
+	MutualCount int                   `json:"mutual_count"`
+	FollowedBy  []UserSummaryResponse `json:"followed_by"`
+	Explanation string                `json:"explanation"`
+}
+
+func ToSuggestionResponse(suggestion domain.Suggestion) SuggestionResponse {
+	followedBy := make([]UserSummaryResponse, 0, len(suggestion.FollowedBy))
+	for _, user := range suggestion.FollowedBy {
+		followedBy = append(followedBy, ToUserSummaryResponse(user))
+	}
+
+	return SuggestionResponse{
+		User:        ToUserSummaryResponse(suggestion.User),
+		MutualCount: suggestion.MutualCount,
+		FollowedBy:  followedBy,
+		Explanation: ExplainSuggestion(suggestion),
+	}
+}
+
+type SuggestionsResponse struct {
+	Suggestions []SuggestionResponse `json:"suggestions"`
+}
+
+func ToSuggestionsResponse(suggestions []domain.Suggestion) SuggestionsResponse {
+	suggestionResponses := make([]SuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		suggestionResponses = append(suggestionResponses, ToSuggestionResponse(suggestion))
+	}
+
+	return SuggestionsResponse{
+		Suggestions: suggestionResponses,
+	}
+}
+
+// ExplainSuggestion builds the "followed by people you follow" sentence of a suggestion,
+// e.g. "Followed by alice, bob and 3 others you follow".
+func ExplainSuggestion(suggestion domain.Suggestion) string {
+
+	usernames := make([]string, len(suggestion.FollowedBy))
+	for i, user := range suggestion.FollowedBy {
+		usernames[i] = user.Username
+	}
+
+	others := suggestion.MutualCount - len(usernames)
+
+	switch {
+	case len(usernames) == 0:
+		return fmt.Sprintf("Followed by %d people you follow", suggestion.MutualCount)
+	case others > 1:
+		return fmt.Sprintf("Followed by %s and %d others you follow", strings.Join(usernames, ", "), others)
+	case others == 1:
+		return fmt.Sprintf("Followed by %s and 1 other you follow", strings.Join(usernames, ", "))
+	case len(usernames) == 1:
+		return fmt.Sprintf("Followed by %s", usernames[0])
+	default:
+		return fmt.Sprintf("Followed by %s and %s", strings.Join(usernames[:len(usernames)-1], ", "), usernames[len(usernames)-1])
+	}
+}
@@ -6,36 +6,99 @@ import (
 )
 
 type CreateTweetRequest struct {
-	UserID  int64  `json:"user_id" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	UserID         int64  `json:"user_id" binding:"required"`
+	Content        string `json:"content"`
+	ReplyToTweetID int64  `json:"reply_to_tweet_id"`
+	RetweetOfID    int64  `json:"retweet_of_id"`
+}
+
+// ViewerRequest identifies who is reading, so the tweets of protected accounts are
+// only returned to their approved followers. It is empty for anonymous readers.
+type ViewerRequest struct {
+	ViewerID int64 `form:"viewer_id"`
+}
+
+type PinTweetRequest struct {
+	TweetID int64 `json:"tweet_id" binding:"required"`
 }
 
 type UpdateTweetRequest struct {
 	Content string `json:"content" binding:"required"`
 }
 
+type ValidateTweetRequest struct {
+	Content string `json:"content"`
+}
+
+type ValidateTweetResponse struct {
+	WeightedLength int    `json:"weighted_length"`
+	MaxLength      int    `json:"max_length"`
+	Remaining      int    `json:"remaining"`
+	Permillage     int    `json:"permillage"`
+	Valid          bool   `json:"valid"`
+	Error          string `json:"error,omitempty"`
+}
+
 type TweetResponse struct {
+	ID             int64                `json:"id"`
+	UserID         int64                `json:"user_id"`
+	Content        string               `json:"content"`
+	ReplyToTweetID int64                `json:"reply_to_tweet_id,omitempty"`
+	RetweetOfID    int64                `json:"retweet_of_id,omitempty"`
+	Edited         bool                 `json:"edited"`
+	EditCount      int                  `json:"edit_count"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	Author         *UserSummaryResponse `json:"author,omitempty"`
+}
+
+func ToTweetResponse(tweet domain.Tweet) TweetResponse {
+	return TweetResponse{
+		ID:             tweet.ID,
+		UserID:         tweet.UserID,
+		Content:        tweet.Content,
+		ReplyToTweetID: tweet.ReplyToTweetID,
+		RetweetOfID:    tweet.RetweetOfID,
+		Edited:         tweet.Edited(),
+		EditCount:      tweet.EditCount,
+		CreatedAt:      tweet.CreatedAt,
+		UpdatedAt:      tweet.UpdatedAt,
+	}
+}
+
+type TweetRevisionResponse struct {
 	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func ToTweetResponse(tweet domain.Tweet) TweetResponse {
-	return TweetResponse{
-		ID:        tweet.ID,
-		UserID:    tweet.UserID,
-		Content:   tweet.Content,
-		CreatedAt: tweet.CreatedAt,
-		UpdatedAt: tweet.UpdatedAt,
+type TweetHistoryResponse struct {
+	Tweet     TweetResponse           `json:"tweet"`
+	Revisions []TweetRevisionResponse `json:"revisions"`
+}
+
+func ToTweetHistoryResponse(tweet domain.Tweet, revisions []domain.TweetRevision) TweetHistoryResponse {
+	revisionResponses := make([]TweetRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, TweetRevisionResponse{
+			ID:        revision.ID,
+			Content:   revision.Content,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	return TweetHistoryResponse{
+		Tweet:     ToTweetResponse(tweet),
+		Revisions: revisionResponses,
 	}
 }
 
 func ToTweetDomain(request CreateTweetRequest) domain.Tweet {
 	return domain.Tweet{
-		UserID:  request.UserID,
-		Content: request.Content,
+		UserID:         request.UserID,
+		Content:        request.Content,
+		ReplyToTweetID: request.ReplyToTweetID,
+		RetweetOfID:    request.RetweetOfID,
 	}
 }
 
@@ -45,22 +108,54 @@ func ToUpdateTweetDomain(request UpdateTweetRequest) domain.Tweet {
 	}
 }
 
+func ToValidateTweetResponse(length domain.TweetLength, err error) ValidateTweetResponse {
+	response := ValidateTweetResponse{
+		WeightedLength: length.WeightedLength,
+		MaxLength:      length.MaxLength,
+		Remaining:      length.Remaining(),
+		Permillage:     length.Permillage,
+		Valid:          err == nil,
+	}
+
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	return response
+}
+
+const (
+	TimelineModeLatest = "latest" // Newest first (default)
+	TimelineModeRanked = "ranked" // Scored by relevance, paginated with a ranking snapshot
+)
+
 type TimelineRequest struct {
-	Limit  int `form:"limit"`
-	Offset int `form:"offset"`
+	Limit       int    `form:"limit"`
+	Offset      int    `form:"offset"`
+	ExcludeSelf bool   `form:"exclude_self"`                                 // Leave out the user's own tweets
+	Mode        string `form:"mode" binding:"omitempty,oneof=latest ranked"` // TimelineModeLatest or TimelineModeRanked
+	Snapshot    string `form:"snapshot"`                                     // Ranking returned by the first page of a ranked timeline
 }
 
 type TimelineResponse struct {
-	Tweets []TweetResponse `json:"tweets"`
-	Limit  int             `json:"limit"`
-	Offset int             `json:"offset"`
-	Total  int             `json:"total"`
+	Tweets   []TweetResponse `json:"tweets"`
+	Limit    int             `json:"limit"`
+	Offset   int             `json:"offset"`
+	Total    int             `json:"total"`
+	Snapshot string          `json:"snapshot,omitempty"` // Ranked timelines only, to pass when asking for the next pages
 }
 
-func ToTimelineResponse(tweets []domain.Tweet, limit, offset int) TimelineResponse {
+// ToTimelineResponse converts a page of a timeline, embedding the author of each tweet
+// found in authors (by user ID).
+func ToTimelineResponse(tweets []domain.Tweet, authors map[int64]domain.User, limit, offset int) TimelineResponse {
 	tweetResponses := make([]TweetResponse, 0, len(tweets))
 	for _, tweet := range tweets {
-		tweetResponses = append(tweetResponses, ToTweetResponse(tweet))
+		response := ToTweetResponse(tweet)
+		if author, ok := authors[tweet.UserID]; ok {
+			summary := ToUserSummaryResponse(author)
+			response.Author = &summary
+		}
+		tweetResponses = append(tweetResponses, response)
 	}
 
 	return TimelineResponse{
@@ -70,3 +165,53 @@ func ToTimelineResponse(tweets []domain.Tweet, limit, offset int) TimelineRespon
 		Total:  len(tweetResponses),
 	}
 }
+
+type UserTweetsRequest struct {
+	Limit           int   `form:"limit"`
+	Cursor          int64 `form:"cursor"`
+	IncludeReplies  *bool `form:"include_replies"`
+	IncludeRetweets *bool `form:"include_retweets"`
+	ViewerID        int64 `form:"viewer_id"`
+}
+
+type UserTweetsResponse struct {
+	Tweets        []TweetResponse `json:"tweets"`
+	PinnedTweetID int64           `json:"pinned_tweet_id,omitempty"`
+	Limit         int             `json:"limit"`
+	NextCursor    int64           `json:"next_cursor,omitempty"`
+}
+
+func ToUserTweetsResponse(tweets []domain.Tweet, pinnedTweetID int64, limit int, nextCursor int64) UserTweetsResponse {
+	tweetResponses := make([]TweetResponse, 0, len(tweets))
+	for _, tweet := range tweets {
+		tweetResponses = append(tweetResponses, ToTweetResponse(tweet))
+	}
+
+	return UserTweetsResponse{
+		Tweets:        tweetResponses,
+		PinnedTweetID: pinnedTweetID,
+		Limit:         limit,
+		NextCursor:    nextCursor,
+	}
+}
+
+type MentionsRequest struct {
+	Limit  int   `form:"limit"`
+	Cursor int64 `form:"cursor"`
+}
+
+type MentionsResponse struct {
+	Tweets     []TweetResponse `json:"tweets"`
+	Limit      int             `json:"limit"`
+	NextCursor int64           `json:"next_cursor,omitempty"`
+}
+
+// ToMentionsResponse converts a page of mentions, embedding the author of each tweet
+// found in authors (by user ID).
+func ToMentionsResponse(tweets []domain.Tweet, authors map[int64]domain.User, limit int, nextCursor int64) MentionsResponse {
+	return MentionsResponse{
+		Tweets:     ToTimelineResponse(tweets, authors, limit, 0).Tweets,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
@@ -6,47 +6,76 @@ import (
 )
 
 type CreateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Protected bool   `json:"protected"`
 }
 
 type UpdateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Protected bool   `json:"protected"`
 }
 
 type UserResponse struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int64     `json:"id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	FollowersCount int       `json:"followers_count"`
+	FollowingCount int       `json:"following_count"`
+	Protected      bool      `json:"protected"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 func ToUserResponse(user domain.User) UserResponse {
 	return UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:             user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		Protected:      user.Protected,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+// UserSummaryResponse is the public part of a user, used when listing many users.
+type UserSummaryResponse struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	FollowersCount int    `json:"followers_count"`
+	FollowingCount int    `json:"following_count"`
+	Protected      bool   `json:"protected"`
+}
+
+func ToUserSummaryResponse(user domain.User) UserSummaryResponse {
+	return UserSummaryResponse{
+		ID:             user.ID,
+		Username:       user.Username,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		Protected:      user.Protected,
 	}
 }
 
 func ToUserDomain(request CreateUserRequest) domain.User {
 	return domain.User{
-		Username: request.Username,
-		Email:    request.Email,
-		Password: request.Password,
+		Username:  request.Username,
+		Email:     request.Email,
+		Password:  request.Password,
+		Protected: request.Protected,
 	}
 }
 
 func ToUpdateUserDomain(request UpdateUserRequest) domain.User {
 	return domain.User{
-		Username: request.Username,
-		Email:    request.Email,
-		Password: request.Password,
+		Username:  request.Username,
+		Email:     request.Email,
+		Password:  request.Password,
+		Protected: request.Protected,
 	}
 }
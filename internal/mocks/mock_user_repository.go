@@ -101,6 +101,36 @@ func (mr *MockUserRepositoryMockRecorder) SelectByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByID", reflect.TypeOf((*MockUserRepository)(nil).SelectByID), ctx, id)
 }
 
+// SelectByIDs mocks base method.
+func (m *MockUserRepository) SelectByIDs(ctx context.Context, ids []int64) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectByIDs", ctx, ids)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectByIDs indicates an expected call of SelectByIDs.
+func (mr *MockUserRepositoryMockRecorder) SelectByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectByIDs", reflect.TypeOf((*MockUserRepository)(nil).SelectByIDs), ctx, ids)
+}
+
+// SelectIDsAfter mocks base method.
+func (m *MockUserRepository) SelectIDsAfter(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectIDsAfter", ctx, afterID, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectIDsAfter indicates an expected call of SelectIDsAfter.
+func (mr *MockUserRepositoryMockRecorder) SelectIDsAfter(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectIDsAfter", reflect.TypeOf((*MockUserRepository)(nil).SelectIDsAfter), ctx, afterID, limit)
+}
+
 // SelectByUsername mocks base method.
 func (m *MockUserRepository) SelectByUsername(ctx context.Context, username string) (domain.User, error) {
 	m.ctrl.T.Helper()
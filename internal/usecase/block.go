@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/pkg"
+)
+
+type BlockUsecase interface {
+	BlockUser(ctx context.Context, blockerID, blockedID int64) (domain.Block, error)
+	UnblockUser(ctx context.Context, blockerID, blockedID int64) error
+	GetBlocks(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Block, int64, error)
+}
+
+type Block struct {
+	blockRepository repository.BlockRepository
+	userRepository  repository.UserRepository
+	producer        pkg.Producer
+}
+
+func NewBlock(blockRepository repository.BlockRepository, userRepository repository.UserRepository, producer pkg.Producer) Block {
+	return Block{
+		blockRepository: blockRepository,
+		userRepository:  userRepository,
+		producer:        producer,
+	}
+}
+
+// BlockUser blocks a user. Follow relationships in both directions are removed and
+// a user.blocked event is published so both timelines are rebuilt without each other's tweets.
+func (b Block) BlockUser(ctx context.Context, blockerID, blockedID int64) (domain.Block, error) {
+
+	// Validate that blocker ID and blocked ID are different
+	if blockerID == blockedID {
+		return domain.Block{}, fmt.Errorf("cannot block yourself")
+	}
+
+	if err := checkUsersExist(ctx, b.userRepository, blockerID, blockedID); err != nil {
+		return domain.Block{}, err
+	}
+
+	// Check if block already exists
+	existingBlock, err := b.blockRepository.SelectByBlockerAndBlocked(ctx, blockerID, blockedID)
+	if err != nil {
+		return domain.Block{}, err
+	}
+	if existingBlock.ID != 0 {
+		return domain.Block{}, fmt.Errorf("already blocking this user")
+	}
+
+	newBlock, err := b.blockRepository.Insert(ctx, domain.Block{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		return domain.Block{}, err
+	}
+
+	go b.publishBlockEvent(dto.UserBlockedEvent, blockerID, blockedID)
+
+	return newBlock, nil
+}
+
+func (b Block) UnblockUser(ctx context.Context, blockerID, blockedID int64) error {
+
+	// Check if block exists
+	existingBlock, err := b.blockRepository.SelectByBlockerAndBlocked(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if existingBlock.ID == 0 {
+		return fmt.Errorf("not blocking this user")
+	}
+
+	if err := b.blockRepository.Delete(ctx, blockerID, blockedID); err != nil {
+		return err
+	}
+
+	go b.publishBlockEvent(dto.UserUnblockedEvent, blockerID, blockedID)
+
+	return nil
+}
+
+// GetBlocks returns the blocks made by a user, most recent first, and the cursor
+// of the next page (0 when there are no more).
+func (b Block) GetBlocks(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Block, int64, error) {
+
+	limit, cursor = normalizePage(limit, cursor)
+
+	blocks, err := b.blockRepository.SelectBlocksPage(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor int64
+	if len(blocks) == limit {
+		nextCursor = blocks[len(blocks)-1].ID
+	}
+
+	return blocks, nextCursor, nil
+}
+
+func (b Block) publishBlockEvent(eventType dto.EventType, blockerID, blockedID int64) {
+	event := dto.NewEvent(eventType, dto.UserRelationshipEventData{
+		UserID:       blockerID,
+		TargetUserID: blockedID,
+	})
+	publishEvent(b.producer, config.TopicFollows, fmt.Sprintf(config.KeyFormatUser, blockerID), event)
+}
+
+// checkUsersExist returns an error when the source or the target user of a relationship does not exist.
+func checkUsersExist(ctx context.Context, userRepository repository.UserRepository, userID, targetUserID int64) error {
+
+	user, err := userRepository.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	targetUser, err := userRepository.SelectByID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+	if targetUser.ID == 0 {
+		return fmt.Errorf("target user not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// stubBlockRepository holds at most one block, and answers the blocks of a user
+// counting the queries when queries is set.
+type stubBlockRepository struct {
+	repository.BlockRepository
+	existing   domain.Block
+	inserted   *[]domain.Block
+	blockedIDs []int64
+	queries    *int
+}
+
+func (s stubBlockRepository) SelectByBlockerAndBlocked(ctx context.Context, blockerID, blockedID int64) (domain.Block, error) {
+	if s.existing.BlockerID != blockerID || s.existing.BlockedID != blockedID {
+		return domain.Block{}, nil
+	}
+	return s.existing, nil
+}
+
+func (s stubBlockRepository) ExistsBetween(ctx context.Context, userID, otherUserID int64) (bool, error) {
+	return s.existing.ID != 0 &&
+		(s.existing.BlockerID == userID && s.existing.BlockedID == otherUserID ||
+			s.existing.BlockerID == otherUserID && s.existing.BlockedID == userID), nil
+}
+
+func (s stubBlockRepository) Insert(ctx context.Context, block domain.Block) (domain.Block, error) {
+	*s.inserted = append(*s.inserted, block)
+	block.ID = int64(len(*s.inserted))
+	return block, nil
+}
+
+func (s stubBlockRepository) SelectBlockedOrBlockingIDs(ctx context.Context, userID int64) ([]int64, error) {
+	if s.queries != nil {
+		*s.queries++
+	}
+	return s.blockedIDs, nil
+}
+
+// expectUsersExist lets the user repository find every user in userIDs.
+func expectUsersExist(userRepository *mocks.MockUserRepository, userIDs ...int64) {
+	for _, userID := range userIDs {
+		userRepository.EXPECT().SelectByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil).AnyTimes()
+	}
+}
+
+func TestBlock_BlockUser_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	expectUsersExist(userRepository, 1, 2)
+
+	var inserted []domain.Block
+	producer := newStubProducer()
+	blockUsecase := NewBlock(stubBlockRepository{inserted: &inserted}, userRepository, producer)
+
+	// Act
+	block, err := blockUsecase.BlockUser(context.Background(), 1, 2)
+
+	// Assert: the block is stored and announced so both timelines are rebuilt
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), block.ID)
+	assert.Equal(t, []domain.Block{{BlockerID: 1, BlockedID: 2}}, inserted)
+
+	published := <-producer.published
+	assert.Equal(t, dto.UserBlockedEvent, published.Type)
+	assert.Equal(t, dto.UserRelationshipEventData{UserID: 1, TargetUserID: 2}, published.Data)
+}
+
+func TestBlock_BlockUser_Yourself(t *testing.T) {
+	// Arrange
+	var inserted []domain.Block
+	blockUsecase := NewBlock(stubBlockRepository{inserted: &inserted}, nil, nil)
+
+	// Act
+	block, err := blockUsecase.BlockUser(context.Background(), 1, 1)
+
+	// Assert
+	assert.EqualError(t, err, "cannot block yourself")
+	assert.Equal(t, int64(0), block.ID)
+	assert.Empty(t, inserted)
+}
+
+func TestBlock_BlockUser_AlreadyBlocking(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	expectUsersExist(userRepository, 1, 2)
+
+	var inserted []domain.Block
+	blockRepository := stubBlockRepository{existing: domain.Block{ID: 5, BlockerID: 1, BlockedID: 2}, inserted: &inserted}
+	blockUsecase := NewBlock(blockRepository, userRepository, nil)
+
+	// Act
+	block, err := blockUsecase.BlockUser(context.Background(), 1, 2)
+
+	// Assert
+	assert.EqualError(t, err, "already blocking this user")
+	assert.Equal(t, int64(0), block.ID)
+	assert.Empty(t, inserted)
+}
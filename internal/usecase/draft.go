@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+type DraftUsecase interface {
+	GetDrafts(ctx context.Context, userID int64) ([]domain.Draft, error)
+	CreateDraft(ctx context.Context, draft domain.Draft) (domain.Draft, error)
+	UpdateDraft(ctx context.Context, id int64, draft domain.Draft) (domain.Draft, error)
+	DeleteDraft(ctx context.Context, id, userID int64) error
+	PublishDraft(ctx context.Context, id, userID int64) (domain.Tweet, error)
+}
+
+type Draft struct {
+	draftRepository repository.DraftRepository
+	userRepository  repository.UserRepository
+	tweetUsecase    TweetUsecase
+}
+
+func NewDraft(draftRepository repository.DraftRepository, userRepository repository.UserRepository, tweetUsecase TweetUsecase) Draft {
+	return Draft{
+		draftRepository: draftRepository,
+		userRepository:  userRepository,
+		tweetUsecase:    tweetUsecase,
+	}
+}
+
+func (d Draft) GetDrafts(ctx context.Context, userID int64) ([]domain.Draft, error) {
+	return d.draftRepository.SelectByUserID(ctx, userID)
+}
+
+func (d Draft) CreateDraft(ctx context.Context, draft domain.Draft) (domain.Draft, error) {
+
+	// Drafts can be over the length limit, they are only validated when published
+	if strings.TrimSpace(draft.Content) == "" {
+		return domain.Draft{}, fmt.Errorf("content cannot be empty")
+	}
+
+	// Check if user exists
+	existingUser, err := d.userRepository.SelectByID(ctx, draft.UserID)
+	if err != nil {
+		return domain.Draft{}, err
+	}
+	if existingUser.ID == 0 {
+		return domain.Draft{}, fmt.Errorf("user not found")
+	}
+
+	return d.draftRepository.Insert(ctx, draft)
+}
+
+func (d Draft) UpdateDraft(ctx context.Context, id int64, draft domain.Draft) (domain.Draft, error) {
+
+	if strings.TrimSpace(draft.Content) == "" {
+		return domain.Draft{}, fmt.Errorf("content cannot be empty")
+	}
+
+	existingDraft, err := d.getUserDraft(ctx, id, draft.UserID)
+	if err != nil {
+		return domain.Draft{}, err
+	}
+
+	existingDraft.Content = draft.Content
+
+	return d.draftRepository.UpdateByID(ctx, id, existingDraft)
+}
+
+func (d Draft) DeleteDraft(ctx context.Context, id, userID int64) error {
+
+	if _, err := d.getUserDraft(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return d.draftRepository.Delete(ctx, id)
+}
+
+// PublishDraft turns a draft into a tweet through TweetUsecase.CreateTweet.
+// The content is validated first so the draft is kept untouched when it cannot be published.
+// The draft is then claimed by deleting it, so concurrent requests publish it only once.
+func (d Draft) PublishDraft(ctx context.Context, id, userID int64) (domain.Tweet, error) {
+
+	draft, err := d.getUserDraft(ctx, id, userID)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if _, err := d.tweetUsecase.ValidateContent(draft.Content); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	claimedDraft, err := d.draftRepository.Claim(ctx, id, userID)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	// Another request published or deleted it in the meantime
+	if claimedDraft.ID == 0 {
+		return domain.Tweet{}, fmt.Errorf("draft not found")
+	}
+
+	tweet, err := d.tweetUsecase.CreateTweet(ctx, domain.Tweet{
+		UserID:  claimedDraft.UserID,
+		Content: claimedDraft.Content,
+	})
+	if err != nil {
+		// Give the draft back so the content is not lost, under a new ID
+		if _, err := d.draftRepository.Insert(ctx, claimedDraft); err != nil {
+			log.Printf("Failed to restore draft %d: %v", id, err)
+		}
+		return domain.Tweet{}, err
+	}
+
+	return tweet, nil
+}
+
+// getUserDraft returns a draft only if it belongs to the given user.
+func (d Draft) getUserDraft(ctx context.Context, id, userID int64) (domain.Draft, error) {
+
+	draft, err := d.draftRepository.SelectByID(ctx, id)
+	if err != nil {
+		return domain.Draft{}, err
+	}
+
+	if draft.ID == 0 || draft.UserID != userID {
+		return domain.Draft{}, fmt.Errorf("draft not found")
+	}
+
+	return draft, nil
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/pkg"
+)
+
+// publishEvent publishes an event to Kafka and logs the outcome.
+// It is meant to run in its own goroutine so it doesn't block the response.
+func publishEvent(producer pkg.Producer, topic, key string, event dto.Event) {
+	if err := producer.Publish(context.Background(), topic, key, event); err != nil {
+		log.Printf("Failed to publish %s event: %v", event.Type, err)
+	} else {
+		log.Printf("Published %s event with key %s", event.Type, key)
+	}
+}
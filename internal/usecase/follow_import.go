@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+type FollowImportUsecase interface {
+	CreateImport(ctx context.Context, userID int64, targets []string) (domain.FollowImport, error)
+	GetImport(ctx context.Context, id int64) (domain.FollowImport, error)
+	ProcessPendingImports(ctx context.Context) (int, error)
+}
+
+type FollowImport struct {
+	followImportRepository repository.FollowImportRepository
+	userRepository         repository.UserRepository
+	followerUsecase        FollowerUsecase
+	timelineUsecase        TimelineUsecase
+	followImportConfig     config.FollowImportConfig
+}
+
+func NewFollowImport(followImportRepository repository.FollowImportRepository, userRepository repository.UserRepository, followerUsecase FollowerUsecase, timelineUsecase TimelineUsecase, followImportConfig config.FollowImportConfig) FollowImport {
+	return FollowImport{
+		followImportRepository: followImportRepository,
+		userRepository:         userRepository,
+		followerUsecase:        followerUsecase,
+		timelineUsecase:        timelineUsecase,
+		followImportConfig:     followImportConfig,
+	}
+}
+
+// CreateImport queues the accounts userID wants to follow, given as usernames or user IDs.
+// The worker follows them in the background; the returned import reports its progress.
+func (f FollowImport) CreateImport(ctx context.Context, userID int64, targets []string) (domain.FollowImport, error) {
+
+	targets = normalizeFollowTargets(targets)
+	if len(targets) == 0 {
+		return domain.FollowImport{}, fmt.Errorf("no accounts to follow")
+	}
+
+	if len(targets) > f.followImportConfig.MaxTargets {
+		return domain.FollowImport{}, fmt.Errorf("an import can follow at most %d accounts", f.followImportConfig.MaxTargets)
+	}
+
+	// Check if user exists
+	existingUser, err := f.userRepository.SelectByID(ctx, userID)
+	if err != nil {
+		return domain.FollowImport{}, err
+	}
+	if existingUser.ID == 0 {
+		return domain.FollowImport{}, fmt.Errorf("user not found")
+	}
+
+	return f.followImportRepository.Insert(ctx, domain.FollowImport{
+		UserID:  userID,
+		Targets: targets,
+	})
+}
+
+func (f FollowImport) GetImport(ctx context.Context, id int64) (domain.FollowImport, error) {
+
+	followImport, err := f.followImportRepository.SelectByID(ctx, id)
+	if err != nil {
+		return domain.FollowImport{}, err
+	}
+	if followImport.ID == 0 {
+		return domain.FollowImport{}, fmt.Errorf("follow import not found")
+	}
+
+	return followImport, nil
+}
+
+// ProcessPendingImports claims the pending imports and follows their targets through
+// FollowerUsecase, so blocks and protected accounts are handled like a direct follow.
+// It returns the number of imports completed.
+func (f FollowImport) ProcessPendingImports(ctx context.Context) (int, error) {
+
+	followImports, err := f.followImportRepository.ClaimPending(ctx, f.followImportConfig.BatchSize, f.followImportConfig.LeaseTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim follow imports: %w", err)
+	}
+
+	completed := 0
+	for _, followImport := range followImports {
+		if err := f.processImport(ctx, followImport); err != nil {
+			// The import stays claimed and is resumed by a worker once its lease expires
+			log.Printf("Failed to process follow import %d: %v", followImport.ID, err)
+			continue
+		}
+		completed++
+	}
+
+	return completed, nil
+}
+
+// processImport follows the targets not processed yet, recording the outcome of each one,
+// then backfills the timeline of the importing user once for all the new follows.
+func (f FollowImport) processImport(ctx context.Context, followImport domain.FollowImport) error {
+
+	var followedIDs []int64
+	for _, target := range followImport.Targets[followImport.ProcessedCount:] {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		followedID, outcome, reason := f.followTarget(ctx, followImport.UserID, target)
+		if outcome == domain.FollowImportFollowed {
+			followedIDs = append(followedIDs, followedID)
+		}
+
+		if err := f.followImportRepository.RecordResult(ctx, followImport.ID, target, outcome, reason); err != nil {
+			return err
+		}
+	}
+
+	if err := f.followImportRepository.MarkCompleted(ctx, followImport.ID); err != nil {
+		return err
+	}
+
+	// A resumed import doesn't know what the previous worker followed: rebuild the timeline instead
+	if followImport.ProcessedCount > 0 {
+		return f.timelineUsecase.InvalidateTimeline(ctx, followImport.UserID)
+	}
+
+	return f.timelineUsecase.BackfillTimeline(ctx, followImport.UserID, followedIDs...)
+}
+
+// followTarget follows one target of an import and returns the followed user and the outcome,
+// with the reason when it failed.
+func (f FollowImport) followTarget(ctx context.Context, userID int64, target string) (int64, string, string) {
+
+	var followedUser domain.User
+	var err error
+	if id, parseErr := strconv.ParseInt(target, 10, 64); parseErr == nil {
+		followedUser, err = f.userRepository.SelectByID(ctx, id)
+	} else {
+		followedUser, err = f.userRepository.SelectByUsername(ctx, target)
+	}
+
+	if err != nil {
+		return 0, domain.FollowImportFailed, err.Error()
+	}
+	if followedUser.ID == 0 {
+		return 0, domain.FollowImportFailed, "user not found"
+	}
+
+	_, followRequest, err := f.followerUsecase.BulkFollowUser(ctx, userID, followedUser.ID)
+	switch {
+	case errors.Is(err, ErrAlreadyFollowing), errors.Is(err, ErrFollowRequestAlreadySent):
+		return followedUser.ID, domain.FollowImportSkipped, ""
+	case err != nil:
+		return followedUser.ID, domain.FollowImportFailed, err.Error()
+	case followRequest.ID != 0:
+		return followedUser.ID, domain.FollowImportRequested, ""
+	default:
+		return followedUser.ID, domain.FollowImportFollowed, ""
+	}
+}
+
+// normalizeFollowTargets trims the targets, strips the leading @ of usernames and
+// drops empty and repeated ones, keeping the original order.
+func normalizeFollowTargets(targets []string) []string {
+
+	seen := make(map[string]bool, len(targets))
+	normalized := make([]string, 0, len(targets))
+	for _, target := range targets {
+		target = strings.TrimPrefix(strings.TrimSpace(target), "@")
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		normalized = append(normalized, target)
+	}
+
+	return normalized
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeFollowTargets(t *testing.T) {
+	// Arrange
+	targets := []string{" alice ", "@bob", "42", "", "alice", "bob", "  ", "@42"}
+
+	// Act
+	normalized := normalizeFollowTargets(targets)
+
+	// Assert
+	assert.Equal(t, []string{"alice", "bob", "42"}, normalized)
+}
+
+// followImportResult is an outcome recorded for one target of an import.
+type followImportResult struct {
+	target, outcome, reason string
+}
+
+// stubFollowImportRepository records the outcomes and the completed imports.
+type stubFollowImportRepository struct {
+	repository.FollowImportRepository
+	results   *[]followImportResult
+	completed *[]int64
+}
+
+func (s stubFollowImportRepository) RecordResult(ctx context.Context, id int64, target, outcome, reason string) error {
+	*s.results = append(*s.results, followImportResult{target: target, outcome: outcome, reason: reason})
+	return nil
+}
+
+func (s stubFollowImportRepository) MarkCompleted(ctx context.Context, id int64) error {
+	*s.completed = append(*s.completed, id)
+	return nil
+}
+
+// newFollowImportTest builds an import use case where alice (2) can be followed, bob (3) is
+// protected, user 4 is already followed and nobody is called carol.
+func newFollowImportTest(t *testing.T) (FollowImport, stubFollowImportRepository, stubTimelineUsecase) {
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByUsername(gomock.Any(), "alice").Return(domain.User{ID: 2}, nil).AnyTimes()
+	userRepository.EXPECT().SelectByUsername(gomock.Any(), "bob").Return(domain.User{ID: 3, Protected: true}, nil).AnyTimes()
+	userRepository.EXPECT().SelectByUsername(gomock.Any(), "carol").Return(domain.User{}, nil).AnyTimes()
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(4)).Return(domain.User{ID: 4}, nil).AnyTimes()
+
+	followImportRepository := stubFollowImportRepository{results: new([]followImportResult), completed: new([]int64)}
+	followerUsecase := stubFollowerUsecase{following: []int64{4}, protected: []int64{3}}
+	timelineUsecase := stubTimelineUsecase{invalidatedTimelines: new([]int64), backfilled: map[int64][]int64{}}
+
+	return NewFollowImport(followImportRepository, userRepository, followerUsecase, timelineUsecase, config.FollowImportConfig{}), followImportRepository, timelineUsecase
+}
+
+func TestFollowImport_ProcessImport(t *testing.T) {
+	// Arrange
+	followImportUsecase, followImportRepository, timelineUsecase := newFollowImportTest(t)
+	followImport := domain.FollowImport{ID: 9, UserID: 1, Targets: []string{"alice", "bob", "4", "carol"}}
+
+	// Act
+	err := followImportUsecase.processImport(context.Background(), followImport)
+
+	// Assert: one result per target, and a single backfill with the accounts followed
+	assert.NoError(t, err)
+	assert.Equal(t, []followImportResult{
+		{target: "alice", outcome: domain.FollowImportFollowed},
+		{target: "bob", outcome: domain.FollowImportRequested},
+		{target: "4", outcome: domain.FollowImportSkipped},
+		{target: "carol", outcome: domain.FollowImportFailed, reason: "user not found"},
+	}, *followImportRepository.results)
+	assert.Equal(t, []int64{9}, *followImportRepository.completed)
+	assert.Equal(t, map[int64][]int64{1: {2}}, timelineUsecase.backfilled)
+	assert.Empty(t, *timelineUsecase.invalidatedTimelines)
+}
+
+func TestFollowImport_ProcessImport_Resumed(t *testing.T) {
+	// Arrange: a previous worker processed alice and bob before dying
+	followImportUsecase, followImportRepository, timelineUsecase := newFollowImportTest(t)
+	followImport := domain.FollowImport{ID: 9, UserID: 1, Targets: []string{"alice", "bob", "4", "carol"}, ProcessedCount: 2}
+
+	// Act
+	err := followImportUsecase.processImport(context.Background(), followImport)
+
+	// Assert: only the rest is processed, and the timeline is rebuilt rather than backfilled
+	assert.NoError(t, err)
+	assert.Equal(t, []followImportResult{
+		{target: "4", outcome: domain.FollowImportSkipped},
+		{target: "carol", outcome: domain.FollowImportFailed, reason: "user not found"},
+	}, *followImportRepository.results)
+	assert.Equal(t, []int64{9}, *followImportRepository.completed)
+	assert.Empty(t, timelineUsecase.backfilled)
+	assert.Equal(t, []int64{1}, *timelineUsecase.invalidatedTimelines)
+}
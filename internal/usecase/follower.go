@@ -2,60 +2,111 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"twitter-demo/internal/config"
 	"twitter-demo/internal/domain"
 	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/pkg"
 )
 
+// ErrAlreadyFollowing is returned when following a user that is already followed
+var ErrAlreadyFollowing = errors.New("already following this user")
+
+// ErrFollowRequestAlreadySent is returned when following a protected account that already has a pending request from the user
+var ErrFollowRequestAlreadySent = errors.New("follow request already sent")
+
 type FollowerUsecase interface {
-	FollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, error)
+	FollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, domain.FollowRequest, error)
+	BulkFollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, domain.FollowRequest, error)
 	UnfollowUser(ctx context.Context, followerID, followedID int64) error
+	GetFollowRequests(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.FollowRequest, int64, error)
+	ApproveFollowRequest(ctx context.Context, targetID, requesterID int64) (domain.Follower, error)
+	RejectFollowRequest(ctx context.Context, targetID, requesterID int64) error
+	ApprovePendingFollowRequests(ctx context.Context, targetID int64) (int, error)
+	GetFollowers(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Connection, int64, error)
+	GetFollowing(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Connection, int64, error)
 }
 
 type Follower struct {
-	followerRepository repository.FollowerRepository
-	userRepository     repository.UserRepository
+	followerRepository      repository.FollowerRepository
+	followRequestRepository repository.FollowRequestRepository
+	userRepository          repository.UserRepository
+	blockRepository         repository.BlockRepository
+	producer                pkg.Producer
 }
 
-func NewFollower(followerRepository repository.FollowerRepository, userRepository repository.UserRepository) Follower {
+func NewFollower(followerRepository repository.FollowerRepository, followRequestRepository repository.FollowRequestRepository, userRepository repository.UserRepository, blockRepository repository.BlockRepository, producer pkg.Producer) Follower {
 	return Follower{
-		followerRepository: followerRepository,
-		userRepository:     userRepository,
+		followerRepository:      followerRepository,
+		followRequestRepository: followRequestRepository,
+		userRepository:          userRepository,
+		blockRepository:         blockRepository,
+		producer:                producer,
 	}
 }
 
-func (f Follower) FollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, error) {
+// FollowUser follows a user. Following a protected account doesn't create the relationship:
+// a follow request is created instead and returned as the second value, waiting for the
+// owner's approval. Exactly one of the two returned values is set.
+func (f Follower) FollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, domain.FollowRequest, error) {
+	return f.followUser(ctx, followerID, followedID, false)
+}
+
+// BulkFollowUser follows a user like FollowUser, as part of a bulk import. The published event
+// tells the worker not to backfill the follower's timeline: the import backfills it once at the end.
+func (f Follower) BulkFollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, domain.FollowRequest, error) {
+	return f.followUser(ctx, followerID, followedID, true)
+}
+
+func (f Follower) followUser(ctx context.Context, followerID, followedID int64, bulk bool) (domain.Follower, domain.FollowRequest, error) {
 
 	// Validate that follower ID and followed ID are different
 	if followerID == followedID {
-		return domain.Follower{}, fmt.Errorf("cannot follow yourself")
+		return domain.Follower{}, domain.FollowRequest{}, fmt.Errorf("cannot follow yourself")
 	}
 
 	// Check if follower user exists
 	followerUser, err := f.userRepository.SelectByID(ctx, followerID)
 	if err != nil {
-		return domain.Follower{}, err
+		return domain.Follower{}, domain.FollowRequest{}, err
 	}
 	if followerUser.ID == 0 {
-		return domain.Follower{}, fmt.Errorf("follower user not found")
+		return domain.Follower{}, domain.FollowRequest{}, fmt.Errorf("follower user not found")
 	}
 
 	// Check if followed user exists
 	followedUser, err := f.userRepository.SelectByID(ctx, followedID)
 	if err != nil {
-		return domain.Follower{}, err
+		return domain.Follower{}, domain.FollowRequest{}, err
 	}
 	if followedUser.ID == 0 {
-		return domain.Follower{}, fmt.Errorf("followed user not found")
+		return domain.Follower{}, domain.FollowRequest{}, fmt.Errorf("followed user not found")
+	}
+
+	// Users can't follow each other while a block exists in either direction
+	blocked, err := f.blockRepository.ExistsBetween(ctx, followerID, followedID)
+	if err != nil {
+		return domain.Follower{}, domain.FollowRequest{}, err
+	}
+	if blocked {
+		return domain.Follower{}, domain.FollowRequest{}, fmt.Errorf("cannot follow this user")
 	}
 
 	// Check if relationship already exists
 	existingFollower, err := f.followerRepository.SelectByFollowerAndFollowed(ctx, followerID, followedID)
 	if err != nil {
-		return domain.Follower{}, err
+		return domain.Follower{}, domain.FollowRequest{}, err
 	}
 	if existingFollower.ID != 0 {
-		return domain.Follower{}, fmt.Errorf("already following this user")
+		return domain.Follower{}, domain.FollowRequest{}, ErrAlreadyFollowing
+	}
+
+	// Protected accounts approve their followers, ask them instead of following
+	if followedUser.Protected {
+		followRequest, err := f.requestFollow(ctx, followerID, followedID)
+		return domain.Follower{}, followRequest, err
 	}
 
 	// Create follower relationship
@@ -66,10 +117,33 @@ func (f Follower) FollowUser(ctx context.Context, followerID, followedID int64)
 
 	createdFollower, err := f.followerRepository.Insert(ctx, newFollower)
 	if err != nil {
-		return domain.Follower{}, err
+		return domain.Follower{}, domain.FollowRequest{}, err
+	}
+
+	go f.publishRelationshipEvent(dto.UserFollowedEvent, dto.UserRelationshipEventData{
+		UserID:       followerID,
+		TargetUserID: followedID,
+		Bulk:         bulk,
+	})
+
+	return createdFollower, domain.FollowRequest{}, nil
+}
+
+// requestFollow creates a pending follow request for a protected account.
+func (f Follower) requestFollow(ctx context.Context, requesterID, targetID int64) (domain.FollowRequest, error) {
+
+	existingRequest, err := f.followRequestRepository.SelectByRequesterAndTarget(ctx, requesterID, targetID)
+	if err != nil {
+		return domain.FollowRequest{}, err
+	}
+	if existingRequest.ID != 0 {
+		return domain.FollowRequest{}, ErrFollowRequestAlreadySent
 	}
 
-	return createdFollower, nil
+	return f.followRequestRepository.Insert(ctx, domain.FollowRequest{
+		RequesterID: requesterID,
+		TargetID:    targetID,
+	})
 }
 
 func (f Follower) UnfollowUser(ctx context.Context, followerID, followedID int64) error {
@@ -103,6 +177,15 @@ func (f Follower) UnfollowUser(ctx context.Context, followerID, followedID int64
 		return err
 	}
 	if existingFollower.ID == 0 {
+		// Unfollowing a protected account that didn't approve yet cancels the request
+		existingRequest, err := f.followRequestRepository.SelectByRequesterAndTarget(ctx, followerID, followedID)
+		if err != nil {
+			return err
+		}
+		if existingRequest.ID != 0 {
+			return f.followRequestRepository.Delete(ctx, followerID, followedID)
+		}
+
 		return fmt.Errorf("not following this user")
 	}
 
@@ -112,5 +195,199 @@ func (f Follower) UnfollowUser(ctx context.Context, followerID, followedID int64
 		return err
 	}
 
+	go f.publishFollowEvent(dto.UserUnfollowedEvent, followerID, followedID)
+
 	return nil
 }
+
+// GetFollowRequests returns the pending requests to follow userID, most recent first,
+// with the requesting users loaded, and the cursor of the next page (0 when there are no more).
+func (f Follower) GetFollowRequests(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.FollowRequest, int64, error) {
+
+	limit, cursor = normalizePage(limit, cursor)
+
+	followRequests, err := f.followRequestRepository.SelectPendingPage(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	requesterIDs := make([]int64, len(followRequests))
+	for i, followRequest := range followRequests {
+		requesterIDs[i] = followRequest.RequesterID
+	}
+
+	users, err := f.userRepository.SelectByIDs(ctx, requesterIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	usersByID := make(map[int64]domain.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	for i := range followRequests {
+		followRequests[i].Requester = usersByID[followRequests[i].RequesterID]
+	}
+
+	var nextCursor int64
+	if len(followRequests) == limit {
+		nextCursor = followRequests[len(followRequests)-1].ID
+	}
+
+	return followRequests, nextCursor, nil
+}
+
+// ApproveFollowRequest accepts a pending request to follow targetID. The requester
+// becomes a follower and their timeline is backfilled like after a direct follow.
+func (f Follower) ApproveFollowRequest(ctx context.Context, targetID, requesterID int64) (domain.Follower, error) {
+
+	existingRequest, err := f.followRequestRepository.SelectByRequesterAndTarget(ctx, requesterID, targetID)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+	if existingRequest.ID == 0 {
+		return domain.Follower{}, fmt.Errorf("follow request not found")
+	}
+
+	follower, err := f.followRequestRepository.Approve(ctx, requesterID, targetID)
+	if err != nil {
+		return domain.Follower{}, err
+	}
+
+	go f.publishFollowEvent(dto.UserFollowedEvent, requesterID, targetID)
+
+	return follower, nil
+}
+
+// RejectFollowRequest discards a pending request to follow targetID. The requester is not notified.
+func (f Follower) RejectFollowRequest(ctx context.Context, targetID, requesterID int64) error {
+
+	existingRequest, err := f.followRequestRepository.SelectByRequesterAndTarget(ctx, requesterID, targetID)
+	if err != nil {
+		return err
+	}
+	if existingRequest.ID == 0 {
+		return fmt.Errorf("follow request not found")
+	}
+
+	return f.followRequestRepository.Delete(ctx, requesterID, targetID)
+}
+
+// ApprovePendingFollowRequests approves every request waiting for targetID's approval,
+// once the account is no longer protected. It returns how many were approved.
+func (f Follower) ApprovePendingFollowRequests(ctx context.Context, targetID int64) (int, error) {
+
+	followerIDs, err := f.followRequestRepository.ApproveAll(ctx, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, followerID := range followerIDs {
+		go f.publishFollowEvent(dto.UserFollowedEvent, followerID, targetID)
+	}
+
+	return len(followerIDs), nil
+}
+
+func (f Follower) publishFollowEvent(eventType dto.EventType, followerID, followedID int64) {
+	f.publishRelationshipEvent(eventType, dto.UserRelationshipEventData{
+		UserID:       followerID,
+		TargetUserID: followedID,
+	})
+}
+
+func (f Follower) publishRelationshipEvent(eventType dto.EventType, data dto.UserRelationshipEventData) {
+	event := dto.NewEvent(eventType, data)
+	publishEvent(f.producer, config.TopicFollows, fmt.Sprintf(config.KeyFormatUser, data.UserID), event)
+}
+
+// GetFollowers returns the users following userID, most recent first, and the cursor
+// of the next page (0 when there are no more).
+func (f Follower) GetFollowers(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Connection, int64, error) {
+
+	limit, cursor = normalizePage(limit, cursor)
+
+	followers, err := f.followerRepository.SelectFollowersPage(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return f.hydrateConnections(ctx, followers, limit, func(follower domain.Follower) int64 {
+		return follower.FollowerID
+	})
+}
+
+// GetFollowing returns the users followed by userID, most recent first, and the cursor
+// of the next page (0 when there are no more).
+func (f Follower) GetFollowing(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Connection, int64, error) {
+
+	limit, cursor = normalizePage(limit, cursor)
+
+	following, err := f.followerRepository.SelectFollowingPage(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return f.hydrateConnections(ctx, following, limit, func(follower domain.Follower) int64 {
+		return follower.FollowedID
+	})
+}
+
+// hydrateConnections loads the users on the other side of each relationship with a
+// single query, keeping the order of the relationships.
+func (f Follower) hydrateConnections(ctx context.Context, relationships []domain.Follower, limit int, otherUserID func(domain.Follower) int64) ([]domain.Connection, int64, error) {
+
+	userIDs := make([]int64, len(relationships))
+	for i, relationship := range relationships {
+		userIDs[i] = otherUserID(relationship)
+	}
+
+	users, err := f.userRepository.SelectByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	usersByID := make(map[int64]domain.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	connections := make([]domain.Connection, 0, len(relationships))
+	for _, relationship := range relationships {
+		user, ok := usersByID[otherUserID(relationship)]
+		if !ok {
+			continue
+		}
+		connections = append(connections, domain.Connection{
+			FollowID:   relationship.ID,
+			User:       user,
+			FollowedAt: relationship.CreatedAt,
+		})
+	}
+
+	var nextCursor int64
+	if len(relationships) == limit {
+		nextCursor = relationships[len(relationships)-1].ID
+	}
+
+	return connections, nextCursor, nil
+}
+
+// normalizePage applies the default and max values for cursor pagination.
+func normalizePage(limit int, cursor int64) (int, int64) {
+
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	return limit, cursor
+}
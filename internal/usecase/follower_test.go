@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// stubFollowRequestRepository holds at most one pending request and records what is
+// done with the requests.
+type stubFollowRequestRepository struct {
+	repository.FollowRequestRepository
+	existing domain.FollowRequest
+	inserted *[]domain.FollowRequest
+	approved *[]domain.FollowRequest
+	deleted  *[]domain.FollowRequest
+}
+
+func (s stubFollowRequestRepository) SelectByRequesterAndTarget(ctx context.Context, requesterID, targetID int64) (domain.FollowRequest, error) {
+	if s.existing.RequesterID != requesterID || s.existing.TargetID != targetID {
+		return domain.FollowRequest{}, nil
+	}
+	return s.existing, nil
+}
+
+func (s stubFollowRequestRepository) Insert(ctx context.Context, followRequest domain.FollowRequest) (domain.FollowRequest, error) {
+	*s.inserted = append(*s.inserted, followRequest)
+	followRequest.ID = int64(len(*s.inserted))
+	return followRequest, nil
+}
+
+func (s stubFollowRequestRepository) Approve(ctx context.Context, requesterID, targetID int64) (domain.Follower, error) {
+	*s.approved = append(*s.approved, domain.FollowRequest{RequesterID: requesterID, TargetID: targetID})
+	return domain.Follower{ID: 1, FollowerID: requesterID, FollowedID: targetID}, nil
+}
+
+func (s stubFollowRequestRepository) Delete(ctx context.Context, requesterID, targetID int64) error {
+	*s.deleted = append(*s.deleted, domain.FollowRequest{RequesterID: requesterID, TargetID: targetID})
+	return nil
+}
+
+func newStubFollowRequestRepository(existing domain.FollowRequest) stubFollowRequestRepository {
+	return stubFollowRequestRepository{
+		existing: existing,
+		inserted: new([]domain.FollowRequest),
+		approved: new([]domain.FollowRequest),
+		deleted:  new([]domain.FollowRequest),
+	}
+}
+
+func TestFollower_FollowUser_ProtectedAccount(t *testing.T) {
+	// Arrange: user 2 is protected
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(1)).Return(domain.User{ID: 1}, nil)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(2)).Return(domain.User{ID: 2, Protected: true}, nil)
+
+	var inserted []domain.Follower
+	followerRepository := stubFollowerRepository{inserted: &inserted}
+	followRequestRepository := newStubFollowRequestRepository(domain.FollowRequest{})
+	followerUsecase := NewFollower(followerRepository, followRequestRepository, userRepository, stubBlockRepository{}, nil)
+
+	// Act
+	follower, followRequest, err := followerUsecase.FollowUser(context.Background(), 1, 2)
+
+	// Assert: a request waits for approval, no relationship is created
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), follower.ID)
+	assert.Equal(t, domain.FollowRequest{ID: 1, RequesterID: 1, TargetID: 2}, followRequest)
+	assert.Empty(t, inserted)
+	assert.Equal(t, []domain.FollowRequest{{RequesterID: 1, TargetID: 2}}, *followRequestRepository.inserted)
+}
+
+func TestFollower_FollowUser_RequestAlreadySent(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(1)).Return(domain.User{ID: 1}, nil)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(2)).Return(domain.User{ID: 2, Protected: true}, nil)
+
+	followRequestRepository := newStubFollowRequestRepository(domain.FollowRequest{ID: 3, RequesterID: 1, TargetID: 2})
+	followerUsecase := NewFollower(stubFollowerRepository{}, followRequestRepository, userRepository, stubBlockRepository{}, nil)
+
+	// Act
+	_, _, err := followerUsecase.FollowUser(context.Background(), 1, 2)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrFollowRequestAlreadySent)
+	assert.Empty(t, *followRequestRepository.inserted)
+}
+
+func TestFollower_ApproveFollowRequest(t *testing.T) {
+	// Arrange
+	followRequestRepository := newStubFollowRequestRepository(domain.FollowRequest{ID: 3, RequesterID: 1, TargetID: 2})
+	producer := newStubProducer()
+	followerUsecase := NewFollower(stubFollowerRepository{}, followRequestRepository, nil, nil, producer)
+
+	// Act
+	follower, err := followerUsecase.ApproveFollowRequest(context.Background(), 2, 1)
+
+	// Assert: the follow is announced like a direct one, so the worker backfills the timeline
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Follower{ID: 1, FollowerID: 1, FollowedID: 2}, follower)
+	assert.Equal(t, []domain.FollowRequest{{RequesterID: 1, TargetID: 2}}, *followRequestRepository.approved)
+
+	published := <-producer.published
+	assert.Equal(t, dto.UserFollowedEvent, published.Type)
+	assert.Equal(t, dto.UserRelationshipEventData{UserID: 1, TargetUserID: 2}, published.Data)
+}
+
+func TestFollower_ApproveFollowRequest_NotFound(t *testing.T) {
+	// Arrange
+	followRequestRepository := newStubFollowRequestRepository(domain.FollowRequest{})
+	followerUsecase := NewFollower(stubFollowerRepository{}, followRequestRepository, nil, nil, nil)
+
+	// Act
+	_, err := followerUsecase.ApproveFollowRequest(context.Background(), 2, 1)
+
+	// Assert
+	assert.EqualError(t, err, "follow request not found")
+	assert.Empty(t, *followRequestRepository.approved)
+}
+
+func TestFollower_RejectFollowRequest(t *testing.T) {
+	// Arrange
+	followRequestRepository := newStubFollowRequestRepository(domain.FollowRequest{ID: 3, RequesterID: 1, TargetID: 2})
+	followerUsecase := NewFollower(stubFollowerRepository{}, followRequestRepository, nil, nil, nil)
+
+	// Act
+	err := followerUsecase.RejectFollowRequest(context.Background(), 2, 1)
+
+	// Assert: the request is dropped without creating the relationship
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.FollowRequest{{RequesterID: 1, TargetID: 2}}, *followRequestRepository.deleted)
+	assert.Empty(t, *followRequestRepository.approved)
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+const (
+	// TweetCacheKey defines the key for the cached copy of a tweet
+	TweetCacheKey = "tweet:%d"
+	// TweetCacheExpiration defines how long a cached tweet is kept without being read again
+	TweetCacheExpiration = 24 * time.Hour
+	// tweetVersionField is the field of a cached tweet telling which version it is, so an
+	// older copy never replaces a newer one
+	tweetVersionField = "edit_count"
+	// ProfileCacheKey defines the key for the cached public profile of a user
+	ProfileCacheKey = "user:%d"
+	// ProfileCacheExpiration defines how long a cached profile is kept. Profiles are dropped
+	// when follow counts change; the expiration bounds how long other edits take to show up.
+	ProfileCacheExpiration = 10 * time.Minute
+)
+
+// cachedTweet is the serialized form of a tweet in the tweet cache.
+type cachedTweet struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	Content        string    `json:"content"`
+	ReplyToTweetID int64     `json:"reply_to_tweet_id,omitempty"`
+	RetweetOfID    int64     `json:"retweet_of_id,omitempty"`
+	EditCount      int       `json:"edit_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// cachedProfile is the serialized form of a user in the profile cache.
+// Only the public fields are cached, never the email or password.
+type cachedProfile struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	FollowersCount int    `json:"followers_count"`
+	FollowingCount int    `json:"following_count"`
+	Protected      bool   `json:"protected,omitempty"`
+}
+
+// hydrateTweets loads tweets by ID, in the order of tweetIDs, from the tweet cache. Only
+// the tweets missing from it are read from the database, and cached for the next reads.
+// Tweets that don't exist anymore are left out.
+func (t Timeline) hydrateTweets(ctx context.Context, tweetIDs []int64) ([]domain.Tweet, error) {
+
+	if len(tweetIDs) == 0 {
+		return []domain.Tweet{}, nil
+	}
+
+	keys := make([]string, len(tweetIDs))
+	for i, id := range tweetIDs {
+		keys[i] = t.getTweetCacheKey(id)
+	}
+
+	// A cache failure is not fatal, every tweet is then read from the database
+	entries, err := t.cache.MGet(ctx, keys)
+	if err != nil {
+		log.Printf("Failed to read cached tweets: %v", err)
+		entries = make([]string, len(keys))
+	}
+
+	found := make(map[int64]domain.Tweet, len(tweetIDs))
+	var missingIDs []int64
+	for i, entry := range entries {
+		tweet, ok := parseCachedTweet(entry)
+		if !ok {
+			missingIDs = append(missingIDs, tweetIDs[i])
+			continue
+		}
+		found[tweet.ID] = tweet
+	}
+
+	if len(missingIDs) > 0 {
+		tweets, err := t.tweetRepository.SelectTweetsByIDs(ctx, missingIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tweet := range tweets {
+			found[tweet.ID] = tweet
+		}
+		t.cacheTweets(ctx, tweets)
+	}
+
+	hydrated := make([]domain.Tweet, 0, len(tweetIDs))
+	for _, id := range tweetIDs {
+		if tweet, ok := found[id]; ok {
+			hydrated = append(hydrated, tweet)
+		}
+	}
+
+	return hydrated, nil
+}
+
+// HydrateTweets loads tweets by ID, in the order of tweetIDs, from the tweet cache.
+func (t Timeline) HydrateTweets(ctx context.Context, tweetIDs []int64) ([]domain.Tweet, error) {
+	return t.hydrateTweets(ctx, tweetIDs)
+}
+
+// HydrateAuthors returns the authors of the tweets by user ID, from the profile cache.
+// Only the profiles missing from it are read from the database, in one query.
+func (t Timeline) HydrateAuthors(ctx context.Context, tweets []domain.Tweet) (map[int64]domain.User, error) {
+
+	authors := make(map[int64]domain.User)
+	if len(tweets) == 0 {
+		return authors, nil
+	}
+
+	authorIDs := make([]int64, 0, len(tweets))
+	for _, tweet := range tweets {
+		authorIDs = append(authorIDs, tweet.UserID)
+	}
+	authorIDs = uniqueIDs(authorIDs)
+
+	keys := make([]string, len(authorIDs))
+	for i, id := range authorIDs {
+		keys[i] = t.getProfileCacheKey(id)
+	}
+
+	entries, err := t.cache.MGet(ctx, keys)
+	if err != nil {
+		log.Printf("Failed to read cached profiles: %v", err)
+		entries = make([]string, len(keys))
+	}
+
+	var missingIDs []int64
+	for i, entry := range entries {
+		user, ok := parseCachedProfile(entry)
+		if !ok {
+			missingIDs = append(missingIDs, authorIDs[i])
+			continue
+		}
+		authors[user.ID] = user
+	}
+
+	if len(missingIDs) == 0 {
+		return authors, nil
+	}
+
+	users, err := t.userRepository.SelectByIDs(ctx, missingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]interface{}, len(users))
+	for _, user := range users {
+		authors[user.ID] = user
+		profiles[t.getProfileCacheKey(user.ID)] = formatCachedProfile(user)
+	}
+
+	if err := t.cache.SetMany(ctx, profiles, ProfileCacheExpiration); err != nil {
+		log.Printf("Failed to cache profiles: %v", err)
+	}
+
+	return authors, nil
+}
+
+// CacheTweet stores a new tweet in the tweet cache, so the timelines it is fanned out to
+// are hydrated without reading it from the database.
+func (t Timeline) CacheTweet(ctx context.Context, tweet domain.Tweet) error {
+
+	if err := t.cache.SetManyIfNewer(ctx, map[string]interface{}{t.getTweetCacheKey(tweet.ID): formatCachedTweet(tweet)}, tweetVersionField, TweetCacheExpiration); err != nil {
+		return fmt.Errorf("failed to cache tweet %d: %w", tweet.ID, err)
+	}
+
+	return nil
+}
+
+// RefreshTweet caches the current version of a tweet after it was edited. Dropping it
+// would not be enough: a reader that read the previous version from the database could
+// cache it again afterwards. Cached tweets are versioned by their edit count instead, and
+// an older version never replaces a newer one.
+func (t Timeline) RefreshTweet(ctx context.Context, tweetID int64) error {
+
+	tweets, err := t.tweetRepository.SelectTweetsByIDs(ctx, []int64{tweetID})
+	if err != nil {
+		return err
+	}
+
+	if len(tweets) == 0 {
+		if err := t.cache.Delete(ctx, t.getTweetCacheKey(tweetID)); err != nil {
+			return fmt.Errorf("failed to invalidate tweet %d: %w", tweetID, err)
+		}
+		return nil
+	}
+
+	return t.CacheTweet(ctx, tweets[0])
+}
+
+// InvalidateProfiles drops the cached profiles of users, e.g. after their follow counts changed.
+func (t Timeline) InvalidateProfiles(ctx context.Context, userIDs ...int64) error {
+
+	for _, userID := range userIDs {
+		if err := t.cache.Delete(ctx, t.getProfileCacheKey(userID)); err != nil {
+			return fmt.Errorf("failed to invalidate profile of user %d: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// cacheTweets stores tweets read from the database in the tweet cache.
+func (t Timeline) cacheTweets(ctx context.Context, tweets []domain.Tweet) {
+
+	if len(tweets) == 0 {
+		return
+	}
+
+	entries := make(map[string]interface{}, len(tweets))
+	for _, tweet := range tweets {
+		entries[t.getTweetCacheKey(tweet.ID)] = formatCachedTweet(tweet)
+	}
+
+	if err := t.cache.SetManyIfNewer(ctx, entries, tweetVersionField, TweetCacheExpiration); err != nil {
+		log.Printf("Failed to cache tweets: %v", err)
+	}
+}
+
+// getTweetCacheKey constructs the cache key for a tweet.
+func (t Timeline) getTweetCacheKey(tweetID int64) string {
+	return fmt.Sprintf(TweetCacheKey, tweetID)
+}
+
+// getProfileCacheKey constructs the cache key for a user profile.
+func (t Timeline) getProfileCacheKey(userID int64) string {
+	return fmt.Sprintf(ProfileCacheKey, userID)
+}
+
+// formatCachedTweet serializes a tweet for the tweet cache.
+func formatCachedTweet(tweet domain.Tweet) string {
+
+	data, _ := json.Marshal(cachedTweet{
+		ID:             tweet.ID,
+		UserID:         tweet.UserID,
+		Content:        tweet.Content,
+		ReplyToTweetID: tweet.ReplyToTweetID,
+		RetweetOfID:    tweet.RetweetOfID,
+		EditCount:      tweet.EditCount,
+		CreatedAt:      tweet.CreatedAt,
+		UpdatedAt:      tweet.UpdatedAt,
+	})
+
+	return string(data)
+}
+
+// parseCachedTweet parses a tweet written by formatCachedTweet. An empty entry is a miss.
+func parseCachedTweet(entry string) (domain.Tweet, bool) {
+
+	var cached cachedTweet
+	if entry == "" || json.Unmarshal([]byte(entry), &cached) != nil || cached.ID == 0 {
+		return domain.Tweet{}, false
+	}
+
+	return domain.Tweet{
+		ID:             cached.ID,
+		UserID:         cached.UserID,
+		Content:        cached.Content,
+		ReplyToTweetID: cached.ReplyToTweetID,
+		RetweetOfID:    cached.RetweetOfID,
+		EditCount:      cached.EditCount,
+		CreatedAt:      cached.CreatedAt,
+		UpdatedAt:      cached.UpdatedAt,
+	}, true
+}
+
+// formatCachedProfile serializes the public fields of a user for the profile cache.
+func formatCachedProfile(user domain.User) string {
+
+	data, _ := json.Marshal(cachedProfile{
+		ID:             user.ID,
+		Username:       user.Username,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		Protected:      user.Protected,
+	})
+
+	return string(data)
+}
+
+// parseCachedProfile parses a profile written by formatCachedProfile. An empty entry is a miss.
+func parseCachedProfile(entry string) (domain.User, bool) {
+
+	var cached cachedProfile
+	if entry == "" || json.Unmarshal([]byte(entry), &cached) != nil || cached.ID == 0 {
+		return domain.User{}, false
+	}
+
+	return domain.User{
+		ID:             cached.ID,
+		Username:       cached.Username,
+		FollowersCount: cached.FollowersCount,
+		FollowingCount: cached.FollowingCount,
+		Protected:      cached.Protected,
+	}, true
+}
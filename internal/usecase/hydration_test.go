@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedTweetRoundTrip(t *testing.T) {
+	// Arrange
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tweet := domain.Tweet{
+		ID:             42,
+		UserID:         7,
+		Content:        "Hello, world!",
+		ReplyToTweetID: 41,
+		EditCount:      1,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt.Add(time.Minute),
+	}
+
+	// Act
+	parsed, ok := parseCachedTweet(formatCachedTweet(tweet))
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, tweet, parsed)
+}
+
+func TestCachedTweetHasVersion(t *testing.T) {
+	// The version is compared when caching, it is written even for a tweet never edited
+	assert.Contains(t, formatCachedTweet(domain.Tweet{ID: 42}), `"`+tweetVersionField+`":0`)
+	assert.Contains(t, formatCachedTweet(domain.Tweet{ID: 42, EditCount: 2}), `"`+tweetVersionField+`":2`)
+}
+
+func TestParseCachedTweetMiss(t *testing.T) {
+	_, ok := parseCachedTweet("")
+	assert.False(t, ok)
+
+	_, ok = parseCachedTweet("not json")
+	assert.False(t, ok)
+}
+
+func TestCachedProfileKeepsPublicFields(t *testing.T) {
+	// Arrange
+	user := domain.User{
+		ID:             7,
+		Username:       "gopher",
+		Email:          "gopher@example.com",
+		Password:       "secret",
+		FollowersCount: 10,
+		FollowingCount: 3,
+		Protected:      true,
+	}
+
+	// Act
+	entry := formatCachedProfile(user)
+	parsed, ok := parseCachedProfile(entry)
+
+	// Assert
+	assert.True(t, ok)
+	assert.NotContains(t, entry, "gopher@example.com")
+	assert.NotContains(t, entry, "secret")
+	assert.Equal(t, domain.User{ID: 7, Username: "gopher", FollowersCount: 10, FollowingCount: 3, Protected: true}, parsed)
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+const (
+	// MaxListNameLength defines the maximum length of a list name
+	MaxListNameLength = 25
+	// MaxListDescriptionLength defines the maximum length of a list description
+	MaxListDescriptionLength = 100
+)
+
+// ErrListNotFound is returned when a list doesn't exist or is private and the viewer is not its owner.
+var ErrListNotFound = errors.New("list not found")
+
+type ListUsecase interface {
+	GetList(ctx context.Context, id, viewerID int64) (domain.List, error)
+	GetUserLists(ctx context.Context, userID, viewerID int64) ([]domain.List, error)
+	GetSubscribedLists(ctx context.Context, userID int64) ([]domain.List, error)
+	GetListMembers(ctx context.Context, id, viewerID int64) ([]domain.User, error)
+	CreateList(ctx context.Context, list domain.List) (domain.List, error)
+	UpdateList(ctx context.Context, id int64, list domain.List) (domain.List, error)
+	DeleteList(ctx context.Context, id, ownerID int64) error
+	AddMember(ctx context.Context, id, ownerID, userID int64) error
+	RemoveMember(ctx context.Context, id, ownerID, userID int64) error
+	Subscribe(ctx context.Context, id, userID int64) error
+	Unsubscribe(ctx context.Context, id, userID int64) error
+}
+
+type List struct {
+	listRepository  repository.ListRepository
+	userRepository  repository.UserRepository
+	blockRepository repository.BlockRepository
+	timelineUsecase TimelineUsecase
+}
+
+func NewList(listRepository repository.ListRepository, userRepository repository.UserRepository, blockRepository repository.BlockRepository, timelineUsecase TimelineUsecase) List {
+	return List{
+		listRepository:  listRepository,
+		userRepository:  userRepository,
+		blockRepository: blockRepository,
+		timelineUsecase: timelineUsecase,
+	}
+}
+
+func (l List) GetList(ctx context.Context, id, viewerID int64) (domain.List, error) {
+
+	list, err := l.listRepository.SelectByID(ctx, id)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	if !canViewList(list, viewerID) {
+		return domain.List{}, ErrListNotFound
+	}
+
+	return list, nil
+}
+
+// GetUserLists returns the lists owned by a user. Private lists are only returned to their owner.
+func (l List) GetUserLists(ctx context.Context, userID, viewerID int64) ([]domain.List, error) {
+
+	lists, err := l.listRepository.SelectByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visibleLists := make([]domain.List, 0, len(lists))
+	for _, list := range lists {
+		if canViewList(list, viewerID) {
+			visibleLists = append(visibleLists, list)
+		}
+	}
+
+	return visibleLists, nil
+}
+
+func (l List) GetSubscribedLists(ctx context.Context, userID int64) ([]domain.List, error) {
+	return l.listRepository.SelectSubscribedLists(ctx, userID)
+}
+
+func (l List) GetListMembers(ctx context.Context, id, viewerID int64) ([]domain.User, error) {
+
+	if _, err := l.GetList(ctx, id, viewerID); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := l.listRepository.SelectMemberIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := l.userRepository.SelectByIDs(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the order of the members, most recently added first
+	usersByID := make(map[int64]domain.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	members := make([]domain.User, 0, len(users))
+	for _, memberID := range memberIDs {
+		if user, ok := usersByID[memberID]; ok {
+			members = append(members, user)
+		}
+	}
+
+	return members, nil
+}
+
+func (l List) CreateList(ctx context.Context, list domain.List) (domain.List, error) {
+
+	if err := validateList(list); err != nil {
+		return domain.List{}, err
+	}
+
+	// Check if owner exists
+	owner, err := l.userRepository.SelectByID(ctx, list.OwnerID)
+	if err != nil {
+		return domain.List{}, err
+	}
+	if owner.ID == 0 {
+		return domain.List{}, fmt.Errorf("user not found")
+	}
+
+	return l.listRepository.Insert(ctx, list)
+}
+
+func (l List) UpdateList(ctx context.Context, id int64, list domain.List) (domain.List, error) {
+
+	if err := validateList(list); err != nil {
+		return domain.List{}, err
+	}
+
+	existingList, err := l.getOwnedList(ctx, id, list.OwnerID)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	existingList.Name = list.Name
+	existingList.Description = list.Description
+	existingList.Private = list.Private
+
+	return l.listRepository.UpdateByID(ctx, id, existingList)
+}
+
+func (l List) DeleteList(ctx context.Context, id, ownerID int64) error {
+
+	if _, err := l.getOwnedList(ctx, id, ownerID); err != nil {
+		return err
+	}
+
+	if err := l.listRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	l.invalidateTimeline(ctx, id)
+
+	return nil
+}
+
+// AddMember adds a user to a list owned by ownerID. Users blocking the owner, or
+// blocked by them, cannot be added.
+func (l List) AddMember(ctx context.Context, id, ownerID, userID int64) error {
+
+	if _, err := l.getOwnedList(ctx, id, ownerID); err != nil {
+		return err
+	}
+
+	user, err := l.userRepository.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	blocked, err := l.blockRepository.ExistsBetween(ctx, ownerID, userID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fmt.Errorf("cannot add this user to the list")
+	}
+
+	isMember, err := l.listRepository.IsMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return fmt.Errorf("user is already a member of this list")
+	}
+
+	if err := l.listRepository.InsertMember(ctx, id, userID); err != nil {
+		return err
+	}
+
+	l.invalidateTimeline(ctx, id)
+
+	return nil
+}
+
+func (l List) RemoveMember(ctx context.Context, id, ownerID, userID int64) error {
+
+	if _, err := l.getOwnedList(ctx, id, ownerID); err != nil {
+		return err
+	}
+
+	isMember, err := l.listRepository.IsMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("user is not a member of this list")
+	}
+
+	if err := l.listRepository.DeleteMember(ctx, id, userID); err != nil {
+		return err
+	}
+
+	l.invalidateTimeline(ctx, id)
+
+	return nil
+}
+
+// Subscribe lets a user follow the timeline of a public list.
+func (l List) Subscribe(ctx context.Context, id, userID int64) error {
+
+	list, err := l.GetList(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if list.OwnerID == userID {
+		return fmt.Errorf("cannot subscribe to your own list")
+	}
+
+	user, err := l.userRepository.SelectByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	isSubscriber, err := l.listRepository.IsSubscriber(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if isSubscriber {
+		return fmt.Errorf("already subscribed to this list")
+	}
+
+	return l.listRepository.InsertSubscriber(ctx, id, userID)
+}
+
+func (l List) Unsubscribe(ctx context.Context, id, userID int64) error {
+
+	isSubscriber, err := l.listRepository.IsSubscriber(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !isSubscriber {
+		return fmt.Errorf("not subscribed to this list")
+	}
+
+	return l.listRepository.DeleteSubscriber(ctx, id, userID)
+}
+
+// getOwnedList returns a list only if it belongs to the given user.
+func (l List) getOwnedList(ctx context.Context, id, ownerID int64) (domain.List, error) {
+
+	list, err := l.listRepository.SelectByID(ctx, id)
+	if err != nil {
+		return domain.List{}, err
+	}
+
+	if list.ID == 0 || list.OwnerID != ownerID {
+		return domain.List{}, ErrListNotFound
+	}
+
+	return list, nil
+}
+
+// invalidateTimeline drops the cached list timeline after its members changed.
+// The change is already stored, a stale cache only lasts until it expires.
+func (l List) invalidateTimeline(ctx context.Context, id int64) {
+	if err := l.timelineUsecase.InvalidateListTimeline(ctx, id); err != nil {
+		log.Printf("Failed to invalidate list timeline: %v", err)
+	}
+}
+
+func validateList(list domain.List) error {
+
+	name := strings.TrimSpace(list.Name)
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+
+	if len([]rune(name)) > MaxListNameLength {
+		return fmt.Errorf("name cannot be longer than %d characters", MaxListNameLength)
+	}
+
+	if len([]rune(list.Description)) > MaxListDescriptionLength {
+		return fmt.Errorf("description cannot be longer than %d characters", MaxListDescriptionLength)
+	}
+
+	return nil
+}
+
+// canViewList reports whether viewerID can see a list: public lists are visible to
+// everyone, private ones only to their owner.
+func canViewList(list domain.List, viewerID int64) bool {
+	return list.ID != 0 && (!list.Private || list.OwnerID == viewerID)
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidateList(t *testing.T) {
+	// Arrange
+	valid := domain.List{Name: "Go developers", Description: "People writing Go"}
+	emptyName := domain.List{Name: "   "}
+	longName := domain.List{Name: strings.Repeat("a", MaxListNameLength+1)}
+	longDescription := domain.List{Name: "News", Description: strings.Repeat("a", MaxListDescriptionLength+1)}
+
+	// Act & Assert
+	assert.NoError(t, validateList(valid))
+	assert.Error(t, validateList(emptyName))
+	assert.Error(t, validateList(longName))
+	assert.Error(t, validateList(longDescription))
+}
+
+func TestCanViewList(t *testing.T) {
+	// Arrange
+	publicList := domain.List{ID: 1, OwnerID: 10}
+	privateList := domain.List{ID: 2, OwnerID: 10, Private: true}
+
+	// Act & Assert
+	assert.True(t, canViewList(publicList, 0))
+	assert.True(t, canViewList(publicList, 20))
+	assert.True(t, canViewList(privateList, 10))
+	assert.False(t, canViewList(privateList, 20))
+	assert.False(t, canViewList(domain.List{}, 10))
+}
+
+// stubListRepository serves a single list and its members.
+type stubListRepository struct {
+	repository.ListRepository
+	list      domain.List
+	memberIDs []int64
+}
+
+func (s stubListRepository) SelectByID(ctx context.Context, id int64) (domain.List, error) {
+	if id != s.list.ID {
+		return domain.List{}, nil
+	}
+	return s.list, nil
+}
+
+func (s stubListRepository) SelectMemberIDs(ctx context.Context, listID int64) ([]int64, error) {
+	return s.memberIDs, nil
+}
+
+// SelectListIDsByMemberID returns the list when the user is one of its members.
+func (s stubListRepository) SelectListIDsByMemberID(ctx context.Context, userID int64) ([]int64, error) {
+	if !slices.Contains(s.memberIDs, userID) {
+		return nil, nil
+	}
+	return []int64{s.list.ID}, nil
+}
+
+func TestTimeline_GetListTimeline_PrivateList(t *testing.T) {
+	// Arrange: list 7 of user 10 is private and cached, its only member is user 2
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByIDs(gomock.Any(), []int64{2}).Return([]domain.User{{ID: 2}}, nil).AnyTimes()
+
+	cache := newStubCache()
+	timeline := Timeline{
+		cache:           cache,
+		listRepository:  stubListRepository{list: domain.List{ID: 7, OwnerID: 10, Private: true}, memberIDs: []int64{2}},
+		userRepository:  userRepository,
+		muteRepository:  stubMuteRepository{},
+		blockRepository: stubBlockRepository{},
+	}
+	cache.lists[timeline.getListCacheKey(7)] = []string{"9", TimelineEndMarker}
+	cache.values[timeline.getTweetCacheKey(9)] = formatCachedTweet(domain.Tweet{ID: 9, UserID: 2})
+
+	// Act
+	ownerTweets, ownerErr := timeline.GetListTimeline(context.Background(), 7, 10, 20, 0)
+	_, otherErr := timeline.GetListTimeline(context.Background(), 7, 20, 20, 0)
+	_, anonymousErr := timeline.GetListTimeline(context.Background(), 7, 0, 20, 0)
+
+	// Assert: only the owner can read it, the others don't learn that it exists
+	assert.NoError(t, ownerErr)
+	assert.Equal(t, []int64{9}, tweetIDsOf(ownerTweets))
+	assert.ErrorIs(t, otherErr, ErrListNotFound)
+	assert.ErrorIs(t, anonymousErr, ErrListNotFound)
+}
+
+func TestTimeline_GetHiddenListMemberIDs(t *testing.T) {
+	// Arrange: user 3 and user 4 are protected, the viewer (user 1) follows user 3 and muted user 5
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	members := []domain.User{{ID: 2}, {ID: 3, Protected: true}, {ID: 4, Protected: true}, {ID: 5}}
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByIDs(gomock.Any(), []int64{2, 3, 4, 5}).Return(members, nil).AnyTimes()
+
+	timeline := Timeline{
+		cache:              newStubCache(),
+		listRepository:     stubListRepository{list: domain.List{ID: 7, OwnerID: 10}, memberIDs: []int64{2, 3, 4, 5}},
+		userRepository:     userRepository,
+		followerRepository: stubFollowerRepository{followers: map[int64][]int64{3: {1}}},
+		muteRepository:     stubMuteRepository{mutedIDs: []int64{5}},
+		blockRepository:    stubBlockRepository{},
+	}
+
+	// Act
+	viewerHidden, err := timeline.getHiddenListMemberIDs(context.Background(), 7, 1)
+	assert.NoError(t, err)
+	anonymousHidden, err := timeline.getHiddenListMemberIDs(context.Background(), 7, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 4}, viewerHidden)
+	assert.Equal(t, []int64{3, 4}, anonymousHidden)
+}
+
+func TestTimeline_FanOutToLists(t *testing.T) {
+	// Arrange: user 2 is a member of list 7, whose timeline is cached
+	cache := newStubCache()
+	timeline := Timeline{
+		cache:          cache,
+		listRepository: stubListRepository{list: domain.List{ID: 7}, memberIDs: []int64{2}},
+	}
+	cache.lists[timeline.getListCacheKey(7)] = []string{"8", TimelineEndMarker}
+
+	// Act
+	timeline.fanOutToLists(context.Background(), 2, "9")
+	timeline.fanOutToLists(context.Background(), 3, "10")
+
+	// Assert: only the lists of the author get the tweet
+	assert.Equal(t, []string{"9", "8", TimelineEndMarker}, cache.lists[timeline.getListCacheKey(7)])
+}
+
+func TestTimeline_FanOutToLists_SkipsMissingTimelines(t *testing.T) {
+	// Arrange: list 7 isn't cached
+	cache := newStubCache()
+	timeline := Timeline{
+		cache:          cache,
+		listRepository: stubListRepository{list: domain.List{ID: 7}, memberIDs: []int64{2}},
+	}
+
+	// Act
+	timeline.fanOutToLists(context.Background(), 2, "9")
+
+	// Assert: no partial timeline is created, it is rebuilt whole on the next read
+	assert.NotContains(t, cache.lists, timeline.getListCacheKey(7))
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+)
+
+const (
+	// MentionsCacheKey defines the key for the cache of the tweets mentioning a user
+	MentionsCacheKey = "mentions:user:%d"
+	// MaxCachedMentions defines the maximum number of mentions to keep in cache per user
+	MaxCachedMentions = 200
+)
+
+// GetMentions returns the tweets mentioning a user, newest first, including the ones
+// written by users they don't follow. Pagination uses the ID of the last tweet of the
+// previous page as cursor. It returns the tweets and the cursor of the next page (0 at
+// the end). Tweets of muted or blocked users, and of protected accounts the user doesn't
+// follow, are left out.
+func (t Timeline) GetMentions(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Tweet, int64, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	hiddenUserIDs, err := t.getHiddenUserIDs(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// STEP 1: Try to resolve the page from the cached list of mentions
+	mentions, cacheHit := t.retrieveCachedMentions(ctx, userID, limit, cursor, hiddenUserIDs)
+
+	// STEP 2: Cache miss - fall back to database
+	if !cacheHit {
+		mentions, err = t.mentionRepository.SelectMentions(ctx, userID, cursor, limit, hiddenUserIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Only the newest mentions are cached, rebuild the list when the first page was missed.
+		// The rebuild outlives the request, so it doesn't use the request context.
+		if cursor == 0 {
+			go t.cacheMentions(context.Background(), userID)
+		}
+	}
+
+	var nextCursor int64
+	if len(mentions) == limit {
+		nextCursor = mentions[len(mentions)-1].TweetID
+	}
+
+	tweetIDs := make([]int64, len(mentions))
+	for i, mention := range mentions {
+		tweetIDs[i] = mention.TweetID
+	}
+
+	tweets, err := t.hydrateTweets(ctx, tweetIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// STEP 3: Leave out the protected accounts the user can't see
+	hiddenAuthorIDs, err := t.getInvisibleAuthorIDs(ctx, userID, tweets)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return removeHiddenAuthors(tweets, hiddenAuthorIDs), nextCursor, nil
+}
+
+// retrieveCachedMentions resolves a page of a user's mentions from cache, skipping the
+// ones written by hiddenUserIDs. It reports a miss when the list is not cached, or when
+// the page goes beyond the cached window and older mentions may exist in the database.
+func (t Timeline) retrieveCachedMentions(ctx context.Context, userID int64, limit int, cursor int64, hiddenUserIDs []int64) ([]domain.Mention, bool) {
+
+	entries, err := t.cache.LRange(ctx, t.getMentionsCacheKey(userID), 0, MaxCachedMentions)
+	if err != nil || len(entries) == 0 {
+		return nil, false
+	}
+
+	hidden := make(map[int64]bool, len(hiddenUserIDs))
+	for _, id := range hiddenUserIDs {
+		hidden[id] = true
+	}
+
+	mentions, complete := parseCachedMentions(entries, limit, cursor, hidden)
+
+	// Only a list ending with the end marker holds every mention of the user
+	if len(mentions) < limit && !complete {
+		return nil, false
+	}
+
+	return mentions, true
+}
+
+// cacheMentions rebuilds the cached list of a user's newest mentions from the database.
+// Like timelines, only one replica rebuilds a list at a time.
+func (t Timeline) cacheMentions(ctx context.Context, userID int64) {
+
+	cacheKey := t.getMentionsCacheKey(userID)
+
+	lockToken, locked := t.acquireRebuildLock(ctx, cacheKey)
+	if !locked {
+		return
+	}
+	defer t.releaseRebuildLock(ctx, cacheKey, lockToken)
+
+	mentions, err := t.mentionRepository.SelectMentions(ctx, userID, 0, MaxCachedMentions, nil)
+	if err != nil {
+		log.Printf("Failed to read mentions of user %d: %v", userID, err)
+		return
+	}
+
+	if err := t.cache.ReplaceList(ctx, cacheKey, mentionEntries(mentions), 0, CacheExpiration); err != nil {
+		log.Printf("Failed to cache mentions of user %d: %v", userID, err)
+	}
+}
+
+// PushMentions adds a new tweet to the cached mentions of the users it mentions.
+// Lists that are not cached are left alone: they are built from the database on the next read.
+func (t Timeline) PushMentions(ctx context.Context, tweet domain.Tweet) error {
+
+	userIDs, err := t.selectMentionedUserIDs(ctx, tweet)
+	if err != nil {
+		return err
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	cacheKeys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		cacheKeys[i] = t.getMentionsCacheKey(userID)
+	}
+
+	entry := formatMentionEntry(domain.Mention{TweetID: tweet.ID, AuthorID: tweet.UserID})
+	if err := t.cache.PushCappedIfExists(ctx, cacheKeys, entry, MaxCachedMentions, 0); err != nil {
+		return fmt.Errorf("failed to add tweet %d to mentions: %w", tweet.ID, err)
+	}
+
+	return nil
+}
+
+// InvalidateMentions drops the cached mentions of the users mentioned before or after a
+// tweet was edited, so the tweet shows up, or disappears, in the right place.
+func (t Timeline) InvalidateMentions(ctx context.Context, previous, current domain.Tweet) error {
+
+	previousIDs, err := t.selectMentionedUserIDs(ctx, previous)
+	if err != nil {
+		return err
+	}
+
+	currentIDs, err := t.selectMentionedUserIDs(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range uniqueIDs(append(previousIDs, currentIDs...)) {
+		if err := t.cache.Delete(ctx, t.getMentionsCacheKey(userID)); err != nil {
+			return fmt.Errorf("failed to invalidate mentions of user %d: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// selectMentionedUserIDs returns the users a tweet mentions, except its author.
+func (t Timeline) selectMentionedUserIDs(ctx context.Context, tweet domain.Tweet) ([]int64, error) {
+
+	usernames := tweet.Mentions()
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	userIDs, err := t.mentionRepository.SelectUserIDsByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions of tweet %d: %w", tweet.ID, err)
+	}
+
+	mentionedIDs := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != tweet.UserID {
+			mentionedIDs = append(mentionedIDs, userID)
+		}
+	}
+
+	return mentionedIDs, nil
+}
+
+// getInvisibleAuthorIDs returns the authors of the tweets that are protected accounts
+// viewerID doesn't follow.
+func (t Timeline) getInvisibleAuthorIDs(ctx context.Context, viewerID int64, tweets []domain.Tweet) ([]int64, error) {
+
+	authors, err := t.HydrateAuthors(ctx, tweets)
+	if err != nil {
+		return nil, err
+	}
+
+	var invisibleIDs []int64
+	for _, author := range authors {
+		if !author.Protected {
+			continue
+		}
+
+		visible, err := canViewTweetsOf(ctx, t.followerRepository, viewerID, author)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			invisibleIDs = append(invisibleIDs, author.ID)
+		}
+	}
+
+	return invisibleIDs, nil
+}
+
+// getMentionsCacheKey constructs the cache key for a user's mentions.
+func (t Timeline) getMentionsCacheKey(userID int64) string {
+	return fmt.Sprintf(MentionsCacheKey, userID)
+}
+
+// mentionEntries converts mentions to cached list entries, followed by the end marker
+// when they are all the mentions of the user.
+func mentionEntries(mentions []domain.Mention) []interface{} {
+
+	entries := make([]interface{}, 0, len(mentions)+1)
+	for _, mention := range mentions {
+		entries = append(entries, formatMentionEntry(mention))
+	}
+
+	if len(mentions) < MaxCachedMentions {
+		entries = append(entries, TimelineEndMarker)
+	}
+
+	return entries
+}
+
+// parseCachedMentions reads up to limit mentions older than cursor (0 for the newest)
+// from a cached list, skipping the ones written by hidden authors. It reports whether
+// the list ended with the end marker.
+func parseCachedMentions(entries []string, limit int, cursor int64, hidden map[int64]bool) ([]domain.Mention, bool) {
+
+	mentions := make([]domain.Mention, 0, limit)
+	for _, entry := range entries {
+		if entry == TimelineEndMarker {
+			return mentions, true
+		}
+
+		mention, ok := parseMentionEntry(entry)
+		if !ok || (cursor != 0 && mention.TweetID >= cursor) || hidden[mention.AuthorID] {
+			continue
+		}
+
+		mentions = append(mentions, mention)
+		if len(mentions) == limit {
+			return mentions, false
+		}
+	}
+
+	return mentions, false
+}
+
+// formatMentionEntry encodes a mention with the author of its tweet, e.g. "42:7".
+func formatMentionEntry(mention domain.Mention) string {
+	return strconv.FormatInt(mention.TweetID, 10) + ":" + strconv.FormatInt(mention.AuthorID, 10)
+}
+
+// parseMentionEntry decodes an entry written by formatMentionEntry.
+func parseMentionEntry(entry string) (domain.Mention, bool) {
+
+	tweetPart, authorPart, found := strings.Cut(entry, ":")
+	if !found {
+		return domain.Mention{}, false
+	}
+
+	tweetID, err := strconv.ParseInt(tweetPart, 10, 64)
+	if err != nil {
+		return domain.Mention{}, false
+	}
+
+	authorID, err := strconv.ParseInt(authorPart, 10, 64)
+	if err != nil {
+		return domain.Mention{}, false
+	}
+
+	return domain.Mention{TweetID: tweetID, AuthorID: authorID}, true
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"testing"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTweetMentions(t *testing.T) {
+	tweet := domain.Tweet{Content: "@alice thanks! cc @bob, @alice and mail@example.com"}
+
+	assert.Equal(t, []string{"alice", "bob"}, tweet.Mentions())
+	assert.Empty(t, domain.Tweet{Content: "no mentions here"}.Mentions())
+}
+
+func TestParseCachedMentions(t *testing.T) {
+	entries := []string{"9:1", "8:2", "7:1", TimelineEndMarker}
+
+	// Full page followed by more mentions
+	mentions, complete := parseCachedMentions(entries, 2, 0, nil)
+	assert.Equal(t, []domain.Mention{{TweetID: 9, AuthorID: 1}, {TweetID: 8, AuthorID: 2}}, mentions)
+	assert.False(t, complete)
+
+	// Next page, reaching the end marker
+	mentions, complete = parseCachedMentions(entries, 2, 8, nil)
+	assert.Equal(t, []domain.Mention{{TweetID: 7, AuthorID: 1}}, mentions)
+	assert.True(t, complete)
+
+	// Hidden authors are skipped
+	mentions, _ = parseCachedMentions(entries, 2, 0, map[int64]bool{1: true})
+	assert.Equal(t, []domain.Mention{{TweetID: 8, AuthorID: 2}}, mentions)
+}
+
+func TestMentionEntries(t *testing.T) {
+	assert.Equal(t, []interface{}{"9:1", TimelineEndMarker}, mentionEntries([]domain.Mention{{TweetID: 9, AuthorID: 1}}))
+
+	// A full window has no room for the marker
+	full := make([]domain.Mention, MaxCachedMentions)
+	assert.Len(t, mentionEntries(full), MaxCachedMentions)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/pkg"
+)
+
+type MuteUsecase interface {
+	MuteUser(ctx context.Context, muterID, mutedID int64) (domain.Mute, error)
+	UnmuteUser(ctx context.Context, muterID, mutedID int64) error
+	GetMutes(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Mute, int64, error)
+}
+
+type Mute struct {
+	muteRepository repository.MuteRepository
+	userRepository repository.UserRepository
+	producer       pkg.Producer
+}
+
+func NewMute(muteRepository repository.MuteRepository, userRepository repository.UserRepository, producer pkg.Producer) Mute {
+	return Mute{
+		muteRepository: muteRepository,
+		userRepository: userRepository,
+		producer:       producer,
+	}
+}
+
+// MuteUser hides the tweets of a user from the muter's timeline without unfollowing.
+// The muted user is not affected in any way.
+func (m Mute) MuteUser(ctx context.Context, muterID, mutedID int64) (domain.Mute, error) {
+
+	// Validate that muter ID and muted ID are different
+	if muterID == mutedID {
+		return domain.Mute{}, fmt.Errorf("cannot mute yourself")
+	}
+
+	if err := checkUsersExist(ctx, m.userRepository, muterID, mutedID); err != nil {
+		return domain.Mute{}, err
+	}
+
+	// Check if mute already exists
+	existingMute, err := m.muteRepository.SelectByMuterAndMuted(ctx, muterID, mutedID)
+	if err != nil {
+		return domain.Mute{}, err
+	}
+	if existingMute.ID != 0 {
+		return domain.Mute{}, fmt.Errorf("already muting this user")
+	}
+
+	newMute, err := m.muteRepository.Insert(ctx, domain.Mute{
+		MuterID: muterID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		return domain.Mute{}, err
+	}
+
+	go m.publishMuteEvent(dto.UserMutedEvent, muterID, mutedID)
+
+	return newMute, nil
+}
+
+func (m Mute) UnmuteUser(ctx context.Context, muterID, mutedID int64) error {
+
+	// Check if mute exists
+	existingMute, err := m.muteRepository.SelectByMuterAndMuted(ctx, muterID, mutedID)
+	if err != nil {
+		return err
+	}
+	if existingMute.ID == 0 {
+		return fmt.Errorf("not muting this user")
+	}
+
+	if err := m.muteRepository.Delete(ctx, muterID, mutedID); err != nil {
+		return err
+	}
+
+	go m.publishMuteEvent(dto.UserUnmutedEvent, muterID, mutedID)
+
+	return nil
+}
+
+// GetMutes returns the mutes made by a user, most recent first, and the cursor
+// of the next page (0 when there are no more).
+func (m Mute) GetMutes(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Mute, int64, error) {
+
+	limit, cursor = normalizePage(limit, cursor)
+
+	mutes, err := m.muteRepository.SelectMutesPage(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor int64
+	if len(mutes) == limit {
+		nextCursor = mutes[len(mutes)-1].ID
+	}
+
+	return mutes, nextCursor, nil
+}
+
+func (m Mute) publishMuteEvent(eventType dto.EventType, muterID, mutedID int64) {
+	event := dto.NewEvent(eventType, dto.UserRelationshipEventData{
+		UserID:       muterID,
+		TargetUserID: mutedID,
+	})
+	publishEvent(m.producer, config.TopicFollows, fmt.Sprintf(config.KeyFormatUser, muterID), event)
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// stubMuteRepository holds at most one mute, and answers the mutes of a user
+// counting the queries when queries is set.
+type stubMuteRepository struct {
+	repository.MuteRepository
+	existing domain.Mute
+	inserted *[]domain.Mute
+	mutedIDs []int64
+	queries  *int
+}
+
+func (s stubMuteRepository) SelectByMuterAndMuted(ctx context.Context, muterID, mutedID int64) (domain.Mute, error) {
+	if s.existing.MuterID != muterID || s.existing.MutedID != mutedID {
+		return domain.Mute{}, nil
+	}
+	return s.existing, nil
+}
+
+func (s stubMuteRepository) Insert(ctx context.Context, mute domain.Mute) (domain.Mute, error) {
+	*s.inserted = append(*s.inserted, mute)
+	mute.ID = int64(len(*s.inserted))
+	return mute, nil
+}
+
+func (s stubMuteRepository) SelectMutedIDs(ctx context.Context, userID int64) ([]int64, error) {
+	if s.queries != nil {
+		*s.queries++
+	}
+	return s.mutedIDs, nil
+}
+
+func TestMute_MuteUser_Yourself(t *testing.T) {
+	// Arrange
+	var inserted []domain.Mute
+	muteUsecase := NewMute(stubMuteRepository{inserted: &inserted}, nil, nil)
+
+	// Act
+	_, err := muteUsecase.MuteUser(context.Background(), 1, 1)
+
+	// Assert
+	assert.EqualError(t, err, "cannot mute yourself")
+	assert.Empty(t, inserted)
+}
+
+func TestMute_MuteUser_AlreadyMuting(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	expectUsersExist(userRepository, 1, 2)
+
+	var inserted []domain.Mute
+	muteRepository := stubMuteRepository{existing: domain.Mute{ID: 5, MuterID: 1, MutedID: 2}, inserted: &inserted}
+	muteUsecase := NewMute(muteRepository, userRepository, nil)
+
+	// Act
+	_, err := muteUsecase.MuteUser(context.Background(), 1, 2)
+
+	// Assert
+	assert.EqualError(t, err, "already muting this user")
+	assert.Empty(t, inserted)
+}
+
+func TestTimeline_MutedAuthorsAreFiltered(t *testing.T) {
+	// Arrange: user 1 muted user 2, whose tweets are still in the invalidated timeline
+	ctx := context.Background()
+	cache := newStubCache()
+	timeline := Timeline{
+		cache:           cache,
+		muteRepository:  stubMuteRepository{mutedIDs: []int64{2}},
+		blockRepository: stubBlockRepository{},
+	}
+	cacheKey := timeline.getCacheKey(1)
+	cache.lists[cacheKey] = []string{"9", "8", "7", TimelineEndMarker}
+	cache.lists[timeline.getStaleCacheKey(cacheKey)] = cache.lists[cacheKey]
+	for _, tweet := range []domain.Tweet{{ID: 9, UserID: 3}, {ID: 8, UserID: 2}, {ID: 7, UserID: 3}} {
+		cache.values[timeline.getTweetCacheKey(tweet.ID)] = formatCachedTweet(tweet)
+	}
+
+	hiddenUserIDs, err := timeline.getHiddenUserIDs(ctx, 1)
+	assert.NoError(t, err)
+
+	// Act
+	_, hit, err := timeline.readCachedPage(ctx, cacheKey, 3, 0, hiddenUserIDs, 0)
+	assert.NoError(t, err)
+	stale, staleHit := timeline.readStaleTimeline(ctx, cacheKey, 3, 0, hiddenUserIDs, 0)
+
+	// Assert: the cached page is rebuilt rather than served, the stale one is served without them
+	assert.Equal(t, []int64{2}, hiddenUserIDs)
+	assert.False(t, hit)
+	assert.True(t, staleHit)
+	assert.Equal(t, []int64{9, 7}, tweetIDsOf(stale))
+}
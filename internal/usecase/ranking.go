@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+)
+
+const (
+	// RankingSnapshotKey defines the key of a ranked timeline, paginated until it expires
+	RankingSnapshotKey = "ranking:user:%d:%s"
+)
+
+// ErrRankingSnapshotExpired is returned when the next page of a ranked timeline is asked
+// for a ranking that expired. The client starts over from the first page.
+var ErrRankingSnapshotExpired = errors.New("ranking snapshot expired")
+
+// TimelineScorer scores the candidates of a ranked timeline, higher first.
+type TimelineScorer interface {
+	Score(candidate domain.RankingCandidate, now time.Time) float64
+}
+
+// WeightedScorer decays tweets with age and boosts them with their engagement, the
+// viewer's affinity with the author and the followed users who engaged with them.
+// Tweets from outside the viewer's network are weighted down.
+type WeightedScorer struct {
+	config config.RankingConfig
+}
+
+func NewWeightedScorer(rankingConfig config.RankingConfig) WeightedScorer {
+	return WeightedScorer{
+		config: rankingConfig,
+	}
+}
+
+func (s WeightedScorer) Score(candidate domain.RankingCandidate, now time.Time) float64 {
+
+	age := max(now.Sub(candidate.Tweet.CreatedAt), 0)
+	recency := math.Exp2(-age.Hours() / s.config.HalfLife.Hours())
+
+	engagement := float64(candidate.Engagement.Replies + candidate.Engagement.Retweets)
+	boost := 1 +
+		s.config.EngagementWeight*math.Log1p(engagement) +
+		s.config.AffinityWeight*math.Log1p(float64(candidate.Affinity)) +
+		s.config.SocialProofWeight*math.Log1p(float64(candidate.SocialProof))
+
+	score := recency * boost
+	if !candidate.InNetwork {
+		score *= s.config.OutOfNetworkWeight
+	}
+
+	return score
+}
+
+// ChronologicalScorer ranks the newest tweets first.
+type ChronologicalScorer struct{}
+
+func (ChronologicalScorer) Score(candidate domain.RankingCandidate, now time.Time) float64 {
+	return float64(candidate.Tweet.ID)
+}
+
+// GetRankedTimeline returns a page of the ranked ("For You") timeline of a user: the
+// newest tweets of their timeline plus tweets their network engaged with, scored by the
+// configured TimelineScorer. The first page (empty snapshotID) ranks the candidates and
+// stores the ranking; the next pages are read from it by its snapshot ID, so tweets don't
+// move between pages while the client scrolls.
+func (t Timeline) GetRankedTimeline(ctx context.Context, userID int64, limit, offset int, snapshotID string, excludeSelf bool) ([]domain.Tweet, string, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	hiddenUserIDs, err := t.getHiddenUserIDs(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if excludeSelf {
+		hiddenUserIDs = append(hiddenUserIDs, userID)
+	}
+
+	if snapshotID == "" {
+		t.recordActivity(ctx, userID)
+
+		snapshotID, err = t.rankTimeline(ctx, userID, hiddenUserIDs)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	tweetIDs, complete, err := t.retrieveCachedPage(ctx, t.getRankingSnapshotKey(userID, snapshotID), limit, offset)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Every snapshot ends with the end marker, not finding it means it expired
+	if len(tweetIDs) == 0 && !complete {
+		return nil, "", ErrRankingSnapshotExpired
+	}
+
+	tweets, err := t.hydrateTweets(ctx, tweetIDs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Users muted or blocked since the ranking was made are left out
+	return removeHiddenAuthors(tweets, hiddenUserIDs), snapshotID, nil
+}
+
+// rankTimeline collects and scores the candidates of a user's ranked timeline and stores
+// the ranking, returning its snapshot ID.
+func (t Timeline) rankTimeline(ctx context.Context, userID int64, hiddenUserIDs []int64) (string, error) {
+
+	now := time.Now()
+
+	candidates, err := t.collectRankingCandidates(ctx, userID, hiddenUserIDs, now)
+	if err != nil {
+		return "", err
+	}
+
+	tweetIDs := rankCandidates(candidates, t.scorer, now)
+
+	snapshotID := strconv.FormatUint(rand.Uint64(), 36)
+	snapshotKey := t.getRankingSnapshotKey(userID, snapshotID)
+
+	// The whole ranking is stored, so it always ends with the end marker
+	entries := make([]interface{}, 0, len(tweetIDs)+1)
+	for _, id := range tweetIDs {
+		entries = append(entries, strconv.FormatInt(id, 10))
+	}
+	entries = append(entries, TimelineEndMarker)
+
+	if err := t.cache.ReplaceList(ctx, snapshotKey, entries, 0, t.rankingConfig.SnapshotExpiration); err != nil {
+		return "", fmt.Errorf("failed to store ranking of user %d: %w", userID, err)
+	}
+
+	return snapshotID, nil
+}
+
+// collectRankingCandidates gathers the tweets that may appear in a ranked timeline: the
+// newest tweets of the user's timeline, read from its cache when there, and the tweets
+// written outside their network that the users they follow replied to or retweeted.
+func (t Timeline) collectRankingCandidates(ctx context.Context, userID int64, hiddenUserIDs []int64, now time.Time) ([]domain.RankingCandidate, error) {
+
+	inNetworkIDs, complete, err := t.retrieveCachedPage(ctx, t.getCacheKey(userID), t.rankingConfig.InNetworkCandidates, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// A list only filled by the fan-out holds too few tweets to rank
+	if len(inNetworkIDs) < t.rankingConfig.InNetworkCandidates && !complete {
+		inNetworkIDs, err = t.tweetRepository.SelectTimelineTweetIDs(ctx, userID, t.rankingConfig.InNetworkCandidates, hiddenUserIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	socialProof, err := t.engagementRepository.SelectNetworkEngagedTweetIDs(ctx, userID, now.Add(-t.rankingConfig.OutOfNetworkWindow), t.rankingConfig.OutOfNetworkCandidates, hiddenUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateIDs := slices.Clone(inNetworkIDs)
+	for tweetID := range socialProof {
+		candidateIDs = append(candidateIDs, tweetID)
+	}
+	candidateIDs = uniqueIDs(candidateIDs)
+
+	tweets, err := t.hydrateTweets(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached timeline may still hold tweets of users hidden since it was written
+	tweets = removeHiddenAuthors(tweets, hiddenUserIDs)
+
+	engagement, err := t.engagementRepository.SelectEngagementCounts(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	affinity, err := t.engagementRepository.SelectInteractionCounts(ctx, userID, now.Add(-t.rankingConfig.InteractionWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	inNetwork := make(map[int64]bool, len(inNetworkIDs))
+	for _, id := range inNetworkIDs {
+		inNetwork[id] = true
+	}
+
+	candidates := make([]domain.RankingCandidate, 0, len(tweets))
+	for _, tweet := range tweets {
+		candidates = append(candidates, domain.RankingCandidate{
+			Tweet:       tweet,
+			InNetwork:   inNetwork[tweet.ID],
+			Engagement:  engagement[tweet.ID],
+			Affinity:    affinity[tweet.UserID],
+			SocialProof: socialProof[tweet.ID],
+		})
+	}
+
+	return candidates, nil
+}
+
+// rankCandidates scores the candidates and returns their tweet IDs, best first.
+// Ties go to the newest tweet so a ranking is deterministic.
+func rankCandidates(candidates []domain.RankingCandidate, scorer TimelineScorer, now time.Time) []int64 {
+
+	scores := make(map[int64]float64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.Tweet.ID] = scorer.Score(candidate, now)
+	}
+
+	tweetIDs := make([]int64, 0, len(candidates))
+	for _, candidate := range candidates {
+		tweetIDs = append(tweetIDs, candidate.Tweet.ID)
+	}
+
+	sort.Slice(tweetIDs, func(i, j int) bool {
+		if scores[tweetIDs[i]] != scores[tweetIDs[j]] {
+			return scores[tweetIDs[i]] > scores[tweetIDs[j]]
+		}
+		return tweetIDs[i] > tweetIDs[j]
+	})
+
+	return tweetIDs
+}
+
+// getRankingSnapshotKey constructs the cache key for a ranked timeline.
+func (t Timeline) getRankingSnapshotKey(userID int64, snapshotID string) string {
+	return fmt.Sprintf(RankingSnapshotKey, userID, snapshotID)
+}
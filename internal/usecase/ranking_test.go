@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRankingConfig() config.RankingConfig {
+	return config.RankingConfig{
+		HalfLife:           6 * time.Hour,
+		EngagementWeight:   1,
+		AffinityWeight:     0.5,
+		SocialProofWeight:  0.5,
+		OutOfNetworkWeight: 0.5,
+	}
+}
+
+func TestWeightedScorer(t *testing.T) {
+	// Arrange
+	scorer := NewWeightedScorer(testRankingConfig())
+	now := time.Now()
+	fresh := domain.RankingCandidate{Tweet: domain.Tweet{ID: 1, CreatedAt: now}, InNetwork: true}
+	old := domain.RankingCandidate{Tweet: domain.Tweet{ID: 2, CreatedAt: now.Add(-6 * time.Hour)}, InNetwork: true}
+	engaged := fresh
+	engaged.Engagement = domain.TweetEngagement{Replies: 3, Retweets: 2}
+	outOfNetwork := fresh
+	outOfNetwork.InNetwork = false
+
+	// Act & Assert
+	assert.InDelta(t, 1, scorer.Score(fresh, now), 1e-9)
+	assert.InDelta(t, 0.5, scorer.Score(old, now), 1e-9) // One half-life
+	assert.Greater(t, scorer.Score(engaged, now), scorer.Score(fresh, now))
+	assert.InDelta(t, 0.5, scorer.Score(outOfNetwork, now), 1e-9)
+}
+
+func TestRankCandidates(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	candidates := []domain.RankingCandidate{
+		{Tweet: domain.Tweet{ID: 1, CreatedAt: now.Add(-time.Hour)}, InNetwork: true},
+		{Tweet: domain.Tweet{ID: 2, CreatedAt: now.Add(-2 * time.Hour)}, InNetwork: true, Affinity: 20},
+		{Tweet: domain.Tweet{ID: 3, CreatedAt: now.Add(-time.Hour)}, InNetwork: true},
+	}
+
+	// Act
+	ranked := rankCandidates(candidates, NewWeightedScorer(testRankingConfig()), now)
+	chronological := rankCandidates(candidates, ChronologicalScorer{}, now)
+
+	// Assert
+	assert.Equal(t, []int64{2, 3, 1}, ranked) // Ties go to the newest tweet
+	assert.Equal(t, []int64{3, 2, 1}, chronological)
+}
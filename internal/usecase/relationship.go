@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+// MaxRelationshipTargets is how many targets can be checked in a single request
+const MaxRelationshipTargets = 100
+
+type RelationshipUsecase interface {
+	GetRelationships(ctx context.Context, sourceID int64, targetIDs []int64) ([]domain.Relationship, error)
+}
+
+type Relationship struct {
+	relationshipRepository repository.RelationshipRepository
+	userRepository         repository.UserRepository
+}
+
+func NewRelationship(relationshipRepository repository.RelationshipRepository, userRepository repository.UserRepository) Relationship {
+	return Relationship{
+		relationshipRepository: relationshipRepository,
+		userRepository:         userRepository,
+	}
+}
+
+// GetRelationships returns how sourceID relates to each target, so clients can render
+// "Follows you", "Following" or "Blocked" badges. Repeated targets are returned once.
+func (r Relationship) GetRelationships(ctx context.Context, sourceID int64, targetIDs []int64) ([]domain.Relationship, error) {
+
+	targetIDs = uniqueIDs(targetIDs)
+	if len(targetIDs) == 0 {
+		return nil, fmt.Errorf("at least one target is required")
+	}
+
+	if len(targetIDs) > MaxRelationshipTargets {
+		return nil, fmt.Errorf("at most %d targets can be checked at once", MaxRelationshipTargets)
+	}
+
+	// Check if source user exists
+	sourceUser, err := r.userRepository.SelectByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if sourceUser.ID == 0 {
+		return nil, fmt.Errorf("source user not found")
+	}
+
+	return r.relationshipRepository.SelectRelationships(ctx, sourceID, targetIDs)
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence of each.
+func uniqueIDs(ids []int64) []int64 {
+
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	return unique
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+type ScheduledTweetUsecase interface {
+	ScheduleTweet(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.ScheduledTweet, error)
+	GetScheduledTweets(ctx context.Context, userID int64, status string) ([]domain.ScheduledTweet, error)
+	CancelScheduledTweet(ctx context.Context, id, userID int64) error
+	PublishDueTweets(ctx context.Context) (int, error)
+}
+
+type ScheduledTweet struct {
+	scheduledTweetRepository repository.ScheduledTweetRepository
+	userRepository           repository.UserRepository
+	tweetUsecase             TweetUsecase
+	schedulerConfig          config.SchedulerConfig
+}
+
+func NewScheduledTweet(scheduledTweetRepository repository.ScheduledTweetRepository, userRepository repository.UserRepository, tweetUsecase TweetUsecase, schedulerConfig config.SchedulerConfig) ScheduledTweet {
+	return ScheduledTweet{
+		scheduledTweetRepository: scheduledTweetRepository,
+		userRepository:           userRepository,
+		tweetUsecase:             tweetUsecase,
+		schedulerConfig:          schedulerConfig,
+	}
+}
+
+func (s ScheduledTweet) ScheduleTweet(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.ScheduledTweet, error) {
+
+	// Validate content with the same rules used when publishing
+	if _, err := s.tweetUsecase.ValidateContent(scheduledTweet.Content); err != nil {
+		return domain.ScheduledTweet{}, err
+	}
+
+	// Validate publish time
+	now := time.Now()
+	if !scheduledTweet.PublishAt.After(now) {
+		return domain.ScheduledTweet{}, fmt.Errorf("publish time must be in the future")
+	}
+
+	if scheduledTweet.PublishAt.After(now.Add(s.schedulerConfig.MaxHorizon)) {
+		return domain.ScheduledTweet{}, fmt.Errorf("publish time is too far in the future")
+	}
+
+	// Check if user exists
+	existingUser, err := s.userRepository.SelectByID(ctx, scheduledTweet.UserID)
+	if err != nil {
+		return domain.ScheduledTweet{}, err
+	}
+	if existingUser.ID == 0 {
+		return domain.ScheduledTweet{}, fmt.Errorf("user not found")
+	}
+
+	return s.scheduledTweetRepository.Insert(ctx, scheduledTweet)
+}
+
+func (s ScheduledTweet) GetScheduledTweets(ctx context.Context, userID int64, status string) ([]domain.ScheduledTweet, error) {
+	return s.scheduledTweetRepository.SelectByUserID(ctx, userID, status)
+}
+
+func (s ScheduledTweet) CancelScheduledTweet(ctx context.Context, id, userID int64) error {
+
+	// Check if scheduled tweet exists and belongs to the user
+	existingScheduledTweet, err := s.scheduledTweetRepository.SelectByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existingScheduledTweet.ID == 0 || existingScheduledTweet.UserID != userID {
+		return fmt.Errorf("scheduled tweet not found")
+	}
+
+	return s.scheduledTweetRepository.Cancel(ctx, id)
+}
+
+// PublishDueTweets claims the tweets whose publish time has passed and publishes
+// them through TweetUsecase.PublishScheduledTweet, so fan-out and events behave exactly
+// like a tweet posted directly. It returns the number of tweets published.
+func (s ScheduledTweet) PublishDueTweets(ctx context.Context) (int, error) {
+
+	dueTweets, err := s.scheduledTweetRepository.ClaimDue(ctx, s.schedulerConfig.BatchSize, s.schedulerConfig.LeaseTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due tweets: %w", err)
+	}
+
+	published := 0
+	for _, dueTweet := range dueTweets {
+		tweet, err := s.tweetUsecase.PublishScheduledTweet(ctx, dueTweet)
+		if err != nil {
+			log.Printf("Failed to publish scheduled tweet %d: %v", dueTweet.ID, err)
+			if err := s.scheduledTweetRepository.MarkFailed(ctx, dueTweet.ID, err.Error()); err != nil {
+				log.Printf("Failed to mark scheduled tweet %d as failed: %v", dueTweet.ID, err)
+			}
+			continue
+		}
+
+		// Another worker reclaimed it after our lease expired and published it first
+		if tweet.ID == 0 {
+			log.Printf("Scheduled tweet %d was already published", dueTweet.ID)
+			continue
+		}
+
+		published++
+	}
+
+	return published, nil
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// stubScheduledTweetRepository records the scheduled tweets inserted.
+type stubScheduledTweetRepository struct {
+	repository.ScheduledTweetRepository
+	inserted *[]domain.ScheduledTweet
+}
+
+func (s stubScheduledTweetRepository) Insert(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.ScheduledTweet, error) {
+	*s.inserted = append(*s.inserted, scheduledTweet)
+	scheduledTweet.ID = int64(len(*s.inserted))
+	return scheduledTweet, nil
+}
+
+func TestScheduledTweet_ScheduleTweet_PublishTime(t *testing.T) {
+	schedulerConfig := config.SchedulerConfig{MaxHorizon: 24 * time.Hour}
+	tweetUsecase := NewTweet(nil, nil, nil, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	tests := []struct {
+		name      string
+		publishAt time.Time
+		err       string
+	}{
+		{name: "in the past", publishAt: time.Now().Add(-time.Minute), err: "publish time must be in the future"},
+		{name: "beyond the horizon", publishAt: time.Now().Add(25 * time.Hour), err: "publish time is too far in the future"},
+		{name: "within the horizon", publishAt: time.Now().Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mocks.NewMockUserRepository(ctrl)
+			expectUsersExist(userRepository, 1)
+
+			var inserted []domain.ScheduledTweet
+			scheduledTweetUsecase := NewScheduledTweet(stubScheduledTweetRepository{inserted: &inserted}, userRepository, tweetUsecase, schedulerConfig)
+
+			// Act
+			scheduledTweet, err := scheduledTweetUsecase.ScheduleTweet(context.Background(), domain.ScheduledTweet{UserID: 1, Content: "hello", PublishAt: tt.publishAt})
+
+			// Assert: a refused tweet is never stored
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Empty(t, inserted)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), scheduledTweet.ID)
+			assert.Len(t, inserted, 1)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+// searchDateLayout is the format of the since: and until: operators.
+const searchDateLayout = "2006-01-02"
+
+var (
+	// ErrInvalidSearchQuery is returned for a query with nothing to search for or a malformed operator.
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+	// ErrInvalidSearchCursor is returned for a cursor that was not returned by a search.
+	ErrInvalidSearchCursor = errors.New("invalid search cursor")
+)
+
+// searchTokenPattern splits a query into "quoted phrases" and words.
+var searchTokenPattern = regexp.MustCompile(`"[^"]*"|\S+`)
+
+// hashtagPattern matches a hashtag operator, e.g. #golang.
+var hashtagPattern = regexp.MustCompile(`^#(\w+)$`)
+
+type SearchUsecase interface {
+	SearchTweets(ctx context.Context, q string, viewerID int64, sort string, sinceID int64, cursor string, limit int) ([]domain.Tweet, string, error)
+}
+
+type Search struct {
+	searchRepository repository.SearchRepository
+	muteRepository   repository.MuteRepository
+	blockRepository  repository.BlockRepository
+}
+
+func NewSearch(searchRepository repository.SearchRepository, muteRepository repository.MuteRepository, blockRepository repository.BlockRepository) Search {
+	return Search{
+		searchRepository: searchRepository,
+		muteRepository:   muteRepository,
+		blockRepository:  blockRepository,
+	}
+}
+
+// SearchTweets returns the tweets matching q, best matches or newest first, and the cursor
+// of the next page ("" at the end). See ParseSearchQuery for the query syntax. Tweets of
+// users viewerID muted or blocked, or who blocked them, and of protected accounts they
+// don't follow are left out. A viewerID of 0 is an anonymous viewer.
+func (s Search) SearchTweets(ctx context.Context, q string, viewerID int64, sort string, sinceID int64, cursor string, limit int) ([]domain.Tweet, string, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	query, err := ParseSearchQuery(q)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query.SinceID = sinceID
+	query.Sort = domain.SearchSortRelevance
+	if sort == domain.SearchSortRecent {
+		query.Sort = domain.SearchSortRecent
+	}
+
+	position, err := parseSearchCursor(cursor, query.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var hiddenUserIDs []int64
+	if viewerID != 0 {
+		mutedIDs, err := s.muteRepository.SelectMutedIDs(ctx, viewerID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		blockedIDs, err := s.blockRepository.SelectBlockedOrBlockingIDs(ctx, viewerID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		hiddenUserIDs = append(mutedIDs, blockedIDs...)
+	}
+
+	results, err := s.searchRepository.SearchTweets(ctx, query, viewerID, hiddenUserIDs, position, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tweets := make([]domain.Tweet, len(results))
+	for i, result := range results {
+		tweets[i] = result.Tweet
+	}
+
+	var nextCursor string
+	if len(results) == limit {
+		last := results[len(results)-1]
+		nextCursor = formatSearchCursor(domain.SearchCursor{TweetID: last.Tweet.ID, Rank: last.Rank}, query.Sort)
+	}
+
+	return tweets, nextCursor, nil
+}
+
+// ParseSearchQuery parses a search query. Besides words, it supports:
+//   - "quoted phrases", matched as a whole
+//   - #hashtag, matching the hashtag only
+//   - from:username (or from:@username), tweets written by the user
+//   - since:YYYY-MM-DD and until:YYYY-MM-DD, tweets published from the first day and
+//     before the second one
+func ParseSearchQuery(q string) (domain.SearchQuery, error) {
+
+	var query domain.SearchQuery
+	var text []string
+
+	for _, token := range searchTokenPattern.FindAllString(q, -1) {
+		operator, value, _ := strings.Cut(token, ":")
+
+		switch {
+		case strings.HasPrefix(token, `"`):
+			if phrase := strings.TrimSpace(strings.Trim(token, `"`)); phrase != "" {
+				text = append(text, `"`+phrase+`"`)
+			}
+
+		case hashtagPattern.MatchString(token):
+			hashtag := hashtagPattern.FindStringSubmatch(token)[1]
+			query.Hashtags = append(query.Hashtags, hashtag)
+			text = append(text, hashtag)
+
+		case operator == "from" && value != "":
+			query.From = strings.TrimPrefix(value, "@")
+
+		case operator == "since" || operator == "until":
+			date, err := time.Parse(searchDateLayout, value)
+			if err != nil {
+				return domain.SearchQuery{}, fmt.Errorf("%w: %s expects a date as YYYY-MM-DD", ErrInvalidSearchQuery, operator)
+			}
+			if operator == "since" {
+				query.Since = date
+			} else {
+				query.Until = date
+			}
+
+		default:
+			// A stray quote would start a phrase in the text search
+			if word := strings.Trim(token, `"`); word != "" {
+				text = append(text, word)
+			}
+		}
+	}
+
+	query.Text = strings.Join(text, " ")
+
+	if query.Empty() {
+		return domain.SearchQuery{}, fmt.Errorf("%w: nothing to search for", ErrInvalidSearchQuery)
+	}
+
+	return query, nil
+}
+
+// formatSearchCursor encodes the position of the last result of a page: its tweet ID for
+// searches sorted by recency, its rank and tweet ID for searches sorted by relevance.
+func formatSearchCursor(cursor domain.SearchCursor, sort string) string {
+
+	id := strconv.FormatInt(cursor.TweetID, 10)
+	if sort != domain.SearchSortRelevance {
+		return id
+	}
+
+	return strconv.FormatFloat(float64(cursor.Rank), 'g', -1, 32) + "_" + id
+}
+
+// parseSearchCursor decodes a cursor written by formatSearchCursor. An empty cursor is
+// the first page.
+func parseSearchCursor(cursor, sort string) (domain.SearchCursor, error) {
+
+	if cursor == "" {
+		return domain.SearchCursor{}, nil
+	}
+
+	idPart := cursor
+	var rank float64
+	if sort == domain.SearchSortRelevance {
+		rankPart, rest, found := strings.Cut(cursor, "_")
+		if !found {
+			return domain.SearchCursor{}, ErrInvalidSearchCursor
+		}
+
+		var err error
+		rank, err = strconv.ParseFloat(rankPart, 32)
+		if err != nil {
+			return domain.SearchCursor{}, ErrInvalidSearchCursor
+		}
+		idPart = rest
+	}
+
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id <= 0 {
+		return domain.SearchCursor{}, ErrInvalidSearchCursor
+	}
+
+	return domain.SearchCursor{TweetID: id, Rank: float32(rank)}, nil
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+// SearchIndexUsecase keeps the inverted index of the search service up to date and searches it.
+type SearchIndexUsecase interface {
+	SearchTweets(ctx context.Context, q string, viewerID int64, limit, offset int) ([]domain.IndexHit, int, error)
+	IndexTweet(ctx context.Context, tweet domain.IndexedTweet) error
+	DeleteTweet(ctx context.Context, tweetID int64) error
+	SetAuthorProtected(ctx context.Context, userID int64, protected bool) error
+	FlushIndex(ctx context.Context) error
+	Reindex(ctx context.Context) (int, error)
+	GetIndexStats(ctx context.Context) (domain.IndexStats, error)
+}
+
+type SearchIndex struct {
+	searchIndex        repository.SearchIndex
+	tweetRepository    repository.TweetRepository
+	userRepository     repository.UserRepository
+	followerRepository repository.FollowerRepository
+	muteRepository     repository.MuteRepository
+	blockRepository    repository.BlockRepository
+	config             config.SearchIndexConfig
+}
+
+func NewSearchIndex(searchIndex repository.SearchIndex, tweetRepository repository.TweetRepository, userRepository repository.UserRepository, followerRepository repository.FollowerRepository, muteRepository repository.MuteRepository, blockRepository repository.BlockRepository, config config.SearchIndexConfig) SearchIndex {
+	return SearchIndex{
+		searchIndex:        searchIndex,
+		tweetRepository:    tweetRepository,
+		userRepository:     userRepository,
+		followerRepository: followerRepository,
+		muteRepository:     muteRepository,
+		blockRepository:    blockRepository,
+		config:             config,
+	}
+}
+
+// SearchTweets returns a page of the tweets matching any word of q, best BM25 score first,
+// and how many tweets match. Words are matched by their stem, so "running" finds "runs".
+// Tweets of users viewerID muted or blocked, or who blocked them, are left out, and so
+// are the tweets of protected accounts they don't follow. A viewerID of 0 is an anonymous viewer.
+func (s SearchIndex) SearchTweets(ctx context.Context, q string, viewerID int64, limit, offset int) ([]domain.IndexHit, int, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if len(repository.Analyze(q)) == 0 {
+		return nil, 0, fmt.Errorf("%w: nothing to search for", ErrInvalidSearchQuery)
+	}
+
+	var hiddenUserIDs []int64
+	if viewerID != 0 {
+		mutedIDs, err := s.muteRepository.SelectMutedIDs(ctx, viewerID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		blockedIDs, err := s.blockRepository.SelectBlockedOrBlockingIDs(ctx, viewerID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hiddenUserIDs = append(mutedIDs, blockedIDs...)
+	}
+
+	visibleProtectedIDs, err := s.getVisibleProtectedIDs(ctx, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.searchIndex.Search(ctx, q, hiddenUserIDs, visibleProtectedIDs, limit, offset)
+}
+
+// getVisibleProtectedIDs returns the protected accounts whose tweets viewerID can find:
+// the ones they follow, and their own.
+func (s SearchIndex) getVisibleProtectedIDs(ctx context.Context, viewerID int64) ([]int64, error) {
+
+	if viewerID == 0 {
+		return nil, nil
+	}
+
+	protectedIDs, err := s.searchIndex.ProtectedUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	followedIDs, err := s.followerRepository.SelectFollowedIDsAmong(ctx, viewerID, protectedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(followedIDs, viewerID), nil
+}
+
+// IndexTweet adds a new or edited tweet to the index.
+func (s SearchIndex) IndexTweet(ctx context.Context, tweet domain.IndexedTweet) error {
+	return s.searchIndex.Index(ctx, tweet)
+}
+
+// DeleteTweet removes a tweet from the index.
+func (s SearchIndex) DeleteTweet(ctx context.Context, tweetID int64) error {
+	return s.searchIndex.Delete(ctx, tweetID)
+}
+
+// SetAuthorProtected records whether a user is a protected account, whose tweets are only
+// found by their followers.
+func (s SearchIndex) SetAuthorProtected(ctx context.Context, userID int64, protected bool) error {
+	return s.searchIndex.SetProtected(ctx, protected, userID)
+}
+
+// FlushIndex writes the changes indexed since the last flush to disk.
+func (s SearchIndex) FlushIndex(ctx context.Context) error {
+	return s.searchIndex.Flush(ctx)
+}
+
+// Reindex empties the index and indexes every tweet read from the database, in ID order,
+// config.ReindexBatchSize at a time, after recording which users are protected. It
+// returns how many tweets were indexed.
+func (s SearchIndex) Reindex(ctx context.Context) (int, error) {
+
+	if err := s.searchIndex.Reset(ctx); err != nil {
+		return 0, fmt.Errorf("failed to reset index: %w", err)
+	}
+
+	if err := s.reindexProtectedAuthors(ctx); err != nil {
+		return 0, fmt.Errorf("failed to index protected users: %w", err)
+	}
+
+	indexed := 0
+	var afterID int64
+	for {
+		if ctx.Err() != nil {
+			return indexed, ctx.Err()
+		}
+
+		tweets, err := s.tweetRepository.SelectTweetsAfter(ctx, afterID, s.config.ReindexBatchSize)
+		if err != nil {
+			return indexed, err
+		}
+
+		if len(tweets) == 0 {
+			break
+		}
+
+		for _, tweet := range tweets {
+			if err := s.searchIndex.Index(ctx, toIndexedTweet(tweet)); err != nil {
+				return indexed, fmt.Errorf("failed to index tweet %d: %w", tweet.ID, err)
+			}
+			indexed++
+		}
+
+		afterID = tweets[len(tweets)-1].ID
+		log.Printf("Indexed %d tweets, up to tweet %d", indexed, afterID)
+	}
+
+	if err := s.searchIndex.Flush(ctx); err != nil {
+		return indexed, err
+	}
+
+	return indexed, nil
+}
+
+// reindexProtectedAuthors records every protected user read from the database, in ID
+// order, config.ReindexBatchSize at a time.
+func (s SearchIndex) reindexProtectedAuthors(ctx context.Context) error {
+
+	var afterID int64
+	for {
+		userIDs, err := s.userRepository.SelectIDsAfter(ctx, afterID, s.config.ReindexBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		users, err := s.userRepository.SelectByIDs(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		var protectedIDs []int64
+		for _, user := range users {
+			if user.Protected {
+				protectedIDs = append(protectedIDs, user.ID)
+			}
+		}
+
+		if err := s.searchIndex.SetProtected(ctx, true, protectedIDs...); err != nil {
+			return err
+		}
+
+		afterID = userIDs[len(userIDs)-1]
+	}
+}
+
+// GetIndexStats describes the index.
+func (s SearchIndex) GetIndexStats(ctx context.Context) (domain.IndexStats, error) {
+	return s.searchIndex.Stats(ctx)
+}
+
+// toIndexedTweet copies what the index keeps of a tweet.
+func toIndexedTweet(tweet domain.Tweet) domain.IndexedTweet {
+	return domain.IndexedTweet{
+		ID:        tweet.ID,
+		UserID:    tweet.UserID,
+		Content:   tweet.Content,
+		CreatedAt: tweet.CreatedAt,
+		UpdatedAt: tweet.UpdatedAt,
+	}
+}
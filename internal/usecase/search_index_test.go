@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"twitter-demo/internal/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSearchIndex only knows which authors are protected.
+type stubSearchIndex struct {
+	repository.SearchIndex
+	protectedIDs []int64
+}
+
+func (s stubSearchIndex) ProtectedUserIDs(ctx context.Context) ([]int64, error) {
+	return s.protectedIDs, nil
+}
+
+// stubFollowedRepository answers which users a viewer follows.
+type stubFollowedRepository struct {
+	repository.FollowerRepository
+	followedIDs map[int64][]int64
+}
+
+func (s stubFollowedRepository) SelectFollowedIDsAmong(ctx context.Context, followerID int64, ids []int64) ([]int64, error) {
+	var followed []int64
+	for _, id := range ids {
+		for _, followedID := range s.followedIDs[followerID] {
+			if id == followedID {
+				followed = append(followed, id)
+			}
+		}
+	}
+	return followed, nil
+}
+
+func TestSearchIndex_GetVisibleProtectedIDs(t *testing.T) {
+	// Arrange
+	searchIndex := SearchIndex{
+		searchIndex:        stubSearchIndex{protectedIDs: []int64{10, 11, 12}},
+		followerRepository: stubFollowedRepository{followedIDs: map[int64][]int64{1: {11, 20}}},
+	}
+
+	// Act
+	anonymous, err := searchIndex.getVisibleProtectedIDs(context.Background(), 0)
+	assert.NoError(t, err)
+	viewer, err := searchIndex.getVisibleProtectedIDs(context.Background(), 1)
+
+	// Assert: the protected accounts followed, and the viewer's own
+	assert.NoError(t, err)
+	assert.Empty(t, anonymous)
+	assert.Equal(t, []int64{11, 1}, viewer)
+}
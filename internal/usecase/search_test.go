@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	// Act
+	query, err := ParseSearchQuery(`go "clean architecture" #golang from:@alice since:2024-01-01 until:2024-02-01`)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SearchQuery{
+		Text:     `go "clean architecture" golang`,
+		Hashtags: []string{"golang"},
+		From:     "alice",
+		Since:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, query)
+}
+
+func TestParseSearchQueryFromOnly(t *testing.T) {
+	query, err := ParseSearchQuery("from:alice")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", query.From)
+	assert.Empty(t, query.Text)
+}
+
+func TestParseSearchQueryInvalid(t *testing.T) {
+	_, err := ParseSearchQuery("   ")
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+
+	_, err = ParseSearchQuery("go since:yesterday")
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+
+	// A date range alone has nothing to search for
+	_, err = ParseSearchQuery("since:2024-01-01")
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	cursor := domain.SearchCursor{TweetID: 42, Rank: 0.0607927}
+
+	parsed, err := parseSearchCursor(formatSearchCursor(cursor, domain.SearchSortRelevance), domain.SearchSortRelevance)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	// Sorted by recency, only the tweet ID is kept
+	assert.Equal(t, "42", formatSearchCursor(cursor, domain.SearchSortRecent))
+	parsed, err = parseSearchCursor("42", domain.SearchSortRecent)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SearchCursor{TweetID: 42}, parsed)
+
+	_, err = parseSearchCursor("42", domain.SearchSortRelevance)
+	assert.ErrorIs(t, err, ErrInvalidSearchCursor)
+}
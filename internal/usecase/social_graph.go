@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+// SocialGraphUsecase mirrors in the social graph of this process the relationships
+// changed by other processes, which it learns about from their events.
+type SocialGraphUsecase interface {
+	ApplyFollow(ctx context.Context, followerID, followedID int64) error
+	ApplyUnfollow(ctx context.Context, followerID, followedID int64) error
+	ApplyBlock(ctx context.Context, blockerID, blockedID int64) error
+}
+
+type SocialGraph struct {
+	graph repository.SocialGraph
+}
+
+func NewSocialGraph(graph repository.SocialGraph) SocialGraph {
+	return SocialGraph{
+		graph: graph,
+	}
+}
+
+func (s SocialGraph) ApplyFollow(ctx context.Context, followerID, followedID int64) error {
+	return s.graph.AddEdge(ctx, followerID, followedID)
+}
+
+func (s SocialGraph) ApplyUnfollow(ctx context.Context, followerID, followedID int64) error {
+	return s.graph.RemoveEdge(ctx, followerID, followedID)
+}
+
+// ApplyBlock removes the follows between both users, in both directions, like the block did.
+func (s SocialGraph) ApplyBlock(ctx context.Context, blockerID, blockedID int64) error {
+
+	if err := s.graph.RemoveEdge(ctx, blockerID, blockedID); err != nil {
+		return err
+	}
+
+	return s.graph.RemoveEdge(ctx, blockedID, blockerID)
+}
@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+)
+
+const (
+	// TimelineStreamKey defines the key of the stream of events of a user's timeline,
+	// kept for a while so a client can resume after a disconnection
+	TimelineStreamKey = "stream:timeline:user:%d"
+	// TimelineChannel defines the pub/sub channel the events of a user's timeline are published to
+	TimelineChannel = "events:timeline:user:%d"
+	// TimelineControlChannel is always subscribed to, so the pub/sub connection of a
+	// replica stays open while no user is connected
+	TimelineControlChannel = "events:timeline"
+	// StreamOnlineKey defines the key of the sorted set holding when the stream of each
+	// connected user last sent a heartbeat
+	StreamOnlineKey = "stream:online"
+)
+
+// timelineEventPayload is the message published for a timeline event.
+type timelineEventPayload struct {
+	ID      string `json:"id"`
+	TweetID int64  `json:"tweet_id"`
+}
+
+// publishTimelineEvents streams a tweet the fan-out pushed to the timelines of userIDs to
+// the ones connected to their timeline. Each event is added to the user's stream, so a
+// client can resume from its ID, and published to the user's channel, so whichever
+// replica holds the connection delivers it.
+func (t Timeline) publishTimelineEvents(ctx context.Context, tweetID int64, userIDs []int64) {
+
+	onlineIDs := t.selectOnlineUserIDs(ctx, userIDs)
+	if len(onlineIDs) == 0 {
+		return
+	}
+
+	streamKeys := make([]string, len(onlineIDs))
+	for i, userID := range onlineIDs {
+		streamKeys[i] = fmt.Sprintf(TimelineStreamKey, userID)
+	}
+
+	eventIDs, err := t.cache.AppendToStreams(ctx, streamKeys, map[string]interface{}{"tweet_id": tweetID}, t.fanOutConfig.StreamMaxLen, t.fanOutConfig.StreamExpiration)
+	if err != nil {
+		log.Printf("Failed to stream tweet %d: %v", tweetID, err)
+		return
+	}
+
+	messages := make([]pkg.Message, len(onlineIDs))
+	for i, userID := range onlineIDs {
+		payload, _ := json.Marshal(timelineEventPayload{ID: eventIDs[i], TweetID: tweetID})
+		messages[i] = pkg.Message{Channel: fmt.Sprintf(TimelineChannel, userID), Payload: string(payload)}
+	}
+
+	if err := t.cache.PublishMany(ctx, messages); err != nil {
+		log.Printf("Failed to publish tweet %d to streams: %v", tweetID, err)
+		return
+	}
+
+	log.Printf("Streamed tweet %d to %d connected users", tweetID, len(onlineIDs))
+}
+
+// selectOnlineUserIDs keeps the users whose timeline stream sent a heartbeat recently.
+func (t Timeline) selectOnlineUserIDs(ctx context.Context, userIDs []int64) []int64 {
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		members[i] = strconv.FormatInt(id, 10)
+	}
+
+	scores, err := t.cache.ZMScore(ctx, StreamOnlineKey, members)
+	if err != nil {
+		log.Printf("Failed to read connected users: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	online := make([]int64, 0)
+	for i, id := range userIDs {
+		if isActive(scores[i], now, t.fanOutConfig.StreamOnlineWindow) {
+			online = append(online, id)
+		}
+	}
+
+	return online
+}
+
+type TimelineStreamUsecase interface {
+	Subscribe(ctx context.Context, userID int64, lastEventID string) (*TimelineSubscription, error)
+	KeepAlive(ctx context.Context, userID int64)
+}
+
+// TimelineStream delivers the events of the timelines of the users connected to this
+// replica. It holds a single pub/sub subscription, subscribed to the channel of every
+// connected user, and dispatches the events to their connections.
+type TimelineStream struct {
+	cache        pkg.Cache
+	config       config.StreamConfig
+	start        sync.Once
+	mutex        sync.Mutex
+	subscription pkg.Subscription
+	subscribers  map[int64]map[*TimelineSubscription]bool
+}
+
+// TimelineSubscription is the connection of a user to their timeline stream. Backlog holds
+// the events missed since the last event ID given when subscribing, Events the new ones.
+// Events is closed when the client doesn't keep up: it should reconnect and resume from
+// the last event it got.
+type TimelineSubscription struct {
+	Backlog []domain.TimelineEvent
+	Events  <-chan domain.TimelineEvent
+
+	userID int64
+	events chan domain.TimelineEvent
+	closed bool
+	stream *TimelineStream
+}
+
+func NewTimelineStream(cache pkg.Cache, streamConfig config.StreamConfig) *TimelineStream {
+	return &TimelineStream{
+		cache:       cache,
+		config:      streamConfig,
+		subscribers: make(map[int64]map[*TimelineSubscription]bool),
+	}
+}
+
+// Subscribe connects a user to their timeline stream. With a lastEventID, the events
+// that came after it and are still kept are returned as the backlog. Events may be both
+// in the backlog and received: clients skip the ones not After the last they handled.
+func (s *TimelineStream) Subscribe(ctx context.Context, userID int64, lastEventID string) (*TimelineSubscription, error) {
+
+	// The subscription is opened on first use, so processes not serving streams don't hold one
+	s.start.Do(func() {
+		s.subscription = s.cache.NewSubscription(context.Background(), TimelineControlChannel)
+		go s.dispatch()
+	})
+
+	events := make(chan domain.TimelineEvent, s.config.BufferSize)
+	subscription := &TimelineSubscription{
+		Events: events,
+		userID: userID,
+		events: events,
+		stream: s,
+	}
+
+	// Subscribe before reading the backlog, so no event falls in between
+	if err := s.addSubscriber(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	s.KeepAlive(ctx, userID)
+
+	if lastEventID != "" {
+		entries, err := s.cache.ReadStream(ctx, fmt.Sprintf(TimelineStreamKey, userID), lastEventID, 0)
+		if err != nil {
+			subscription.Close()
+			return nil, fmt.Errorf("failed to read timeline stream of user %d: %w", userID, err)
+		}
+
+		for _, entry := range entries {
+			tweetID, err := strconv.ParseInt(entry.Values["tweet_id"], 10, 64)
+			if err != nil {
+				continue
+			}
+			subscription.Backlog = append(subscription.Backlog, domain.TimelineEvent{ID: entry.ID, TweetID: tweetID})
+		}
+	}
+
+	return subscription, nil
+}
+
+// KeepAlive records that a user is still connected, so the fan-out keeps streaming to them.
+func (s *TimelineStream) KeepAlive(ctx context.Context, userID int64) {
+	if err := s.cache.ZAdd(ctx, StreamOnlineKey, strconv.FormatInt(userID, 10), float64(time.Now().Unix())); err != nil {
+		log.Printf("Failed to record stream of user %d: %v", userID, err)
+	}
+}
+
+// Close disconnects the subscription. It is safe to call more than once.
+func (s *TimelineSubscription) Close() {
+	s.stream.removeSubscriber(s)
+}
+
+// addSubscriber registers a subscription, subscribing to the user's channel for the first one.
+func (s *TimelineStream) addSubscriber(ctx context.Context, subscription *TimelineSubscription) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	userSubscribers := s.subscribers[subscription.userID]
+	if userSubscribers == nil {
+		if err := s.subscription.Subscribe(ctx, fmt.Sprintf(TimelineChannel, subscription.userID)); err != nil {
+			return fmt.Errorf("failed to subscribe to timeline of user %d: %w", subscription.userID, err)
+		}
+		userSubscribers = make(map[*TimelineSubscription]bool)
+		s.subscribers[subscription.userID] = userSubscribers
+	}
+
+	userSubscribers[subscription] = true
+
+	return nil
+}
+
+// removeSubscriber unregisters a subscription and closes its events, unsubscribing from
+// the user's channel after the last one. The caller must not hold the mutex.
+func (s *TimelineStream) removeSubscriber(subscription *TimelineSubscription) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeSubscriberLocked(subscription)
+}
+
+func (s *TimelineStream) removeSubscriberLocked(subscription *TimelineSubscription) {
+
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	close(subscription.events)
+
+	userSubscribers := s.subscribers[subscription.userID]
+	delete(userSubscribers, subscription)
+	if len(userSubscribers) > 0 {
+		return
+	}
+
+	delete(s.subscribers, subscription.userID)
+	if err := s.subscription.Unsubscribe(context.Background(), fmt.Sprintf(TimelineChannel, subscription.userID)); err != nil {
+		log.Printf("Failed to unsubscribe from timeline of user %d: %v", subscription.userID, err)
+	}
+}
+
+// dispatch delivers the published events to the subscriptions of their user. A
+// subscription whose buffer is full is closed instead of blocking everyone else.
+func (s *TimelineStream) dispatch() {
+
+	for message := range s.subscription.Messages() {
+
+		var userID int64
+		if _, err := fmt.Sscanf(message.Channel, TimelineChannel, &userID); err != nil {
+			continue
+		}
+
+		var payload timelineEventPayload
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			log.Printf("Ignoring malformed timeline event on %s: %v", message.Channel, err)
+			continue
+		}
+		event := domain.TimelineEvent{ID: payload.ID, TweetID: payload.TweetID}
+
+		s.mutex.Lock()
+		for subscription := range s.subscribers[userID] {
+			select {
+			case subscription.events <- event:
+			default:
+				log.Printf("Disconnecting slow stream of user %d", userID)
+				s.removeSubscriberLocked(subscription)
+			}
+		}
+		s.mutex.Unlock()
+	}
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimelineEventAfter(t *testing.T) {
+	event := domain.TimelineEvent{ID: "1714564800000-1", TweetID: 42}
+
+	assert.True(t, event.After(""))
+	assert.True(t, event.After("1714564800000-0"))
+	assert.True(t, event.After("999999999999-5"))
+	assert.False(t, event.After("1714564800000-1"))
+	assert.False(t, event.After("1714564800001-0"))
+}
+
+// stubSubscription hands the messages published on its channel to the stream, and
+// records the channels it is subscribed to.
+type stubSubscription struct {
+	mutex    sync.Mutex
+	channels map[string]bool
+	messages chan pkg.Message
+}
+
+func (s *stubSubscription) Subscribe(ctx context.Context, channels ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, channel := range channels {
+		s.channels[channel] = true
+	}
+	return nil
+}
+
+func (s *stubSubscription) Unsubscribe(ctx context.Context, channels ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, channel := range channels {
+		delete(s.channels, channel)
+	}
+	return nil
+}
+
+func (s *stubSubscription) Messages() <-chan pkg.Message {
+	return s.messages
+}
+
+func (s *stubSubscription) Close() error {
+	close(s.messages)
+	return nil
+}
+
+func (s *stubSubscription) isSubscribed(channel string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.channels[channel]
+}
+
+// stubStreamCache adds the streams and pub/sub commands to stubCache. Streams hold the
+// entries to read back, appended records the keys written and published the messages.
+type stubStreamCache struct {
+	*stubCache
+	subscription *stubSubscription
+	streams      map[string][]pkg.StreamEntry
+	appended     []string
+	published    []pkg.Message
+}
+
+func newStubStreamCache() *stubStreamCache {
+	return &stubStreamCache{
+		stubCache:    newStubCache(),
+		subscription: &stubSubscription{channels: map[string]bool{}, messages: make(chan pkg.Message)},
+		streams:      map[string][]pkg.StreamEntry{},
+	}
+}
+
+func (s *stubStreamCache) NewSubscription(ctx context.Context, channels ...string) pkg.Subscription {
+	_ = s.subscription.Subscribe(ctx, channels...)
+	return s.subscription
+}
+
+// ReadStream ignores count and returns the entries after afterID.
+func (s *stubStreamCache) ReadStream(ctx context.Context, key, afterID string, count int64) ([]pkg.StreamEntry, error) {
+	var entries []pkg.StreamEntry
+	for _, entry := range s.streams[key] {
+		if (domain.TimelineEvent{ID: entry.ID}).After(afterID) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *stubStreamCache) AppendToStreams(ctx context.Context, keys []string, values map[string]interface{}, maxLen int64, expiration time.Duration) ([]string, error) {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		s.appended = append(s.appended, key)
+		ids[i] = fmt.Sprintf("%d-0", len(s.appended))
+	}
+	return ids, nil
+}
+
+func (s *stubStreamCache) PublishMany(ctx context.Context, messages []pkg.Message) error {
+	s.published = append(s.published, messages...)
+	return nil
+}
+
+// publish sends a timeline event to the stream, as the fan-out of another replica would.
+func (s *stubStreamCache) publish(userID int64, eventID string, tweetID int64) {
+	payload, _ := json.Marshal(timelineEventPayload{ID: eventID, TweetID: tweetID})
+	s.subscription.messages <- pkg.Message{Channel: fmt.Sprintf(TimelineChannel, userID), Payload: string(payload)}
+}
+
+func TestTimelineStream_Subscribe_Backlog(t *testing.T) {
+	// Arrange: the client got event 1-0 before disconnecting
+	ctx := context.Background()
+	cache := newStubStreamCache()
+	cache.streams[fmt.Sprintf(TimelineStreamKey, 1)] = []pkg.StreamEntry{
+		{ID: "1-0", Values: map[string]string{"tweet_id": "10"}},
+		{ID: "2-0", Values: map[string]string{"tweet_id": "11"}},
+		{ID: "3-0", Values: map[string]string{"tweet_id": "oops"}},
+		{ID: "4-0", Values: map[string]string{"tweet_id": "12"}},
+	}
+	stream := NewTimelineStream(cache, config.StreamConfig{BufferSize: 8})
+
+	// Act
+	resumed, err := stream.Subscribe(ctx, 1, "1-0")
+	assert.NoError(t, err)
+	defer resumed.Close()
+	fresh, err := stream.Subscribe(ctx, 1, "")
+	assert.NoError(t, err)
+	defer fresh.Close()
+
+	// Assert: the missed events are replayed, malformed ones are skipped, and the user
+	// is subscribed to and recorded as connected
+	assert.Equal(t, []domain.TimelineEvent{{ID: "2-0", TweetID: 11}, {ID: "4-0", TweetID: 12}}, resumed.Backlog)
+	assert.Empty(t, fresh.Backlog)
+	assert.True(t, cache.subscription.isSubscribed(fmt.Sprintf(TimelineChannel, 1)))
+	assert.NotZero(t, cache.scores[StreamOnlineKey]["1"])
+}
+
+func TestTimelineStream_DispatchDropsSlowSubscriber(t *testing.T) {
+	// Arrange: two connections of user 1 with room for one event, one of them never reads
+	ctx := context.Background()
+	cache := newStubStreamCache()
+	stream := NewTimelineStream(cache, config.StreamConfig{BufferSize: 1})
+
+	slow, err := stream.Subscribe(ctx, 1, "")
+	assert.NoError(t, err)
+	fast, err := stream.Subscribe(ctx, 1, "")
+	assert.NoError(t, err)
+	defer fast.Close()
+
+	// Act: each publish waits for the previous event to be dispatched
+	var received []domain.TimelineEvent
+	for i := int64(1); i <= 3; i++ {
+		cache.publish(1, fmt.Sprintf("%d-0", i), 10+i)
+		received = append(received, <-fast.Events)
+	}
+
+	var slowReceived []domain.TimelineEvent
+	for event := range slow.Events {
+		slowReceived = append(slowReceived, event)
+	}
+
+	// Assert: the slow connection got what fit in its buffer and was closed, the other
+	// one kept receiving and the user is still subscribed to
+	assert.Equal(t, []domain.TimelineEvent{{ID: "1-0", TweetID: 11}, {ID: "2-0", TweetID: 12}, {ID: "3-0", TweetID: 13}}, received)
+	assert.Equal(t, []domain.TimelineEvent{{ID: "1-0", TweetID: 11}}, slowReceived)
+	assert.True(t, cache.subscription.isSubscribed(fmt.Sprintf(TimelineChannel, 1)))
+
+	// Closing a dropped connection again is harmless
+	slow.Close()
+}
+
+func TestTimeline_PublishTimelineEvents_OnlineUsersOnly(t *testing.T) {
+	// Arrange: user 1 sent a heartbeat just now, user 2 an hour ago, user 3 never connected
+	cache := newStubStreamCache()
+	cache.scores[StreamOnlineKey]["1"] = float64(time.Now().Unix())
+	cache.scores[StreamOnlineKey]["2"] = float64(time.Now().Add(-time.Hour).Unix())
+	timeline := Timeline{cache: cache, fanOutConfig: config.FanOutConfig{StreamOnlineWindow: time.Minute}}
+
+	// Act
+	timeline.publishTimelineEvents(context.Background(), 42, []int64{1, 2, 3})
+
+	// Assert
+	assert.Equal(t, []string{fmt.Sprintf(TimelineStreamKey, 1)}, cache.appended)
+	assert.Equal(t, []pkg.Message{{Channel: fmt.Sprintf(TimelineChannel, 1), Payload: `{"id":"1-0","tweet_id":42}`}}, cache.published)
+}
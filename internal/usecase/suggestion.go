@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/pkg"
+)
+
+const (
+	// SuggestionsCacheKey defines the key for the cache of a user's who-to-follow suggestions
+	SuggestionsCacheKey = "suggestions:user:%d"
+	// SuggestionsRefreshLockKey defines the key of the lock held by the worker refreshing suggestions
+	SuggestionsRefreshLockKey = "lock:suggestions:refresh"
+)
+
+// ErrRefreshInProgress is returned when another worker is already refreshing the suggestions.
+var ErrRefreshInProgress = errors.New("suggestions are being refreshed")
+
+type SuggestionUsecase interface {
+	GetSuggestions(ctx context.Context, userID int64, limit int) ([]domain.Suggestion, error)
+	RefreshSuggestions(ctx context.Context) (int, error)
+}
+
+type Suggestion struct {
+	followerRepository repository.FollowerRepository
+	userRepository     repository.UserRepository
+	blockRepository    repository.BlockRepository
+	cache              pkg.Cache
+	suggestionConfig   config.SuggestionConfig
+}
+
+func NewSuggestion(followerRepository repository.FollowerRepository, userRepository repository.UserRepository, blockRepository repository.BlockRepository, cache pkg.Cache, suggestionConfig config.SuggestionConfig) Suggestion {
+	return Suggestion{
+		followerRepository: followerRepository,
+		userRepository:     userRepository,
+		blockRepository:    blockRepository,
+		cache:              cache,
+		suggestionConfig:   suggestionConfig,
+	}
+}
+
+// GetSuggestions returns the accounts userID may want to follow, best first, with the
+// users and the mutual connections explaining each suggestion loaded.
+// Suggestions are precomputed by the worker; users it didn't reach yet get them computed on demand.
+// Accounts followed or blocked since the last computation are left out.
+func (s Suggestion) GetSuggestions(ctx context.Context, userID int64, limit int) ([]domain.Suggestion, error) {
+
+	// Set default and max values
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > s.suggestionConfig.MaxPerUser {
+		limit = s.suggestionConfig.MaxPerUser
+	}
+
+	suggestions, cacheHit := s.retrieveCachedSuggestions(ctx, userID)
+	if !cacheHit {
+		var err error
+		suggestions, err = s.followerRepository.SelectFriendsOfFriends(ctx, userID, s.suggestionConfig.MaxPerUser, s.suggestionConfig.MaxFollowedBy)
+		if err != nil {
+			return nil, err
+		}
+
+		go s.storeSuggestions(context.Background(), userID, suggestions)
+	}
+
+	suggestions, err := s.removeStaleSuggestions(ctx, userID, suggestions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return s.hydrateSuggestions(ctx, suggestions)
+}
+
+// RefreshSuggestions recomputes the suggestions of every user following at least one
+// account and stores them in cache. It is the worker job and returns how many users were processed.
+// Only one worker runs it at a time: the others get ErrRefreshInProgress until it is done.
+func (s Suggestion) RefreshSuggestions(ctx context.Context) (int, error) {
+
+	lockToken, locked := s.acquireRefreshLock(ctx)
+	if !locked {
+		return 0, ErrRefreshInProgress
+	}
+	defer s.releaseRefreshLock(lockToken)
+
+	processed := 0
+	var afterID int64
+
+	for {
+		userIDs, err := s.followerRepository.SelectFollowerIDsPage(ctx, afterID, s.suggestionConfig.BatchSize)
+		if err != nil {
+			return processed, err
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return processed, ctx.Err()
+			}
+
+			suggestions, err := s.followerRepository.SelectFriendsOfFriends(ctx, userID, s.suggestionConfig.MaxPerUser, s.suggestionConfig.MaxFollowedBy)
+			if err != nil {
+				log.Printf("Failed to compute suggestions for user %d: %v", userID, err)
+				continue
+			}
+
+			s.storeSuggestions(ctx, userID, suggestions)
+			processed++
+		}
+
+		if len(userIDs) < s.suggestionConfig.BatchSize {
+			return processed, nil
+		}
+
+		afterID = userIDs[len(userIDs)-1]
+	}
+}
+
+// acquireRefreshLock takes the lock to refresh the suggestions and returns its token. It
+// expires after an interval, so a worker dying mid-run doesn't stop the next runs. If Redis
+// can't be reached the refresh goes ahead: storing the suggestions will fail anyway.
+func (s Suggestion) acquireRefreshLock(ctx context.Context) (string, bool) {
+
+	token := strconv.FormatUint(rand.Uint64(), 36)
+
+	locked, err := s.cache.SetNX(ctx, SuggestionsRefreshLockKey, token, s.suggestionConfig.Interval)
+	if err != nil {
+		log.Printf("Failed to lock suggestions refresh: %v", err)
+		return "", true
+	}
+
+	return token, locked
+}
+
+// releaseRefreshLock releases the refresh lock, unless it expired and was taken by another worker.
+func (s Suggestion) releaseRefreshLock(token string) {
+
+	if token == "" {
+		return
+	}
+
+	// The run may have been stopped by ctx being cancelled, the lock is released anyway
+	if _, err := s.cache.DeleteIfEquals(context.Background(), SuggestionsRefreshLockKey, token); err != nil {
+		log.Printf("Failed to release suggestions refresh lock: %v", err)
+	}
+}
+
+// removeStaleSuggestions drops the accounts userID followed or blocked (or got blocked by)
+// after the suggestions were computed.
+func (s Suggestion) removeStaleSuggestions(ctx context.Context, userID int64, suggestions []domain.Suggestion) ([]domain.Suggestion, error) {
+
+	if len(suggestions) == 0 {
+		return suggestions, nil
+	}
+
+	suggestedIDs := make([]int64, len(suggestions))
+	for i, suggestion := range suggestions {
+		suggestedIDs[i] = suggestion.UserID
+	}
+
+	followedIDs, err := s.followerRepository.SelectFollowedIDsAmong(ctx, userID, suggestedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedIDs, err := s.blockRepository.SelectBlockedOrBlockingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[int64]bool, len(followedIDs)+len(blockedIDs))
+	for _, id := range append(followedIDs, blockedIDs...) {
+		excluded[id] = true
+	}
+
+	fresh := make([]domain.Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if !excluded[suggestion.UserID] {
+			fresh = append(fresh, suggestion)
+		}
+	}
+
+	return fresh, nil
+}
+
+// hydrateSuggestions loads the suggested users and their mutual connections with a single query.
+func (s Suggestion) hydrateSuggestions(ctx context.Context, suggestions []domain.Suggestion) ([]domain.Suggestion, error) {
+
+	var userIDs []int64
+	for _, suggestion := range suggestions {
+		userIDs = append(userIDs, suggestion.UserID)
+		userIDs = append(userIDs, suggestion.FollowedByIDs...)
+	}
+
+	users, err := s.userRepository.SelectByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := make(map[int64]domain.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	hydrated := make([]domain.Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		user, ok := usersByID[suggestion.UserID]
+		if !ok {
+			continue
+		}
+
+		suggestion.User = user
+		for _, followedByID := range suggestion.FollowedByIDs {
+			if followedBy, ok := usersByID[followedByID]; ok {
+				suggestion.FollowedBy = append(suggestion.FollowedBy, followedBy)
+			}
+		}
+
+		hydrated = append(hydrated, suggestion)
+	}
+
+	return hydrated, nil
+}
+
+// retrieveCachedSuggestions reads the precomputed suggestions of a user.
+func (s Suggestion) retrieveCachedSuggestions(ctx context.Context, userID int64) ([]domain.Suggestion, bool) {
+
+	entries, err := s.cache.LRange(ctx, s.getCacheKey(userID), 0, -1)
+	if err != nil || len(entries) == 0 {
+		return nil, false
+	}
+
+	suggestions := make([]domain.Suggestion, 0, len(entries))
+	for _, entry := range entries {
+		suggestion, ok := parseSuggestionEntry(entry)
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, true
+}
+
+// storeSuggestions replaces the cached suggestions of a user.
+func (s Suggestion) storeSuggestions(ctx context.Context, userID int64, suggestions []domain.Suggestion) {
+
+	entries := make([]interface{}, len(suggestions))
+	for i, suggestion := range suggestions {
+		entries[i] = formatSuggestionEntry(suggestion)
+	}
+
+	// No suggestions deletes the previous ones
+	if err := s.cache.ReplaceList(ctx, s.getCacheKey(userID), entries, 0, s.suggestionConfig.Expiration); err != nil {
+		log.Printf("Failed to cache suggestions for user %d: %v", userID, err)
+	}
+}
+
+// getCacheKey constructs the cache key for a user's suggestions.
+func (s Suggestion) getCacheKey(userID int64) string {
+	return fmt.Sprintf(SuggestionsCacheKey, userID)
+}
+
+// formatSuggestionEntry encodes a suggestion as "userID:mutualCount:followedByID,followedByID".
+func formatSuggestionEntry(suggestion domain.Suggestion) string {
+
+	followedByIDs := make([]string, len(suggestion.FollowedByIDs))
+	for i, id := range suggestion.FollowedByIDs {
+		followedByIDs[i] = strconv.FormatInt(id, 10)
+	}
+
+	return fmt.Sprintf("%d:%d:%s", suggestion.UserID, suggestion.MutualCount, strings.Join(followedByIDs, ","))
+}
+
+// parseSuggestionEntry decodes an entry written by formatSuggestionEntry.
+func parseSuggestionEntry(entry string) (domain.Suggestion, bool) {
+
+	parts := strings.SplitN(entry, ":", 3)
+	if len(parts) != 3 {
+		return domain.Suggestion{}, false
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return domain.Suggestion{}, false
+	}
+
+	mutualCount, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return domain.Suggestion{}, false
+	}
+
+	suggestion := domain.Suggestion{UserID: userID, MutualCount: mutualCount}
+	if parts[2] != "" {
+		for _, idString := range strings.Split(parts[2], ",") {
+			id, err := strconv.ParseInt(idString, 10, 64)
+			if err != nil {
+				return domain.Suggestion{}, false
+			}
+			suggestion.FollowedByIDs = append(suggestion.FollowedByIDs, id)
+		}
+	}
+
+	return suggestion, true
+}
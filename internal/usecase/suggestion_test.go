@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestionEntry(t *testing.T) {
+	// Arrange
+	suggestion := domain.Suggestion{UserID: 42, MutualCount: 5, FollowedByIDs: []int64{7, 3, 9}}
+	withoutFollowedBy := domain.Suggestion{UserID: 8, MutualCount: 1}
+
+	// Act
+	entry := formatSuggestionEntry(suggestion)
+	parsed, ok := parseSuggestionEntry(entry)
+	parsedWithoutFollowedBy, okWithoutFollowedBy := parseSuggestionEntry(formatSuggestionEntry(withoutFollowedBy))
+
+	// Assert
+	assert.Equal(t, "42:5:7,3,9", entry)
+	assert.True(t, ok)
+	assert.Equal(t, suggestion, parsed)
+	assert.True(t, okWithoutFollowedBy)
+	assert.Equal(t, withoutFollowedBy, parsedWithoutFollowedBy)
+}
+
+func TestParseSuggestionEntryInvalid(t *testing.T) {
+	// Act & Assert
+	for _, entry := range []string{"", "42", "42:x:1", "a:1:", "42:1:1,b"} {
+		_, ok := parseSuggestionEntry(entry)
+		assert.False(t, ok, entry)
+	}
+}
+
+func TestSuggestion_RefreshSuggestions_Lock(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache := newStubCache()
+	suggestion := NewSuggestion(stubFollowerRepository{}, nil, nil, cache, config.SuggestionConfig{Interval: time.Hour, BatchSize: 10})
+
+	// Act: a run while another worker holds the lock, then once it released it
+	cache.values[SuggestionsRefreshLockKey] = "other-worker"
+	_, errWhileLocked := suggestion.RefreshSuggestions(ctx)
+
+	delete(cache.values, SuggestionsRefreshLockKey)
+	_, err := suggestion.RefreshSuggestions(ctx)
+
+	// Assert: the lock is released once the run is done
+	assert.ErrorIs(t, errWhileLocked, ErrRefreshInProgress)
+	assert.NoError(t, err)
+	assert.NotContains(t, cache.values, SuggestionsRefreshLockKey)
+}
@@ -2,14 +2,21 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 	"twitter-demo/internal/config"
 	"twitter-demo/internal/domain"
 	"twitter-demo/internal/infrastructure/repository"
 	"twitter-demo/pkg"
+
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -19,28 +26,106 @@ const (
 	CacheExpiration = 14 * 24 * time.Hour
 	// CacheKey defines the key for the cache
 	CacheKey = "timeline:user:%d"
+	// MaxCachedUserTweets defines the maximum number of a user's own tweet IDs to keep in cache
+	MaxCachedUserTweets = 200
+	// UserTweetsCacheKey defines the key for the cache of a user's own tweets (profile)
+	UserTweetsCacheKey = "tweets:user:%d"
+	// ListCacheKey defines the key for the cache of a list timeline, shared by all its readers
+	ListCacheKey = "timeline:list:%d"
+	// HiddenUsersCacheKey defines the key for the users whose tweets a user must not see
+	HiddenUsersCacheKey = "hidden:user:%d"
+	// HiddenUsersCacheExpiration defines how long the hidden users are kept. They are dropped
+	// by mute and block events; the expiration bounds how long a lost event goes unnoticed.
+	HiddenUsersCacheExpiration = 10 * time.Minute
+	// StaleCacheKey defines the key where an invalidated timeline is kept while it is rebuilt
+	StaleCacheKey = "%s:stale"
+	// StaleExpiration defines how long an invalidated timeline can still be served
+	StaleExpiration = 10 * time.Minute
+	// RebuildLockKey defines the key of the lock held by the replica rebuilding a timeline
+	RebuildLockKey = "lock:%s"
+	// RebuildLockTimeout defines how long a rebuild lock is held at most
+	RebuildLockTimeout = 5 * time.Second
+	// RebuildWait defines how long a request waits for another replica to rebuild a timeline
+	RebuildWait = 200 * time.Millisecond
+	// rebuildPollInterval defines how often the cache is checked while waiting for a rebuild
+	rebuildPollInterval = 25 * time.Millisecond
+	// ActiveUsersKey defines the key of the sorted set holding when each user last read their timeline
+	ActiveUsersKey = "active:users"
+	// ActiveSinceKey defines the key holding when activity started being recorded in ActiveUsersKey
+	ActiveSinceKey = "active:since"
+	// FanOutStatsKey defines the key of the hash counting the timeline writes made and saved by the fan-out
+	FanOutStatsKey = "stats:fanout"
+	// TimelineEndMarker is the last entry of a cached timeline holding every tweet of the
+	// timeline, telling "no more tweets" apart from "not cached"
+	TimelineEndMarker = "end"
+)
+
+// Suffixes added to the cached IDs of a user's own tweets, so profile filters can be
+// applied without loading the tweets
+const (
+	replySuffix   = ":r"
+	retweetSuffix = ":t"
 )
 
 type TimelineUsecase interface {
-	GetTimeline(ctx context.Context, userID int64, limit, offset int) ([]domain.Tweet, error)
+	GetTimeline(ctx context.Context, userID int64, limit, offset int, excludeSelf bool) ([]domain.Tweet, error)
+	GetRankedTimeline(ctx context.Context, userID int64, limit, offset int, snapshotID string, excludeSelf bool) ([]domain.Tweet, string, error)
 	FanOutTweet(ctx context.Context, authorID int64, tweetID int64) error
+	GetUserTweets(ctx context.Context, userID, viewerID int64, limit int, cursor int64, filter domain.TweetFilter) ([]domain.Tweet, int64, int64, error)
+	PushUserTweet(ctx context.Context, tweet domain.Tweet) error
+	InvalidateTimeline(ctx context.Context, userID int64) error
+	BackfillTimeline(ctx context.Context, followerID int64, followedIDs ...int64) error
+	GetListTimeline(ctx context.Context, listID, viewerID int64, limit, offset int) ([]domain.Tweet, error)
+	InvalidateListTimeline(ctx context.Context, listID int64) error
+	HydrateTweets(ctx context.Context, tweetIDs []int64) ([]domain.Tweet, error)
+	HydrateAuthors(ctx context.Context, tweets []domain.Tweet) (map[int64]domain.User, error)
+	CacheTweet(ctx context.Context, tweet domain.Tweet) error
+	RefreshTweet(ctx context.Context, tweetID int64) error
+	InvalidateProfiles(ctx context.Context, userIDs ...int64) error
+	InvalidateHiddenUsers(ctx context.Context, userIDs ...int64) error
+	GetMentions(ctx context.Context, userID int64, limit int, cursor int64) ([]domain.Tweet, int64, error)
+	PushMentions(ctx context.Context, tweet domain.Tweet) error
+	InvalidateMentions(ctx context.Context, previous, current domain.Tweet) error
+	PruneActivity(ctx context.Context) (int64, error)
 }
 
 type Timeline struct {
-	tweetRepository    repository.TweetRepository
-	followerRepository repository.FollowerRepository
-	cache              pkg.Cache
+	tweetRepository      repository.TweetRepository
+	userRepository       repository.UserRepository
+	followerRepository   repository.FollowerRepository
+	blockRepository      repository.BlockRepository
+	muteRepository       repository.MuteRepository
+	listRepository       repository.ListRepository
+	engagementRepository repository.EngagementRepository
+	mentionRepository    repository.MentionRepository
+	cache                pkg.Cache
+	scorer               TimelineScorer
+	fanOutConfig         config.FanOutConfig
+	rankingConfig        config.RankingConfig
+	rebuilds             *singleflight.Group
 }
 
-func NewTimeline(tweetRepository repository.TweetRepository, followerRepository repository.FollowerRepository, cache pkg.Cache) Timeline {
+func NewTimeline(tweetRepository repository.TweetRepository, userRepository repository.UserRepository, followerRepository repository.FollowerRepository, blockRepository repository.BlockRepository, muteRepository repository.MuteRepository, listRepository repository.ListRepository, engagementRepository repository.EngagementRepository, mentionRepository repository.MentionRepository, cache pkg.Cache, scorer TimelineScorer, fanOutConfig config.FanOutConfig, rankingConfig config.RankingConfig) Timeline {
 	return Timeline{
-		tweetRepository:    tweetRepository,
-		followerRepository: followerRepository,
-		cache:              cache,
+		tweetRepository:      tweetRepository,
+		userRepository:       userRepository,
+		followerRepository:   followerRepository,
+		blockRepository:      blockRepository,
+		muteRepository:       muteRepository,
+		listRepository:       listRepository,
+		engagementRepository: engagementRepository,
+		mentionRepository:    mentionRepository,
+		cache:                cache,
+		scorer:               scorer,
+		fanOutConfig:         fanOutConfig,
+		rankingConfig:        rankingConfig,
+		rebuilds:             &singleflight.Group{},
 	}
 }
 
-func (t Timeline) GetTimeline(ctx context.Context, userID int64, limit, offset int) ([]domain.Tweet, error) {
+// GetTimeline returns the home timeline of a user: their own tweets and the tweets of the
+// users they follow, newest first. excludeSelf leaves out their own tweets.
+func (t Timeline) GetTimeline(ctx context.Context, userID int64, limit, offset int, excludeSelf bool) ([]domain.Tweet, error) {
 
 	// Set default and max values for pagination
 	if limit <= 0 {
@@ -55,72 +140,774 @@ func (t Timeline) GetTimeline(ctx context.Context, userID int64, limit, offset i
 		offset = 0
 	}
 
-	// STEP 1: Try to get tweet IDs from cache
-	tweetIDs, err := t.retrieveCacheTweetIDs(ctx, userID, limit, offset)
+	// Users muted or blocked by the reader, or blocking the reader, never show up
+	hiddenUserIDs, err := t.getHiddenUserIDs(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// STEP 2: Handle cache hit - we got IDs from cache
-	if len(tweetIDs) == limit {
-		// We have enough IDs in cache, fetch tweets from DB using these IDs
-		tweets, err := t.tweetRepository.SelectTweetsByIDs(ctx, tweetIDs)
-		if err == nil {
-			return tweets, nil
+	t.recordActivity(ctx, userID)
+
+	// The cache holds the user's own tweets, they are left out when cutting the page from it
+	var excludeUserID int64
+	pageHiddenUserIDs := hiddenUserIDs
+	if excludeSelf {
+		excludeUserID = userID
+		pageHiddenUserIDs = append(slices.Clip(hiddenUserIDs), userID)
+	}
+
+	return t.readTimeline(ctx, t.getCacheKey(userID), limit, offset, hiddenUserIDs, excludeUserID, false, timelineSource{
+		selectPage: func(ctx context.Context) ([]domain.Tweet, error) {
+			return t.tweetRepository.SelectTimelineTweets(ctx, userID, limit, offset, pageHiddenUserIDs)
+		},
+		selectWindow: func(ctx context.Context) ([]int64, error) {
+			return t.tweetRepository.SelectTimelineTweetIDs(ctx, userID, MaxCachedTweets, hiddenUserIDs)
+		},
+	})
+}
+
+// GetListTimeline returns the tweets of the members of a list, newest first.
+// Private lists are only readable by their owner. The cached timeline is shared by
+// every reader, the reader's mutes and blocks and the protected members they don't
+// follow are filtered on read.
+func (t Timeline) GetListTimeline(ctx context.Context, listID, viewerID int64, limit, offset int) ([]domain.Tweet, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	list, err := t.listRepository.SelectByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewList(list, viewerID) {
+		return nil, ErrListNotFound
+	}
+
+	hiddenUserIDs, err := t.getHiddenListMemberIDs(ctx, listID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.readTimeline(ctx, t.getListCacheKey(listID), limit, offset, hiddenUserIDs, 0, true, timelineSource{
+		selectPage: func(ctx context.Context) ([]domain.Tweet, error) {
+			return t.tweetRepository.SelectListTimelineTweets(ctx, listID, limit, offset, hiddenUserIDs)
+		},
+		// Only rebuilt for viewers hiding nobody, the cache is shared
+		selectWindow: func(ctx context.Context) ([]int64, error) {
+			return t.tweetRepository.SelectListTimelineTweetIDs(ctx, listID, MaxCachedTweets, nil)
+		},
+	})
+}
+
+// recordActivity records that a user read their timeline. The fan-out skips users who
+// were inactive for longer than the active window, so the cached timeline of a user coming
+// back is missing tweets: it is dropped and rebuilt from the database by this read.
+func (t Timeline) recordActivity(ctx context.Context, userID int64) {
+
+	if t.fanOutConfig.ActiveWindow <= 0 {
+		return
+	}
+
+	member := strconv.FormatInt(userID, 10)
+	now := time.Now()
+
+	lastActive, err := t.cache.ZScore(ctx, ActiveUsersKey, member)
+	if err != nil {
+		log.Printf("Failed to read activity of user %d: %v", userID, err)
+		return
+	}
+
+	if err := t.cache.ZAdd(ctx, ActiveUsersKey, member, float64(now.Unix())); err != nil {
+		log.Printf("Failed to record activity of user %d: %v", userID, err)
+	}
+
+	// A user never seen may only be missing because activity started being recorded recently
+	activeSince := now
+	if lastActive == 0 {
+		activeSince, err = t.getActiveSince(ctx, now)
+		if err != nil {
+			log.Printf("Failed to read activity of user %d: %v", userID, err)
+			return
+		}
+	}
+
+	if isRecentlyActive(lastActive, activeSince, now, t.fanOutConfig.ActiveWindow) {
+		return
+	}
+
+	if err := t.cache.Delete(ctx, t.getCacheKey(userID)); err != nil {
+		log.Printf("Failed to drop timeline of returning user %d: %v", userID, err)
+		return
+	}
+
+	if err := t.cache.HIncrBy(ctx, FanOutStatsKey, "rebuilds", 1); err != nil {
+		log.Printf("Failed to update fan-out stats: %v", err)
+	}
+}
+
+// getActiveSince returns when activity started being recorded, starting it now if it wasn't.
+// Until a whole active window has passed, users missing from ActiveUsersKey may have read
+// their timeline before the first deploy recording activity, or before Redis was emptied.
+func (t Timeline) getActiveSince(ctx context.Context, now time.Time) (time.Time, error) {
+
+	started, err := t.cache.SetNX(ctx, ActiveSinceKey, now.Unix(), 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if started {
+		return now, nil
+	}
+
+	values, err := t.cache.MGet(ctx, []string{ActiveSinceKey})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	since, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q: %w", ActiveSinceKey, values[0], err)
+	}
+
+	return time.Unix(since, 0), nil
+}
+
+// isActive reports whether a user who last read their timeline at lastActive (Unix
+// seconds, 0 for never) is still within the active window.
+func isActive(lastActive float64, now time.Time, window time.Duration) bool {
+	return lastActive > 0 && now.Sub(time.Unix(int64(lastActive), 0)) <= window
+}
+
+// isRecentlyActive is isActive for activity recorded since activeSince: a user with no
+// recorded activity counts as active until activity was recorded for a whole window.
+func isRecentlyActive(lastActive float64, activeSince, now time.Time, window time.Duration) bool {
+	if lastActive == 0 {
+		return now.Sub(activeSince) <= window
+	}
+	return isActive(lastActive, now, window)
+}
+
+// selectActiveUserIDs keeps the users within the active window. If activity can't be
+// read, every user is kept: a useless write is better than a missing tweet.
+func (t Timeline) selectActiveUserIDs(ctx context.Context, userIDs []int64) []int64 {
+
+	if t.fanOutConfig.ActiveWindow <= 0 || len(userIDs) == 0 {
+		return userIDs
+	}
+
+	members := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		members[i] = strconv.FormatInt(id, 10)
+	}
+
+	scores, err := t.cache.ZMScore(ctx, ActiveUsersKey, members)
+	if err != nil {
+		log.Printf("Failed to read activity of followers: %v", err)
+		return userIDs
+	}
+
+	now := time.Now()
+	activeSince := now
+	if slices.Contains(scores, 0) {
+		activeSince, err = t.getActiveSince(ctx, now)
+		if err != nil {
+			log.Printf("Failed to read activity of followers: %v", err)
+			return userIDs
+		}
+	}
+
+	active := make([]int64, 0, len(userIDs))
+	for i, id := range userIDs {
+		if isRecentlyActive(scores[i], activeSince, now, t.fanOutConfig.ActiveWindow) {
+			active = append(active, id)
 		}
-		// If DB fetch failed, fall through to STEP 3
 	}
 
-	// STEP 3: Cache miss or partial miss - fall back to database
+	return active
+}
+
+// PruneActivity removes the users outside the active window from ActiveUsersKey, and the
+// ones outside the online window from StreamOnlineKey, so the sets don't grow with every
+// user who ever read their timeline. It returns how many users were removed.
+func (t Timeline) PruneActivity(ctx context.Context) (int64, error) {
+
+	now := time.Now()
+	var removed int64
+
+	if t.fanOutConfig.ActiveWindow > 0 {
+		cutoff := now.Add(-t.fanOutConfig.ActiveWindow).Unix()
+		count, err := t.cache.ZRemRangeByScore(ctx, ActiveUsersKey, "-inf", fmt.Sprintf("(%d", cutoff))
+		if err != nil {
+			return removed, err
+		}
+		removed += count
+	}
+
+	cutoff := now.Add(-t.fanOutConfig.StreamOnlineWindow).Unix()
+	count, err := t.cache.ZRemRangeByScore(ctx, StreamOnlineKey, "-inf", fmt.Sprintf("(%d", cutoff))
+	if err != nil {
+		return removed, err
+	}
+
+	return removed + count, nil
+}
+
+// getHiddenListMemberIDs returns the members of a list whose tweets viewerID must not see:
+// the ones they muted or blocked (or who blocked them) and protected accounts they don't follow.
+func (t Timeline) getHiddenListMemberIDs(ctx context.Context, listID, viewerID int64) ([]int64, error) {
+
+	var hiddenUserIDs []int64
+	if viewerID != 0 {
+		var err error
+		hiddenUserIDs, err = t.getHiddenUserIDs(ctx, viewerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	memberIDs, err := t.listRepository.SelectMemberIDs(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := t.userRepository.SelectByIDs(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		if !member.Protected {
+			continue
+		}
+
+		visible, err := canViewTweetsOf(ctx, t.followerRepository, viewerID, member)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			hiddenUserIDs = append(hiddenUserIDs, member.ID)
+		}
+	}
+
+	return hiddenUserIDs, nil
+}
+
+// InvalidateListTimeline drops the cached timeline of a list, e.g. after its members changed.
+func (t Timeline) InvalidateListTimeline(ctx context.Context, listID int64) error {
+
+	if err := t.markStale(ctx, t.getListCacheKey(listID)); err != nil {
+		return fmt.Errorf("failed to invalidate timeline of list %d: %w", listID, err)
+	}
+
+	return nil
+}
+
+// getListCacheKey constructs the cache key for a list timeline.
+func (t Timeline) getListCacheKey(listID int64) string {
+	return fmt.Sprintf(ListCacheKey, listID)
+}
+
+// timelineSource reads a timeline from the database when its cache can't serve a page.
+type timelineSource struct {
+	// selectPage reads the requested page
+	selectPage func(ctx context.Context) ([]domain.Tweet, error)
+	// selectWindow reads the IDs of the newest MaxCachedTweets tweets, to rebuild the cache
+	selectWindow func(ctx context.Context) ([]int64, error)
+}
+
+// readTimeline serves a page of a timeline cached as a list of tweet IDs under cacheKey.
+// The cached list is the source of truth for the newest MaxCachedTweets tweets: every page
+// within it is served from the cache, and when it ends with TimelineEndMarker it holds the
+// whole timeline so short last pages and pages past the end are served from it too. The
+// database is only read for pages beyond the cached window.
+// Tweets written by hiddenUserIDs must never be returned. A shared cache is read by
+// several viewers, so it is not rebuilt for a viewer hiding anybody.
+// The tweets of excludeUserID (0 for nobody) are kept in the cache but left out of pages:
+// offset counts the other tweets, see hydratePage.
+// On a miss the previous version of the timeline, kept for a while when it was invalidated,
+// is served while the timeline is rebuilt in the background (stale-while-revalidate).
+// Rebuilds are coalesced so a cold cache causes one database query, see loadTimeline.
+func (t Timeline) readTimeline(ctx context.Context, cacheKey string, limit, offset int, hiddenUserIDs []int64, excludeUserID int64, shared bool, source timelineSource) ([]domain.Tweet, error) {
+
+	// STEP 1: Try to get the page from cache
+	tweets, hit, err := t.readCachedPage(ctx, cacheKey, limit, offset, hiddenUserIDs, excludeUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// STEP 2: Handle cache hit - a full page, or the end of the timeline
+	if hit {
+		return tweets, nil
+	}
+
+	// STEP 3: Cache miss or partial miss
 	// This happens when:
-	// - Redis doesn't have the key (err == redis.Nil)
-	// - Redis returned fewer IDs than requested (partial miss)
+	// - Redis doesn't have the key (never cached, expired or invalidated)
+	// - Redis returned fewer IDs than requested without reaching the end marker, either
+	//   because the page is beyond the cached window or because the list was only filled
+	//   by the fan-out
 	// - DB fetch by IDs failed
-	tweets, err := t.tweetRepository.SelectTimelineTweets(ctx, userID, limit, offset)
+	// - The cache was written before a mute or block and not rebuilt yet
+	// - Without excludeUserID's tweets, the cached window is too short for the page
+	cacheable := offset+limit <= MaxCachedTweets && (!shared || len(hiddenUserIDs) == 0)
+	if cacheable && excludeUserID != 0 {
+		cacheable = !t.isCachedWindowFull(ctx, cacheKey)
+	}
+	flightKey := fmt.Sprintf("%s:%d:%d:%v:%d", cacheKey, limit, offset, hiddenUserIDs, excludeUserID)
+
+	// STEP 4: Serve the invalidated version, without hidden authors, and rebuild in the background
+	if cacheable {
+		if tweets, ok := t.readStaleTimeline(ctx, cacheKey, limit, offset, hiddenUserIDs, excludeUserID); ok {
+			// The request context, often a pooled gin context, can't outlive the request
+			go func() {
+				if _, err := t.loadTimeline(context.Background(), cacheKey, flightKey, limit, offset, hiddenUserIDs, excludeUserID, cacheable, source); err != nil {
+					log.Printf("Failed to rebuild timeline %s: %v", cacheKey, err)
+				}
+			}()
+			return tweets, nil
+		}
+	}
+
+	// STEP 5: Nothing to serve, read the database (and populate the cache)
+	return t.loadTimeline(ctx, cacheKey, flightKey, limit, offset, hiddenUserIDs, excludeUserID, cacheable, source)
+}
+
+// loadTimeline reads a page of a timeline from the database. When the page is cacheable
+// the whole cached window is rebuilt and the page is cut from it.
+// Concurrent loads of the same page in this replica share a single query (singleflight).
+// Across replicas a short-lived Redis lock lets only one of them rebuild the cache: the
+// others wait for the cache to be filled and only read the database if it takes too long.
+func (t Timeline) loadTimeline(ctx context.Context, cacheKey, flightKey string, limit, offset int, hiddenUserIDs []int64, excludeUserID int64, cacheable bool, source timelineSource) ([]domain.Tweet, error) {
+
+	result, err, _ := t.rebuilds.Do(flightKey, func() (interface{}, error) {
+
+		// The query is shared: a caller going away must not cancel it for the others
+		ctx := context.WithoutCancel(ctx)
+
+		if !cacheable {
+			return source.selectPage(ctx)
+		}
+
+		lockToken, locked := t.acquireRebuildLock(ctx, cacheKey)
+		if !locked {
+			if tweets, ok := t.waitForRebuild(ctx, cacheKey, limit, offset, hiddenUserIDs, excludeUserID); ok {
+				return tweets, nil
+			}
+			return source.selectPage(ctx)
+		}
+		defer t.releaseRebuildLock(ctx, cacheKey, lockToken)
+
+		tweetIDs, err := source.selectWindow(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.cacheTimelineTweetIDs(ctx, cacheKey, tweetIDs); err != nil {
+			log.Print(err)
+		}
+
+		// Without excludeUserID's tweets the window may be too short for the page
+		tweets, err := t.hydratePage(ctx, tweetIDs, limit, offset, excludeUserID)
+		if err != nil || (len(tweets) < limit && len(tweetIDs) == MaxCachedTweets) {
+			return source.selectPage(ctx)
+		}
+
+		return tweets, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// STEP 4: Populate cache with results (only if offset is 0)
-	// We only cache the "fresh" timeline (page 1) to keep cache simple
-	// Deeper pages will always hit the database
-	if offset == 0 && len(tweets) > 0 {
-		go t.cacheTimelineTweets(context.Background(), userID, tweets)
+	return result.([]domain.Tweet), nil
+}
+
+// readCachedPage serves a page from a cached timeline. It reports a miss when the cache
+// doesn't hold the page, or holds tweets of hidden authors.
+func (t Timeline) readCachedPage(ctx context.Context, cacheKey string, limit, offset int, hiddenUserIDs []int64, excludeUserID int64) ([]domain.Tweet, bool, error) {
+
+	tweets, complete, err := t.readCachedTweets(ctx, cacheKey, limit, offset, excludeUserID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if tweets == nil || (len(tweets) < limit && !complete) || containsHiddenAuthor(tweets, hiddenUserIDs) {
+		return nil, false, nil
 	}
 
-	return tweets, nil
+	return tweets, true, nil
 }
 
-// cacheTimelineTweets stores tweet IDs in Redis for future cache hits.
-// This runs asynchronously to avoid blocking the request.
-func (t Timeline) cacheTimelineTweets(ctx context.Context, userID int64, tweets []domain.Tweet) {
-	if len(tweets) == 0 {
-		return
+// readStaleTimeline reads a page of the invalidated version of a timeline.
+// Tweets of hidden authors are dropped, the invalidation was often caused by hiding them.
+func (t Timeline) readStaleTimeline(ctx context.Context, cacheKey string, limit, offset int, hiddenUserIDs []int64, excludeUserID int64) ([]domain.Tweet, bool) {
+
+	tweets, complete, err := t.readCachedTweets(ctx, t.getStaleCacheKey(cacheKey), limit, offset, excludeUserID)
+	if err != nil || tweets == nil || (len(tweets) < limit && !complete) {
+		return nil, false
 	}
 
-	cacheKey := t.getCacheKey(userID)
+	return removeHiddenAuthors(tweets, hiddenUserIDs), true
+}
 
-	// Extract tweet IDs in order
-	tweetIDs := make([]interface{}, len(tweets))
-	for i, tweet := range tweets {
-		tweetIDs[i] = fmt.Sprintf("%d", tweet.ID)
+// readCachedTweets reads and hydrates a page of a cached timeline, and reports whether the
+// cached list ends after it. Without excludeUserID only the page is read from the list,
+// otherwise the whole list is read and the page is cut once their tweets are left out.
+// The tweets are nil when they couldn't be hydrated.
+func (t Timeline) readCachedTweets(ctx context.Context, cacheKey string, limit, offset int, excludeUserID int64) ([]domain.Tweet, bool, error) {
+
+	if excludeUserID == 0 {
+		tweetIDs, complete, err := t.retrieveCachedPage(ctx, cacheKey, limit, offset)
+		if err != nil || (len(tweetIDs) < limit && !complete) {
+			return []domain.Tweet{}, complete, err
+		}
+
+		tweets, err := t.hydrateTweets(ctx, tweetIDs)
+		if err != nil {
+			return nil, false, nil
+		}
+		return tweets, complete, nil
+	}
+
+	tweetIDs, complete, err := t.retrieveCachedPage(ctx, cacheKey, MaxCachedTweets, 0)
+	if err != nil {
+		return nil, false, err
 	}
 
-	// DISCLAIMER: These operations should be atomic
-	// Delete old cache and push new IDs
-	_ = t.cache.Delete(ctx, cacheKey)
+	tweets, err := t.hydratePage(ctx, tweetIDs, limit, offset, excludeUserID)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return tweets, complete, nil
+}
+
+// hydratePage hydrates the page at offset of tweetIDs once the tweets written by
+// excludeUserID (0 for nobody) are left out. The authors are only known once hydrated,
+// so tweets are hydrated in order, a page worth at a time, until the page is filled:
+// a warm page needs no SQL either way.
+func (t Timeline) hydratePage(ctx context.Context, tweetIDs []int64, limit, offset int, excludeUserID int64) ([]domain.Tweet, error) {
+
+	if excludeUserID == 0 {
+		return t.hydrateTweets(ctx, pageOf(tweetIDs, limit, offset))
+	}
 
-	// Push all IDs using RPush to maintain the order from DB (newest first)
-	if err := t.cache.RPush(ctx, cacheKey, tweetIDs...); err != nil {
+	kept := make([]domain.Tweet, 0, offset+limit)
+	for start := 0; start < len(tweetIDs) && len(kept) < offset+limit; {
+		end := min(start+offset+limit-len(kept), len(tweetIDs))
+
+		tweets, err := t.hydrateTweets(ctx, tweetIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tweet := range tweets {
+			if tweet.UserID != excludeUserID {
+				kept = append(kept, tweet)
+			}
+		}
+		start = end
+	}
+
+	return kept[min(offset, len(kept)):min(offset+limit, len(kept))], nil
+}
+
+// isCachedWindowFull reports whether a cached timeline holds a whole window of tweets,
+// so rebuilding it would not serve more of them.
+func (t Timeline) isCachedWindowFull(ctx context.Context, cacheKey string) bool {
+
+	length, err := t.cache.LLen(ctx, cacheKey)
+	if err != nil {
+		return false
+	}
+
+	return length >= MaxCachedTweets
+}
+
+// waitForRebuild polls the cache while another replica rebuilds a timeline and returns
+// the page once it is there. It gives up after RebuildWait.
+func (t Timeline) waitForRebuild(ctx context.Context, cacheKey string, limit, offset int, hiddenUserIDs []int64, excludeUserID int64) ([]domain.Tweet, bool) {
+
+	deadline := time.Now().Add(RebuildWait)
+	for time.Now().Before(deadline) {
+		time.Sleep(rebuildPollInterval)
+
+		tweets, hit, err := t.readCachedPage(ctx, cacheKey, limit, offset, hiddenUserIDs, excludeUserID)
+		if err == nil && hit {
+			return tweets, true
+		}
+	}
+
+	return nil, false
+}
+
+// acquireRebuildLock takes the lock to rebuild a cached timeline and returns its token.
+// If Redis can't be reached the rebuild goes ahead: the cache write will fail anyway.
+func (t Timeline) acquireRebuildLock(ctx context.Context, cacheKey string) (string, bool) {
+
+	token := strconv.FormatUint(rand.Uint64(), 36)
+
+	locked, err := t.cache.SetNX(ctx, fmt.Sprintf(RebuildLockKey, cacheKey), token, RebuildLockTimeout)
+	if err != nil {
+		log.Printf("Failed to lock rebuild of %s: %v", cacheKey, err)
+		return "", true
+	}
+
+	return token, locked
+}
+
+// releaseRebuildLock releases a rebuild lock, unless it expired and was taken by someone else.
+func (t Timeline) releaseRebuildLock(ctx context.Context, cacheKey, token string) {
+
+	if token == "" {
 		return
 	}
 
-	// Trim to keep only the most recent tweets
-	_ = t.cache.LTrim(ctx, cacheKey, 0, MaxCachedTweets-1)
+	if _, err := t.cache.DeleteIfEquals(ctx, fmt.Sprintf(RebuildLockKey, cacheKey), token); err != nil {
+		log.Printf("Failed to release rebuild lock of %s: %v", cacheKey, err)
+	}
+}
 
-	// Set expiration time for the cache
-	_ = t.cache.Expire(ctx, cacheKey, CacheExpiration)
+// markStale invalidates a cached timeline, keeping it for StaleExpiration so it can be
+// served while it is rebuilt.
+func (t Timeline) markStale(ctx context.Context, cacheKey string) error {
+	return t.cache.RenameIfExists(ctx, cacheKey, t.getStaleCacheKey(cacheKey), StaleExpiration)
+}
 
+// getStaleCacheKey constructs the key of the invalidated version of a cached timeline.
+func (t Timeline) getStaleCacheKey(cacheKey string) string {
+	return fmt.Sprintf(StaleCacheKey, cacheKey)
+}
+
+// getHiddenUserIDs returns the users whose tweets must not appear in userID's timeline:
+// the users they muted and the users on either side of a block. They are cached under
+// HiddenUsersCacheKey, so a warm read doesn't query the database for them.
+func (t Timeline) getHiddenUserIDs(ctx context.Context, userID int64) ([]int64, error) {
+
+	cacheKey := t.getHiddenUsersCacheKey(userID)
+
+	// A cache failure is not fatal, the users are then read from the database
+	entries, err := t.cache.MGet(ctx, []string{cacheKey})
+	if err != nil {
+		log.Printf("Failed to read hidden users of user %d: %v", userID, err)
+	} else if hiddenUserIDs, ok := parseCachedIDs(entries[0]); ok {
+		return hiddenUserIDs, nil
+	}
+
+	mutedIDs, err := t.muteRepository.SelectMutedIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedIDs, err := t.blockRepository.SelectBlockedOrBlockingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hiddenUserIDs := append(mutedIDs, blockedIDs...)
+
+	entry, err := json.Marshal(hiddenUserIDs)
+	if err == nil {
+		err = t.cache.SetMany(ctx, map[string]interface{}{cacheKey: string(entry)}, HiddenUsersCacheExpiration)
+	}
+	if err != nil {
+		log.Printf("Failed to cache hidden users of user %d: %v", userID, err)
+	}
+
+	return hiddenUserIDs, nil
+}
+
+// parseCachedIDs parses a cached JSON array of IDs, reporting false on a miss.
+func parseCachedIDs(entry string) ([]int64, bool) {
+
+	if entry == "" {
+		return nil, false
+	}
+
+	var ids []int64
+	if err := json.Unmarshal([]byte(entry), &ids); err != nil {
+		return nil, false
+	}
+
+	return ids, true
+}
+
+// InvalidateHiddenUsers drops the cached hidden users of users who muted, blocked or were
+// blocked, or removed a mute or block.
+func (t Timeline) InvalidateHiddenUsers(ctx context.Context, userIDs ...int64) error {
+
+	for _, userID := range userIDs {
+		if err := t.cache.Delete(ctx, t.getHiddenUsersCacheKey(userID)); err != nil {
+			return fmt.Errorf("failed to invalidate hidden users of user %d: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// getHiddenUsersCacheKey constructs the cache key for the hidden users of a user.
+func (t Timeline) getHiddenUsersCacheKey(userID int64) string {
+	return fmt.Sprintf(HiddenUsersCacheKey, userID)
+}
+
+// containsHiddenAuthor reports whether any of the tweets was written by a hidden user.
+func containsHiddenAuthor(tweets []domain.Tweet, hiddenUserIDs []int64) bool {
+
+	if len(hiddenUserIDs) == 0 {
+		return false
+	}
+
+	hidden := make(map[int64]bool, len(hiddenUserIDs))
+	for _, id := range hiddenUserIDs {
+		hidden[id] = true
+	}
+
+	for _, tweet := range tweets {
+		if hidden[tweet.UserID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeHiddenAuthors drops the tweets written by hiddenUserIDs, keeping the order.
+func removeHiddenAuthors(tweets []domain.Tweet, hiddenUserIDs []int64) []domain.Tweet {
+
+	if !containsHiddenAuthor(tweets, hiddenUserIDs) {
+		return tweets
+	}
+
+	hidden := make(map[int64]bool, len(hiddenUserIDs))
+	for _, id := range hiddenUserIDs {
+		hidden[id] = true
+	}
+
+	visible := make([]domain.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if !hidden[tweet.UserID] {
+			visible = append(visible, tweet)
+		}
+	}
+
+	return visible
+}
+
+// InvalidateTimeline drops the cached timeline of a user so it is rebuilt from the
+// database on the next read, e.g. after a block or mute changed what they can see.
+func (t Timeline) InvalidateTimeline(ctx context.Context, userID int64) error {
+
+	if err := t.markStale(ctx, t.getCacheKey(userID)); err != nil {
+		return fmt.Errorf("failed to invalidate timeline of user %d: %w", userID, err)
+	}
+
+	return nil
+}
+
+// BackfillTimeline adds the recent tweets of newly followed users to the follower's
+// cached timeline, so they show up right away instead of only from their next tweet on.
+// Only tweets newer than the oldest cached entry are merged: the cache holds the newest
+// part of the timeline and older tweets are read from the database anyway. A cache
+// holding the whole timeline must stay complete, so all their tweets are merged into it.
+// A bulk import passes all the users it followed so the timeline is rewritten once.
+func (t Timeline) BackfillTimeline(ctx context.Context, followerID int64, followedIDs ...int64) error {
+
+	cacheKey := t.getCacheKey(followerID)
+
+	cachedIDs, complete, err := t.retrieveCachedPage(ctx, cacheKey, MaxCachedTweets, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read timeline of user %d: %w", followerID, err)
+	}
+
+	// Timelines that are not cached are built from the database on the next read
+	if (len(cachedIDs) == 0 && !complete) || len(followedIDs) == 0 {
+		return nil
+	}
+
+	var oldestCachedID int64
+	if !complete {
+		oldestCachedID = cachedIDs[len(cachedIDs)-1]
+	}
+
+	tweetIDs, err := t.tweetRepository.SelectTweetIDsByAuthors(ctx, followedIDs, oldestCachedID, MaxCachedTweets)
+	if err != nil {
+		return fmt.Errorf("failed to get tweets of users %v: %w", followedIDs, err)
+	}
+
+	merged := make(map[int64]bool, len(cachedIDs)+len(tweetIDs))
+	for _, id := range cachedIDs {
+		merged[id] = true
+	}
+	for _, id := range tweetIDs {
+		merged[id] = true
+	}
+
+	if len(merged) == len(cachedIDs) {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	if err := t.cache.ReplaceList(ctx, cacheKey, timelineEntries(ids, complete), MaxCachedTweets, CacheExpiration); err != nil {
+		return fmt.Errorf("failed to backfill timeline of user %d: %w", followerID, err)
+	}
+
+	return nil
+}
+
+// cacheTimelineTweetIDs stores the newest tweet IDs of a timeline in Redis for future cache
+// hits. tweetIDs is everything the database returned for the cached window, so fewer than
+// MaxCachedTweets IDs are the whole timeline.
+func (t Timeline) cacheTimelineTweetIDs(ctx context.Context, cacheKey string, tweetIDs []int64) error {
+
+	// Replace the old list atomically, keeping the order from DB (newest first)
+	entries := timelineEntries(tweetIDs, len(tweetIDs) < MaxCachedTweets)
+	if err := t.cache.ReplaceList(ctx, cacheKey, entries, MaxCachedTweets, CacheExpiration); err != nil {
+		return fmt.Errorf("failed to cache timeline %s: %w", cacheKey, err)
+	}
+
+	// The invalidated version is not needed anymore
+	_ = t.cache.Delete(ctx, t.getStaleCacheKey(cacheKey))
+
+	return nil
+}
+
+// timelineEntries converts tweet IDs to cached list entries, followed by the end marker
+// when they are the whole timeline. A full window has no room left for the marker.
+func timelineEntries(tweetIDs []int64, complete bool) []interface{} {
+
+	entries := make([]interface{}, 0, len(tweetIDs)+1)
+	for _, id := range tweetIDs {
+		entries = append(entries, strconv.FormatInt(id, 10))
+	}
+
+	if complete && len(tweetIDs) < MaxCachedTweets {
+		entries = append(entries, TimelineEndMarker)
+	}
+
+	return entries
+}
+
+// pageOf cuts a page out of the IDs of a timeline.
+func pageOf(tweetIDs []int64, limit, offset int) []int64 {
+
+	if offset >= len(tweetIDs) {
+		return []int64{}
+	}
+
+	return tweetIDs[offset:min(offset+limit, len(tweetIDs))]
 }
 
 // getCacheKey constructs the cache key for a user's timeline.
@@ -128,28 +915,55 @@ func (t Timeline) getCacheKey(userID int64) string {
 	return fmt.Sprintf(CacheKey, userID)
 }
 
-// retrieveCacheTweetIDs retrieves tweet IDs from cache.
-func (t Timeline) retrieveCacheTweetIDs(ctx context.Context, userID int64, limit, offset int) ([]int64, error) {
+// retrieveCachedPage retrieves a page of tweet IDs from cache. complete reports that the
+// cached list ends within the page (or before it), so no more tweets exist: a short page
+// is then the last one instead of a partial miss.
+func (t Timeline) retrieveCachedPage(ctx context.Context, cacheKey string, limit, offset int) ([]int64, bool, error) {
 
-	cacheKey := t.getCacheKey(userID)
+	// One entry past the page tells whether the end marker follows it
 	startIdx := int64(offset)
-	stopIdx := int64(offset + limit - 1)
+	stopIdx := int64(offset + limit)
 
-	tweetIDs, err := t.cache.LRange(ctx, cacheKey, startIdx, stopIdx)
+	entries, err := t.cache.LRange(ctx, cacheKey, startIdx, stopIdx)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	// Past the end of the list: it is either complete or not cached at all
+	if len(entries) == 0 && offset > 0 {
+		entries, err = t.cache.LRange(ctx, cacheKey, -1, -1)
+		if err != nil {
+			return nil, false, err
+		}
+		return []int64{}, len(entries) == 1 && entries[0] == TimelineEndMarker, nil
 	}
 
-	ids := make([]int64, 0, len(tweetIDs))
-	for _, idStr := range tweetIDs {
-		id, err := strconv.ParseInt(idStr, 10, 64)
+	ids, complete := parseCachedPage(entries, limit)
+
+	return ids, complete, nil
+}
+
+// parseCachedPage parses the entries of a cached timeline, up to limit tweet IDs, and
+// reports whether the end marker was reached.
+func parseCachedPage(entries []string, limit int) ([]int64, bool) {
+
+	ids := make([]int64, 0, min(len(entries), limit))
+	for _, entry := range entries {
+		if entry == TimelineEndMarker {
+			return ids, true
+		}
+		if len(ids) == limit {
+			break
+		}
+
+		id, err := strconv.ParseInt(entry, 10, 64)
 		if err != nil {
 			continue
 		}
 		ids = append(ids, id)
 	}
 
-	return ids, nil
+	return ids, false
 }
 
 // FanOutTweet distributes a new tweet to all followers' timeline caches.
@@ -161,35 +975,288 @@ func (t Timeline) FanOutTweet(ctx context.Context, authorID int64, tweetID int64
 		return fmt.Errorf("failed to get followers: %w", err)
 	}
 
-	// If author has no followers, nothing to do
+	// If author has no followers, only their own timeline and the lists they are a
+	// member of need the tweet
+	tweetIDStr := fmt.Sprintf("%d", tweetID)
 	if len(followerIDs) == 0 {
+		if err := t.cache.PushCapped(ctx, []string{t.getCacheKey(authorID)}, tweetIDStr, MaxCachedTweets, CacheExpiration); err != nil {
+			log.Printf("Failed to add tweet %d to the timeline of its author: %v", tweetID, err)
+		}
+		t.publishTimelineEvents(ctx, tweetID, []int64{authorID})
+		t.fanOutToLists(ctx, authorID, tweetIDStr)
 		return nil
 	}
 
-	// Step 2: Add tweet to each follower's timeline cache
-	tweetIDStr := fmt.Sprintf("%d", tweetID)
+	// Followers who muted the author don't get the tweet
+	muterIDs, err := t.muteRepository.SelectMuterIDs(ctx, authorID)
+	if err != nil {
+		return fmt.Errorf("failed to get muters: %w", err)
+	}
+
+	muters := make(map[int64]bool, len(muterIDs))
+	for _, muterID := range muterIDs {
+		muters[muterID] = true
+	}
+
+	recipientIDs := make([]int64, 0, len(followerIDs))
 	for _, followerID := range followerIDs {
-		cacheKey := t.getCacheKey(followerID)
+		if !muters[followerID] {
+			recipientIDs = append(recipientIDs, followerID)
+		}
+	}
+
+	// Dormant followers don't get the tweet, their timeline is rebuilt when they come back
+	activeIDs := t.selectActiveUserIDs(ctx, recipientIDs)
+
+	// Step 2: Add tweet to each follower's timeline cache (newest first), trimmed to
+	// the latest MaxCachedTweets. Followers are sent in pipelined batches.
+	// The author's own timeline shows their tweets too.
+	cacheKeys := make([]string, 0, len(activeIDs)+1)
+	cacheKeys = append(cacheKeys, t.getCacheKey(authorID))
+	for _, followerID := range activeIDs {
+		cacheKeys = append(cacheKeys, t.getCacheKey(followerID))
+	}
+
+	if err := t.cache.PushCapped(ctx, cacheKeys, tweetIDStr, MaxCachedTweets, CacheExpiration); err != nil {
+		// Log error but continue: the other timelines got the tweet
+		log.Printf("Failed to add tweet %d to follower timelines: %v", tweetID, err)
+	}
+
+	t.recordFanOutStats(ctx, tweetID, len(cacheKeys), len(recipientIDs)-len(activeIDs))
+
+	// Step 3: Stream the tweet to the users connected to their timeline. A connected
+	// follower may be dormant for the fan-out, they are picked among all recipients.
+	t.publishTimelineEvents(ctx, tweetID, append([]int64{authorID}, recipientIDs...))
+
+	// Step 4: Add tweet to the timelines of the lists the author is a member of
+	t.fanOutToLists(ctx, authorID, tweetIDStr)
+
+	return nil
+}
+
+// recordFanOutStats counts the timeline writes made by a fan-out and the ones saved by
+// skipping dormant followers.
+func (t Timeline) recordFanOutStats(ctx context.Context, tweetID int64, written, skipped int) {
+
+	log.Printf("Fan-out of tweet %d: %d timelines written, %d inactive followers skipped", tweetID, written, skipped)
+
+	if err := t.cache.HIncrBy(ctx, FanOutStatsKey, "writes", int64(written)); err != nil {
+		log.Printf("Failed to update fan-out stats: %v", err)
+		return
+	}
+
+	if err := t.cache.HIncrBy(ctx, FanOutStatsKey, "skipped", int64(skipped)); err != nil {
+		log.Printf("Failed to update fan-out stats: %v", err)
+	}
+}
+
+// fanOutToLists pushes a new tweet to the cached timelines of the lists its author is a
+// member of, which is what their subscribers read. Lists that are not cached are left
+// alone: they are built from the database on the next read.
+func (t Timeline) fanOutToLists(ctx context.Context, authorID int64, tweetIDStr string) {
+
+	listIDs, err := t.listRepository.SelectListIDsByMemberID(ctx, authorID)
+	if err != nil {
+		log.Printf("Failed to get lists of user %d: %v", authorID, err)
+		return
+	}
+
+	cacheKeys := make([]string, len(listIDs))
+	for i, listID := range listIDs {
+		cacheKeys[i] = t.getListCacheKey(listID)
+	}
+
+	if err := t.cache.PushCappedIfExists(ctx, cacheKeys, tweetIDStr, MaxCachedTweets, 0); err != nil {
+		log.Printf("Failed to add tweet %s to list timelines: %v", tweetIDStr, err)
+	}
+}
+
+// GetUserTweets returns the tweets written by a user (profile timeline), newest first.
+// Pagination uses the ID of the last tweet of the previous page as cursor. On the first
+// page the pinned tweet, if any, comes first; it is never repeated further down.
+// It returns the tweets, the pinned tweet ID and the cursor of the next page (0 at the end).
+// The tweets of a protected account return ErrProtectedAccount unless viewerID is an approved follower.
+func (t Timeline) GetUserTweets(ctx context.Context, userID, viewerID int64, limit int, cursor int64, filter domain.TweetFilter) ([]domain.Tweet, int64, int64, error) {
+
+	// Set default and max values for pagination
+	if limit <= 0 {
+		limit = config.DefaultLimit
+	}
+
+	if limit > config.MaxLimit {
+		limit = config.MaxLimit
+	}
+
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	user, err := t.userRepository.SelectByID(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	visible, err := canViewTweetsOf(ctx, t.followerRepository, viewerID, user)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !visible {
+		return nil, 0, 0, ErrProtectedAccount
+	}
+
+	pinnedTweetID, err := t.tweetRepository.SelectPinnedTweetID(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// STEP 1: Try to resolve the page from the cached list of the user's tweet IDs
+	tweets, cacheHit := t.retrieveCachedUserTweets(ctx, userID, limit, cursor, filter, pinnedTweetID)
 
-		// Add tweet ID to the beginning of the list (newest first) using LPUSH
-		if err := t.cache.LPush(ctx, cacheKey, tweetIDStr); err != nil {
-			// Log error but continue with other followers
-			log.Printf("Failed to add tweet %d to user %d timeline: %v", tweetID, followerID, err)
+	// STEP 2: Cache miss - fall back to database
+	if !cacheHit {
+		tweets, err = t.tweetRepository.SelectUserTweets(ctx, userID, cursor, limit, filter, pinnedTweetID)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		// Only the newest tweets are cached, rebuild the list when the first page was missed
+		if cursor == 0 {
+			go t.cacheUserTweets(context.Background(), userID)
+		}
+	}
+
+	var nextCursor int64
+	if len(tweets) == limit {
+		nextCursor = tweets[len(tweets)-1].ID
+	}
+
+	// STEP 3: Put the pinned tweet on top of the first page
+	if cursor == 0 && pinnedTweetID != 0 {
+		pinnedTweets, err := t.hydrateTweets(ctx, []int64{pinnedTweetID})
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		tweets = append(pinnedTweets, tweets...)
+	}
+
+	return tweets, pinnedTweetID, nextCursor, nil
+}
+
+// retrieveCachedUserTweets resolves a page of a user's tweets from cache.
+// It reports a miss when the list is not cached, or when the page goes beyond
+// the cached window and older tweets may exist in the database.
+func (t Timeline) retrieveCachedUserTweets(ctx context.Context, userID int64, limit int, cursor int64, filter domain.TweetFilter, excludeID int64) ([]domain.Tweet, bool) {
+
+	entries, err := t.cache.LRange(ctx, t.getUserTweetsCacheKey(userID), 0, MaxCachedUserTweets-1)
+	if err != nil || len(entries) == 0 {
+		return nil, false
+	}
+
+	ids := selectUserTweetIDs(entries, limit, cursor, filter, excludeID)
+
+	// A list shorter than the maximum holds every tweet of the user,
+	// so a short page means the end of the profile
+	if len(ids) < limit && len(entries) >= MaxCachedUserTweets {
+		return nil, false
+	}
+
+	tweets, err := t.hydrateTweets(ctx, ids)
+	if err != nil {
+		return nil, false
+	}
+
+	return tweets, true
+}
+
+// selectUserTweetIDs returns the IDs of a page of cached profile entries: up to limit
+// tweets older than cursor (0 for the first page) that match filter, except excludeID.
+// A tweet pushed while its list was being rebuilt from the database can be listed twice,
+// only its first entry is kept.
+func selectUserTweetIDs(entries []string, limit int, cursor int64, filter domain.TweetFilter, excludeID int64) []int64 {
+
+	ids := make([]int64, 0, limit)
+	seen := make(map[int64]bool, limit)
+	for _, entry := range entries {
+		id, isReply, isRetweet, ok := parseUserTweetEntry(entry)
+		if !ok || id == excludeID || seen[id] || (cursor != 0 && id >= cursor) {
 			continue
 		}
+		seen[id] = true
 
-		// Trim to keep only the latest MaxCachedTweets
-		if err := t.cache.LTrim(ctx, cacheKey, 0, MaxCachedTweets-1); err != nil {
-			// Log error but continue
-			log.Printf("Failed to trim cache for user %d: %v", followerID, err)
+		if !filter.Matches(isReply, isRetweet) {
+			continue
 		}
 
-		// Update expiration
-		if err := t.cache.Expire(ctx, cacheKey, CacheExpiration); err != nil {
-			// Log error but continue
-			log.Printf("Failed to set expiration for user %d: %v", followerID, err)
+		ids = append(ids, id)
+		if len(ids) == limit {
+			break
 		}
 	}
 
+	return ids
+}
+
+// cacheUserTweets rebuilds the cached list of a user's newest tweet IDs from the database.
+func (t Timeline) cacheUserTweets(ctx context.Context, userID int64) {
+
+	allTweets := domain.TweetFilter{IncludeReplies: true, IncludeRetweets: true}
+	tweets, err := t.tweetRepository.SelectUserTweets(ctx, userID, 0, MaxCachedUserTweets, allTweets, 0)
+	if err != nil || len(tweets) == 0 {
+		return
+	}
+
+	entries := make([]interface{}, len(tweets))
+	for i, tweet := range tweets {
+		entries[i] = formatUserTweetEntry(tweet)
+	}
+
+	cacheKey := t.getUserTweetsCacheKey(userID)
+
+	if err := t.cache.ReplaceList(ctx, cacheKey, entries, MaxCachedUserTweets, CacheExpiration); err != nil {
+		log.Printf("Failed to cache tweets of user %d: %v", userID, err)
+	}
+}
+
+// PushUserTweet adds a new tweet to its author's cached profile list.
+// Lists that are not cached are left alone: they are built from the database on the next read.
+func (t Timeline) PushUserTweet(ctx context.Context, tweet domain.Tweet) error {
+
+	cacheKey := t.getUserTweetsCacheKey(tweet.UserID)
+
+	if err := t.cache.PushCappedIfExists(ctx, []string{cacheKey}, formatUserTweetEntry(tweet), MaxCachedUserTweets, 0); err != nil {
+		return fmt.Errorf("failed to add tweet %d to user %d tweets: %w", tweet.ID, tweet.UserID, err)
+	}
+
 	return nil
 }
+
+// getUserTweetsCacheKey constructs the cache key for a user's own tweets.
+func (t Timeline) getUserTweetsCacheKey(userID int64) string {
+	return fmt.Sprintf(UserTweetsCacheKey, userID)
+}
+
+// formatUserTweetEntry encodes a tweet ID with its kind, e.g. "42", "43:r" or "44:t".
+func formatUserTweetEntry(tweet domain.Tweet) string {
+	entry := strconv.FormatInt(tweet.ID, 10)
+	if tweet.IsReply() {
+		entry += replySuffix
+	}
+	if tweet.IsRetweet() {
+		entry += retweetSuffix
+	}
+	return entry
+}
+
+// parseUserTweetEntry decodes an entry written by formatUserTweetEntry.
+func parseUserTweetEntry(entry string) (id int64, isReply, isRetweet bool, ok bool) {
+	isReply = strings.HasSuffix(entry, replySuffix)
+	isRetweet = strings.HasSuffix(entry, retweetSuffix)
+	entry = strings.TrimSuffix(strings.TrimSuffix(entry, replySuffix), retweetSuffix)
+
+	id, err := strconv.ParseInt(entry, 10, 64)
+	if err != nil {
+		return 0, false, false, false
+	}
+
+	return id, isReply, isRetweet, true
+}
@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"twitter-demo/internal/domain"
+)
+
+const (
+	// RebuildCheckpointKey holds the last user whose timeline was rebuilt by
+	// RebuildAllTimelines, to resume an interrupted run
+	RebuildCheckpointKey = "admin:rebuild:checkpoint"
+	// rebuildBatchSize is how many users are read at once by RebuildAllTimelines
+	rebuildBatchSize = 500
+	// scanBatchSize is how many keys are asked per SCAN
+	scanBatchSize = 1000
+)
+
+// ErrRebuildInProgress is returned when a timeline is already being rebuilt, by a read
+// or by another run.
+var ErrRebuildInProgress = errors.New("timeline is being rebuilt")
+
+// TimelineAdminUsecase inspects and repairs the cached timelines, for the admin command.
+type TimelineAdminUsecase interface {
+	RebuildTimeline(ctx context.Context, userID int64) (int, error)
+	RebuildAllTimelines(ctx context.Context, options RebuildOptions) (int, error)
+	DiffTimeline(ctx context.Context, userID int64) (domain.TimelineDiff, error)
+	EvictTimeline(ctx context.Context, userID int64) error
+	EvictKeys(ctx context.Context, pattern string) (int, error)
+	GetCacheStats(ctx context.Context, pattern string) (domain.CacheStats, error)
+	GetFanOutStats(ctx context.Context) (map[string]int64, error)
+}
+
+// RebuildOptions controls a rebuild of every timeline.
+type RebuildOptions struct {
+	AfterID int64   // Only rebuild the users after this ID
+	Resume  bool    // Start after the checkpoint of the previous run, overrides AfterID
+	Rate    float64 // Timelines rebuilt per second, 0 for no limit
+}
+
+// RebuildTimeline replaces the cached timeline of a user with the newest tweets read from
+// the database, and returns how many tweet IDs were cached.
+func (t Timeline) RebuildTimeline(ctx context.Context, userID int64) (int, error) {
+
+	hiddenUserIDs, err := t.getHiddenUserIDs(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	cacheKey := t.getCacheKey(userID)
+
+	lockToken, locked := t.acquireRebuildLock(ctx, cacheKey)
+	if !locked {
+		return 0, ErrRebuildInProgress
+	}
+	defer t.releaseRebuildLock(ctx, cacheKey, lockToken)
+
+	tweetIDs, err := t.tweetRepository.SelectTimelineTweetIDs(ctx, userID, MaxCachedTweets, hiddenUserIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := t.cacheTimelineTweetIDs(ctx, cacheKey, tweetIDs); err != nil {
+		return 0, err
+	}
+
+	return len(tweetIDs), nil
+}
+
+// RebuildAllTimelines rebuilds the timeline of every user, in ID order, at most
+// options.Rate per second. The last user done is saved after every batch, so an
+// interrupted run can be resumed. It returns how many timelines were rebuilt.
+func (t Timeline) RebuildAllTimelines(ctx context.Context, options RebuildOptions) (int, error) {
+
+	afterID := options.AfterID
+	if options.Resume {
+		checkpoint, err := t.readRebuildCheckpoint(ctx)
+		if err != nil {
+			return 0, err
+		}
+		afterID = checkpoint
+	}
+
+	var throttle <-chan time.Time
+	if options.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / options.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	log.Printf("Rebuilding timelines of users after %d", afterID)
+
+	rebuilt := 0
+	for {
+		userIDs, err := t.userRepository.SelectIDsAfter(ctx, afterID, rebuildBatchSize)
+		if err != nil {
+			return rebuilt, err
+		}
+
+		if len(userIDs) == 0 {
+			break
+		}
+
+		for _, userID := range userIDs {
+			if throttle != nil {
+				select {
+				case <-ctx.Done():
+					return rebuilt, ctx.Err()
+				case <-throttle:
+				}
+			}
+
+			if ctx.Err() != nil {
+				return rebuilt, ctx.Err()
+			}
+
+			// A timeline rebuilt by a read right now is fresh anyway
+			if _, err := t.RebuildTimeline(ctx, userID); err != nil && !errors.Is(err, ErrRebuildInProgress) {
+				return rebuilt, fmt.Errorf("failed to rebuild timeline of user %d: %w", userID, err)
+			}
+
+			rebuilt++
+			afterID = userID
+		}
+
+		if err := t.cache.SetMany(ctx, map[string]interface{}{RebuildCheckpointKey: afterID}, 0); err != nil {
+			return rebuilt, fmt.Errorf("failed to save rebuild checkpoint: %w", err)
+		}
+
+		log.Printf("Rebuilt %d timelines, up to user %d", rebuilt, afterID)
+	}
+
+	// The run is over, the next one starts from the beginning
+	if err := t.cache.Delete(ctx, RebuildCheckpointKey); err != nil {
+		return rebuilt, fmt.Errorf("failed to clear rebuild checkpoint: %w", err)
+	}
+
+	return rebuilt, nil
+}
+
+// readRebuildCheckpoint returns the last user rebuilt by an interrupted run, 0 when there is none.
+func (t Timeline) readRebuildCheckpoint(ctx context.Context) (int64, error) {
+
+	values, err := t.cache.MGet(ctx, []string{RebuildCheckpointKey})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rebuild checkpoint: %w", err)
+	}
+
+	if values[0] == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(values[0], 10, 64)
+}
+
+// DiffTimeline compares the cached timeline of a user with the database.
+func (t Timeline) DiffTimeline(ctx context.Context, userID int64) (domain.TimelineDiff, error) {
+
+	diff := domain.TimelineDiff{UserID: userID}
+	cacheKey := t.getCacheKey(userID)
+
+	infos, err := t.cache.ListInfo(ctx, []string{cacheKey})
+	if err != nil {
+		return diff, err
+	}
+
+	entries, err := t.cache.LRange(ctx, cacheKey, 0, -1)
+	if err != nil {
+		return diff, err
+	}
+
+	cachedIDs, complete := parseCachedPage(entries, len(entries))
+
+	hiddenUserIDs, err := t.getHiddenUserIDs(ctx, userID)
+	if err != nil {
+		return diff, err
+	}
+
+	dbIDs, err := t.tweetRepository.SelectTimelineTweetIDs(ctx, userID, MaxCachedTweets, hiddenUserIDs)
+	if err != nil {
+		return diff, err
+	}
+
+	diff.Cached = len(entries) > 0
+	diff.CachedCount = len(cachedIDs)
+	diff.Complete = complete
+	diff.TTL = infos[0].TTL
+	diff.DBCount = len(dbIDs)
+	diff.Missing, diff.Extra = diffTimelineIDs(cachedIDs, dbIDs, complete)
+	diff.OutOfOrder = !slices.IsSortedFunc(cachedIDs, func(a, b int64) int { return cmp.Compare(b, a) })
+
+	return diff, nil
+}
+
+// diffTimelineIDs returns the IDs of dbIDs missing from cachedIDs and the IDs of cachedIDs
+// that are not in dbIDs. Only the range the cache covers is compared: down to its oldest
+// tweet, or the whole timeline when it is complete.
+func diffTimelineIDs(cachedIDs, dbIDs []int64, complete bool) ([]int64, []int64) {
+
+	if len(cachedIDs) == 0 && !complete {
+		return nil, nil
+	}
+
+	oldest := int64(math.MinInt64)
+	if !complete {
+		oldest = slices.Min(cachedIDs)
+	}
+
+	cached := make(map[int64]bool, len(cachedIDs))
+	for _, id := range cachedIDs {
+		cached[id] = true
+	}
+
+	inDB := make(map[int64]bool, len(dbIDs))
+	var missing []int64
+	for _, id := range dbIDs {
+		inDB[id] = true
+		if id >= oldest && !cached[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	var extra []int64
+	for _, id := range cachedIDs {
+		if !inDB[id] {
+			extra = append(extra, id)
+		}
+	}
+
+	return missing, extra
+}
+
+// EvictTimeline drops the cached timeline of a user and its invalidated version.
+// The next read rebuilds it.
+func (t Timeline) EvictTimeline(ctx context.Context, userID int64) error {
+
+	cacheKey := t.getCacheKey(userID)
+
+	for _, key := range []string{cacheKey, t.getStaleCacheKey(cacheKey)} {
+		if err := t.cache.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to evict %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// EvictKeys drops every key matching pattern and returns how many were dropped.
+func (t Timeline) EvictKeys(ctx context.Context, pattern string) (int, error) {
+
+	evicted := 0
+	err := t.scanKeys(ctx, pattern, func(keys []string) error {
+		for _, key := range keys {
+			if err := t.cache.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to evict %s: %w", key, err)
+			}
+			evicted++
+		}
+		return nil
+	})
+
+	return evicted, err
+}
+
+// GetCacheStats describes the lengths and times to live of the lists matching pattern.
+func (t Timeline) GetCacheStats(ctx context.Context, pattern string) (domain.CacheStats, error) {
+
+	stats := domain.CacheStats{Pattern: pattern}
+
+	var lengths, ttls []int64
+	err := t.scanKeys(ctx, pattern, func(keys []string) error {
+		infos, err := t.cache.ListInfo(ctx, keys)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range infos {
+			// Keys gone since they were scanned, or not lists
+			if info.Length == 0 {
+				continue
+			}
+
+			stats.Keys++
+			lengths = append(lengths, info.Length)
+			if info.TTL < 0 {
+				stats.NoExpiry++
+				continue
+			}
+			ttls = append(ttls, int64(info.TTL/time.Second))
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Lengths = newDistribution(lengths)
+	stats.TTLs = newDistribution(ttls)
+
+	return stats, nil
+}
+
+// GetFanOutStats returns the counters kept by the fan-out (writes, skipped, rebuilds).
+func (t Timeline) GetFanOutStats(ctx context.Context) (map[string]int64, error) {
+
+	fields, err := t.cache.HGetAll(ctx, FanOutStatsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]int64, len(fields))
+	for field, value := range fields {
+		counter, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		counters[field] = counter
+	}
+
+	return counters, nil
+}
+
+// scanKeys calls handle with every batch of keys matching pattern.
+func (t Timeline) scanKeys(ctx context.Context, pattern string, handle func(keys []string) error) error {
+
+	var cursor uint64
+	for {
+		keys, next, err := t.cache.ScanKeys(ctx, pattern, cursor, scanBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := handle(keys); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// newDistribution summarizes values. Percentiles use the nearest rank.
+func newDistribution(values []int64) domain.Distribution {
+
+	if len(values) == 0 {
+		return domain.Distribution{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	var sum int64
+	for _, value := range sorted {
+		sum += value
+	}
+
+	percentile := func(p float64) int64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(rank, 0)]
+	}
+
+	return domain.Distribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  float64(sum) / float64(len(sorted)),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+	}
+}
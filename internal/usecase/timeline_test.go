@@ -0,0 +1,623 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/singleflight"
+)
+
+func TestParseCachedPage(t *testing.T) {
+	// Full page followed by more tweets
+	ids, complete := parseCachedPage([]string{"9", "8", "7"}, 2)
+	assert.Equal(t, []int64{9, 8}, ids)
+	assert.False(t, complete)
+
+	// Full page followed by the end marker
+	ids, complete = parseCachedPage([]string{"9", "8", TimelineEndMarker}, 2)
+	assert.Equal(t, []int64{9, 8}, ids)
+	assert.True(t, complete)
+
+	// Short last page
+	ids, complete = parseCachedPage([]string{"9", TimelineEndMarker}, 2)
+	assert.Equal(t, []int64{9}, ids)
+	assert.True(t, complete)
+
+	// Empty timeline
+	ids, complete = parseCachedPage([]string{TimelineEndMarker}, 2)
+	assert.Empty(t, ids)
+	assert.True(t, complete)
+
+	// Partial miss, e.g. a list only filled by the fan-out
+	ids, complete = parseCachedPage([]string{"9"}, 2)
+	assert.Equal(t, []int64{9}, ids)
+	assert.False(t, complete)
+}
+
+func TestTimelineEntries(t *testing.T) {
+	assert.Equal(t, []interface{}{"3", "2", TimelineEndMarker}, timelineEntries([]int64{3, 2}, true))
+	assert.Equal(t, []interface{}{"3", "2"}, timelineEntries([]int64{3, 2}, false))
+	assert.Equal(t, []interface{}{TimelineEndMarker}, timelineEntries(nil, true))
+
+	// A full window has no room for the marker
+	full := make([]int64, MaxCachedTweets)
+	assert.Len(t, timelineEntries(full, true), MaxCachedTweets)
+}
+
+func TestPageOf(t *testing.T) {
+	ids := []int64{5, 4, 3, 2, 1}
+
+	assert.Equal(t, []int64{5, 4}, pageOf(ids, 2, 0))
+	assert.Equal(t, []int64{1}, pageOf(ids, 2, 4))
+	assert.Empty(t, pageOf(ids, 2, 5))
+}
+
+func TestIsActive(t *testing.T) {
+	now := time.Now()
+	window := 7 * 24 * time.Hour
+
+	assert.True(t, isActive(float64(now.Add(-time.Hour).Unix()), now, window))
+	assert.False(t, isActive(float64(now.Add(-8*24*time.Hour).Unix()), now, window))
+
+	// Users who never read their timeline are inactive
+	assert.False(t, isActive(0, now, window))
+}
+
+func TestDiffTimelineIDs(t *testing.T) {
+	// Tweet 7 was never fanned out, tweet 6 was deleted; 3 is older than the cached range
+	missing, extra := diffTimelineIDs([]int64{9, 8, 6, 5}, []int64{9, 8, 7, 5, 3}, false)
+	assert.Equal(t, []int64{7}, missing)
+	assert.Equal(t, []int64{6}, extra)
+
+	// A complete list must hold the whole timeline
+	missing, extra = diffTimelineIDs([]int64{9}, []int64{9, 3}, true)
+	assert.Equal(t, []int64{3}, missing)
+	assert.Empty(t, extra)
+}
+
+func TestNewDistribution(t *testing.T) {
+	distribution := newDistribution([]int64{10, 1, 5, 2, 100})
+
+	assert.Equal(t, 5, distribution.Count)
+	assert.Equal(t, int64(1), distribution.Min)
+	assert.Equal(t, int64(100), distribution.Max)
+	assert.Equal(t, int64(5), distribution.P50)
+	assert.Equal(t, int64(100), distribution.P99)
+	assert.InDelta(t, 23.6, distribution.Mean, 0.001)
+
+	assert.Equal(t, 0, newDistribution(nil).Count)
+}
+
+func TestUserTweetEntryRoundTrip(t *testing.T) {
+	assert.Equal(t, "42", formatUserTweetEntry(domain.Tweet{ID: 42}))
+	assert.Equal(t, "43:r", formatUserTweetEntry(domain.Tweet{ID: 43, ReplyToTweetID: 1}))
+	assert.Equal(t, "44:t", formatUserTweetEntry(domain.Tweet{ID: 44, RetweetOfID: 1}))
+
+	id, isReply, isRetweet, ok := parseUserTweetEntry("43:r")
+	assert.True(t, ok)
+	assert.Equal(t, int64(43), id)
+	assert.True(t, isReply)
+	assert.False(t, isRetweet)
+
+	_, _, _, ok = parseUserTweetEntry("oops")
+	assert.False(t, ok)
+}
+
+func TestSelectUserTweetIDs(t *testing.T) {
+	allTweets := domain.TweetFilter{IncludeReplies: true, IncludeRetweets: true}
+	entries := []string{"9", "8:r", "7:t", "6", "5"}
+
+	// First page, then the page after the cursor
+	assert.Equal(t, []int64{9, 8}, selectUserTweetIDs(entries, 2, 0, allTweets, 0))
+	assert.Equal(t, []int64{7, 6}, selectUserTweetIDs(entries, 2, 8, allTweets, 0))
+
+	// Replies and retweets filtered out, pinned tweet excluded
+	assert.Equal(t, []int64{9, 5}, selectUserTweetIDs(entries, 3, 0, domain.TweetFilter{}, 6))
+
+	// A tweet pushed while the list was rebuilt shows up once
+	assert.Equal(t, []int64{9, 8, 7}, selectUserTweetIDs([]string{"9", "9", "8", "7"}, 3, 0, allTweets, 0))
+}
+
+func TestTimeline_HydratePage(t *testing.T) {
+	// Arrange: the reader (user 1) wrote tweets 8 and 6
+	cache := newStubCache()
+	timeline := Timeline{cache: cache}
+	window := []int64{9, 8, 7, 6, 5, 4}
+	for _, id := range window {
+		userID := int64(2)
+		if id == 8 || id == 6 {
+			userID = 1
+		}
+		cache.values[timeline.getTweetCacheKey(id)] = formatCachedTweet(domain.Tweet{ID: id, UserID: userID})
+	}
+
+	// Act
+	withSelf, err := timeline.hydratePage(context.Background(), window, 2, 2, 0)
+	assert.NoError(t, err)
+	withoutSelf, err := timeline.hydratePage(context.Background(), window, 2, 2, 1)
+	assert.NoError(t, err)
+	pastTheEnd, err := timeline.hydratePage(context.Background(), window, 2, 4, 1)
+
+	// Assert: the reader's tweets are left out before the page is cut, so offsets count the others
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7, 6}, tweetIDsOf(withSelf))
+	assert.Equal(t, []int64{5, 4}, tweetIDsOf(withoutSelf))
+	assert.Empty(t, pastTheEnd)
+}
+
+func TestTimeline_ReadCachedPageWithoutSelf(t *testing.T) {
+	// Arrange: a whole timeline cached, the reader (user 1) wrote tweet 8
+	cache := newStubCache()
+	timeline := Timeline{cache: cache}
+	cacheKey := timeline.getCacheKey(1)
+	cache.lists[cacheKey] = []string{"9", "8", "7", TimelineEndMarker}
+	for _, tweet := range []domain.Tweet{{ID: 9, UserID: 2}, {ID: 8, UserID: 1}, {ID: 7, UserID: 3}} {
+		cache.values[timeline.getTweetCacheKey(tweet.ID)] = formatCachedTweet(tweet)
+	}
+
+	// Act
+	tweets, hit, err := timeline.readCachedPage(context.Background(), cacheKey, 2, 0, nil, 1)
+	assert.NoError(t, err)
+	_, hiddenHit, err := timeline.readCachedPage(context.Background(), cacheKey, 2, 0, []int64{3}, 1)
+
+	// Assert: served from Redis alone, a hidden author is still a miss
+	assert.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, []int64{9, 7}, tweetIDsOf(tweets))
+	assert.False(t, hiddenHit)
+}
+
+// tweetIDsOf returns the IDs of tweets, in order.
+func tweetIDsOf(tweets []domain.Tweet) []int64 {
+	ids := make([]int64, len(tweets))
+	for i, tweet := range tweets {
+		ids[i] = tweet.ID
+	}
+	return ids
+}
+
+// stubCache keeps strings, lists and sorted sets in memory, for the Redis commands timelines use.
+// Its commands are safe to call concurrently, like a Redis client.
+type stubCache struct {
+	pkg.Cache
+	mu      sync.Mutex
+	values  map[string]string
+	lists   map[string][]string
+	scores  map[string]map[string]float64
+	deleted []string
+}
+
+func newStubCache() *stubCache {
+	return &stubCache{
+		values: map[string]string{},
+		lists:  map[string][]string{},
+		scores: map[string]map[string]float64{ActiveUsersKey: {}, StreamOnlineKey: {}},
+	}
+}
+
+// LRange supports the non-negative ranges and the last element (-1, -1) timelines read.
+func (s *stubCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.lists[key]
+	if start < 0 {
+		start = max(int64(len(list))+start, 0)
+	}
+	if stop < 0 {
+		stop = int64(len(list)) + stop
+	}
+	if start >= int64(len(list)) || start > stop {
+		return []string{}, nil
+	}
+	return list[start:min(stop+1, int64(len(list)))], nil
+}
+
+func (s *stubCache) LLen(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.lists[key])), nil
+}
+
+func (s *stubCache) ZAdd(ctx context.Context, key, member string, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scores[key][member] = score
+	return nil
+}
+
+func (s *stubCache) ZScore(ctx context.Context, key, member string) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.scores[key][member], nil
+}
+
+func (s *stubCache) ZMScore(ctx context.Context, key string, members []string) ([]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scores := make([]float64, len(members))
+	for i, member := range members {
+		scores[i] = s.scores[key][member]
+	}
+	return scores, nil
+}
+
+// ZRemRangeByScore only supports the "-inf" to "(max" range used by PruneActivity.
+func (s *stubCache) ZRemRangeByScore(ctx context.Context, key, min, max string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit, err := strconv.ParseFloat(max[1:], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for member, score := range s.scores[key] {
+		if score < limit {
+			delete(s.scores[key], member)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *stubCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		return false, nil
+	}
+	s.values[key] = fmt.Sprint(value)
+	return true, nil
+}
+
+func (s *stubCache) DeleteIfEquals(ctx context.Context, key string, value interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.values[key]; !ok || current != fmt.Sprint(value) {
+		return false, nil
+	}
+	delete(s.values, key)
+	return true, nil
+}
+
+func (s *stubCache) MGet(ctx context.Context, keys []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = s.values[key]
+	}
+	return values, nil
+}
+
+func (s *stubCache) SetMany(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, value := range values {
+		s.values[key] = value.(string)
+	}
+	return nil
+}
+
+// ReplaceList ignores maxLen and the expiration, timelines are written within the window.
+func (s *stubCache) ReplaceList(ctx context.Context, key string, values []interface{}, maxLen int64, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]string, len(values))
+	for i, value := range values {
+		list[i] = fmt.Sprint(value)
+	}
+	s.lists[key] = list
+	return nil
+}
+
+// PushCappedIfExists ignores maxLen and the expiration, like ReplaceList.
+func (s *stubCache) PushCappedIfExists(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if list, ok := s.lists[key]; ok {
+			s.lists[key] = append([]string{fmt.Sprint(value)}, list...)
+		}
+	}
+	return nil
+}
+
+func (s *stubCache) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleted = append(s.deleted, key)
+	delete(s.values, key)
+	delete(s.lists, key)
+	return nil
+}
+
+func (s *stubCache) HIncrBy(ctx context.Context, key, field string, incr int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return nil
+}
+
+func TestIsRecentlyActive(t *testing.T) {
+	now := time.Now()
+	window := 7 * 24 * time.Hour
+	lastWeek := now.Add(-8 * 24 * time.Hour)
+
+	// Never seen, while activity has been recorded for less than a window
+	assert.True(t, isRecentlyActive(0, now.Add(-time.Hour), now, window))
+
+	// Never seen, while activity has been recorded for a whole window
+	assert.False(t, isRecentlyActive(0, lastWeek, now, window))
+
+	// Seen, activity recorded before is no longer relevant
+	assert.True(t, isRecentlyActive(float64(now.Add(-time.Hour).Unix()), lastWeek, now, window))
+	assert.False(t, isRecentlyActive(float64(lastWeek.Unix()), lastWeek, now, window))
+}
+
+func TestTimeline_SelectActiveUserIDs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	fanOutConfig := config.FanOutConfig{ActiveWindow: 7 * 24 * time.Hour, StreamOnlineWindow: time.Minute}
+
+	t.Run("every follower is active right after activity starts being recorded", func(t *testing.T) {
+		// Arrange
+		cache := newStubCache()
+		timeline := Timeline{cache: cache, fanOutConfig: fanOutConfig}
+
+		// Act
+		active := timeline.selectActiveUserIDs(ctx, []int64{1, 2, 3})
+
+		// Assert
+		assert.Equal(t, []int64{1, 2, 3}, active)
+		assert.NotEmpty(t, cache.values[ActiveSinceKey])
+	})
+
+	t.Run("followers not seen for a whole window are dormant", func(t *testing.T) {
+		// Arrange
+		cache := newStubCache()
+		cache.values[ActiveSinceKey] = strconv.FormatInt(now.Add(-30*24*time.Hour).Unix(), 10)
+		cache.scores[ActiveUsersKey]["1"] = float64(now.Add(-time.Hour).Unix())
+		cache.scores[ActiveUsersKey]["2"] = float64(now.Add(-10 * 24 * time.Hour).Unix())
+		timeline := Timeline{cache: cache, fanOutConfig: fanOutConfig}
+
+		// Act
+		active := timeline.selectActiveUserIDs(ctx, []int64{1, 2, 3})
+
+		// Assert
+		assert.Equal(t, []int64{1}, active)
+	})
+}
+
+func TestTimeline_RecordActivity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	fanOutConfig := config.FanOutConfig{ActiveWindow: 7 * 24 * time.Hour, StreamOnlineWindow: time.Minute}
+
+	t.Run("keeps the timeline of a user first seen right after activity starts being recorded", func(t *testing.T) {
+		// Arrange
+		cache := newStubCache()
+		timeline := Timeline{cache: cache, fanOutConfig: fanOutConfig}
+
+		// Act
+		timeline.recordActivity(ctx, 1)
+
+		// Assert
+		assert.Empty(t, cache.deleted)
+		assert.NotZero(t, cache.scores[ActiveUsersKey]["1"])
+	})
+
+	t.Run("drops the timeline of a returning user", func(t *testing.T) {
+		// Arrange
+		cache := newStubCache()
+		cache.values[ActiveSinceKey] = strconv.FormatInt(now.Add(-30*24*time.Hour).Unix(), 10)
+		timeline := Timeline{cache: cache, fanOutConfig: fanOutConfig}
+
+		// Act
+		timeline.recordActivity(ctx, 1)
+
+		// Assert
+		assert.Equal(t, []string{timeline.getCacheKey(1)}, cache.deleted)
+	})
+}
+
+func TestTimeline_PruneActivity(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	cache := newStubCache()
+	cache.scores[ActiveUsersKey]["1"] = float64(now.Add(-time.Hour).Unix())
+	cache.scores[ActiveUsersKey]["2"] = float64(now.Add(-10 * 24 * time.Hour).Unix())
+	cache.scores[StreamOnlineKey]["1"] = float64(now.Unix())
+	cache.scores[StreamOnlineKey]["2"] = float64(now.Add(-time.Hour).Unix())
+	timeline := Timeline{cache: cache, fanOutConfig: config.FanOutConfig{ActiveWindow: 7 * 24 * time.Hour, StreamOnlineWindow: time.Minute}}
+
+	// Act
+	removed, err := timeline.PruneActivity(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+	assert.Equal(t, map[string]float64{"1": float64(now.Add(-time.Hour).Unix())}, cache.scores[ActiveUsersKey])
+	assert.Equal(t, map[string]float64{"1": float64(now.Unix())}, cache.scores[StreamOnlineKey])
+}
+
+func TestTimeline_GetHiddenUserIDs(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	var queries int
+	timeline := Timeline{
+		cache:           newStubCache(),
+		muteRepository:  stubMuteRepository{mutedIDs: []int64{2}, queries: &queries},
+		blockRepository: stubBlockRepository{blockedIDs: []int64{3}, queries: &queries},
+	}
+
+	// Act
+	cold, err := timeline.getHiddenUserIDs(ctx, 1)
+	assert.NoError(t, err)
+	warm, err := timeline.getHiddenUserIDs(ctx, 1)
+	assert.NoError(t, err)
+	queriesWhenWarm := queries
+
+	assert.NoError(t, timeline.InvalidateHiddenUsers(ctx, 1))
+	timeline.muteRepository = stubMuteRepository{queries: &queries}
+	invalidated, err := timeline.getHiddenUserIDs(ctx, 1)
+
+	// Assert: a warm read needs no query, an invalidated one reads the change
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, cold)
+	assert.Equal(t, []int64{2, 3}, warm)
+	assert.Equal(t, 2, queriesWhenWarm)
+	assert.Equal(t, []int64{3}, invalidated)
+}
+
+func TestParseCachedIDs(t *testing.T) {
+	ids, ok := parseCachedIDs("[2,3]")
+	assert.True(t, ok)
+	assert.Equal(t, []int64{2, 3}, ids)
+
+	// Hiding nobody is cached too
+	ids, ok = parseCachedIDs("[]")
+	assert.True(t, ok)
+	assert.Empty(t, ids)
+
+	_, ok = parseCachedIDs("")
+	assert.False(t, ok)
+}
+
+func TestTimeline_RebuildLock(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache := newStubCache()
+	timeline := Timeline{cache: cache}
+	cacheKey := timeline.getCacheKey(1)
+
+	// Act
+	token, locked := timeline.acquireRebuildLock(ctx, cacheKey)
+	_, lockedTwice := timeline.acquireRebuildLock(ctx, cacheKey)
+
+	// A lock that expired and was taken again is not released by its former holder
+	timeline.releaseRebuildLock(ctx, cacheKey, "expired")
+	_, lockedAfterOtherRelease := timeline.acquireRebuildLock(ctx, cacheKey)
+
+	timeline.releaseRebuildLock(ctx, cacheKey, token)
+	_, lockedAfterRelease := timeline.acquireRebuildLock(ctx, cacheKey)
+
+	// Assert
+	assert.True(t, locked)
+	assert.NotEmpty(t, token)
+	assert.False(t, lockedTwice)
+	assert.False(t, lockedAfterOtherRelease)
+	assert.True(t, lockedAfterRelease)
+}
+
+func TestTimeline_WaitForRebuild(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves the page once another replica rebuilt the timeline", func(t *testing.T) {
+		// Arrange
+		cache := newStubCache()
+		timeline := Timeline{cache: cache}
+		cacheKey := timeline.getCacheKey(1)
+		for _, id := range []int64{9, 8} {
+			cache.values[timeline.getTweetCacheKey(id)] = formatCachedTweet(domain.Tweet{ID: id, UserID: 2})
+		}
+
+		go func() {
+			time.Sleep(RebuildWait / 4)
+			_ = cache.ReplaceList(ctx, cacheKey, []interface{}{"9", "8", TimelineEndMarker}, MaxCachedTweets, CacheExpiration)
+		}()
+
+		// Act
+		tweets, ok := timeline.waitForRebuild(ctx, cacheKey, 2, 0, nil, 0)
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, []int64{9, 8}, tweetIDsOf(tweets))
+	})
+
+	t.Run("gives up when the timeline isn't rebuilt in time", func(t *testing.T) {
+		// Arrange
+		timeline := Timeline{cache: newStubCache()}
+		start := time.Now()
+
+		// Act
+		_, ok := timeline.waitForRebuild(ctx, timeline.getCacheKey(1), 2, 0, nil, 0)
+
+		// Assert
+		assert.False(t, ok)
+		assert.GreaterOrEqual(t, time.Since(start), RebuildWait)
+	})
+}
+
+func TestTimeline_LoadTimeline_CoalescesRequests(t *testing.T) {
+	// Arrange: the window query blocks until every request is waiting for it
+	cache := newStubCache()
+	timeline := Timeline{cache: cache, rebuilds: &singleflight.Group{}}
+	cacheKey := timeline.getCacheKey(1)
+	for _, id := range []int64{9, 8} {
+		cache.values[timeline.getTweetCacheKey(id)] = formatCachedTweet(domain.Tweet{ID: id, UserID: 2})
+	}
+
+	var windowQueries, pageQueries atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	source := timelineSource{
+		selectPage: func(ctx context.Context) ([]domain.Tweet, error) {
+			pageQueries.Add(1)
+			return nil, nil
+		},
+		selectWindow: func(ctx context.Context) ([]int64, error) {
+			if windowQueries.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return []int64{9, 8}, nil
+		},
+	}
+
+	// Act
+	const requests = 5
+	results := make(chan []domain.Tweet, requests)
+	load := func() {
+		tweets, err := timeline.loadTimeline(context.Background(), cacheKey, cacheKey, 2, 0, nil, 0, true, source)
+		assert.NoError(t, err)
+		results <- tweets
+	}
+
+	go load()
+	<-started
+	for i := 1; i < requests; i++ {
+		go load()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	// Assert: one query rebuilt the cache and answered every request
+	for i := 0; i < requests; i++ {
+		assert.Equal(t, []int64{9, 8}, tweetIDsOf(<-results))
+	}
+	assert.Equal(t, int32(1), windowQueries.Load())
+	assert.Equal(t, int32(0), pageQueries.Load())
+	assert.Equal(t, []string{"9", "8", TimelineEndMarker}, cache.lists[cacheKey])
+}
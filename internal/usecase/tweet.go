@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
+	"time"
 	"twitter-demo/internal/config"
 	"twitter-demo/internal/domain"
 	"twitter-demo/internal/infrastructure/repository"
@@ -11,28 +13,61 @@ import (
 	"twitter-demo/pkg"
 )
 
+// ErrEditLimit is returned when a tweet is edited after its edit window expired or
+// once its edits are used up.
+var ErrEditLimit = errors.New("tweet can no longer be edited")
+
 type TweetUsecase interface {
-	GetTweetByID(ctx context.Context, id int64) (domain.Tweet, error)
+	GetTweetByID(ctx context.Context, id, viewerID int64) (domain.Tweet, error)
 	CreateTweet(ctx context.Context, tweet domain.Tweet) (domain.Tweet, error)
+	PublishScheduledTweet(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.Tweet, error)
 	UpdateTweetByID(ctx context.Context, id int64, tweet domain.Tweet) (domain.Tweet, error)
+	GetTweetHistory(ctx context.Context, id, viewerID int64) (domain.Tweet, []domain.TweetRevision, error)
+	ValidateContent(content string) (domain.TweetLength, error)
+	PinTweet(ctx context.Context, userID, tweetID int64) error
+	UnpinTweet(ctx context.Context, userID int64) error
 }
 
 type Tweet struct {
-	tweetRepository repository.TweetRepository
-	userRepository  repository.UserRepository
-	producer        pkg.Producer
+	tweetRepository    repository.TweetRepository
+	userRepository     repository.UserRepository
+	followerRepository repository.FollowerRepository
+	blockRepository    repository.BlockRepository
+	producer           pkg.Producer
+	textConfig         config.TweetTextConfig
+	editConfig         config.TweetEditConfig
 }
 
-func NewTweet(tweetRepository repository.TweetRepository, userRepository repository.UserRepository, producer pkg.Producer) Tweet {
+func NewTweet(tweetRepository repository.TweetRepository, userRepository repository.UserRepository, followerRepository repository.FollowerRepository, blockRepository repository.BlockRepository, producer pkg.Producer, textConfig config.TweetTextConfig, editConfig config.TweetEditConfig) Tweet {
 	return Tweet{
-		tweetRepository: tweetRepository,
-		userRepository:  userRepository,
-		producer:        producer,
+		tweetRepository:    tweetRepository,
+		userRepository:     userRepository,
+		followerRepository: followerRepository,
+		blockRepository:    blockRepository,
+		producer:           producer,
+		textConfig:         textConfig,
+		editConfig:         editConfig,
 	}
 }
 
-func (t Tweet) GetTweetByID(ctx context.Context, id int64) (domain.Tweet, error) {
-	return t.tweetRepository.SelectByID(ctx, id)
+// GetTweetByID returns a tweet if viewerID can see its author's tweets, ErrProtectedAccount
+// otherwise. A viewerID of 0 is an anonymous viewer.
+func (t Tweet) GetTweetByID(ctx context.Context, id, viewerID int64) (domain.Tweet, error) {
+
+	tweet, err := t.tweetRepository.SelectByID(ctx, id)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if tweet.ID == 0 {
+		return domain.Tweet{}, nil
+	}
+
+	if err := t.checkVisible(ctx, viewerID, tweet.UserID); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	return tweet, nil
 }
 
 func (t Tweet) CreateTweet(ctx context.Context, tweet domain.Tweet) (domain.Tweet, error) {
@@ -48,28 +83,55 @@ func (t Tweet) CreateTweet(ctx context.Context, tweet domain.Tweet) (domain.Twee
 		return domain.Tweet{}, err
 	}
 
-	// Publish TweetCreatedEvent to Kafka for Fan-Out processing
+	t.publishTweetCreated(newTweet)
+
+	return newTweet, nil
+}
+
+// PublishScheduledTweet publishes a scheduled tweet claimed by the scheduler. The tweet
+// is created and the scheduled tweet marked as published in one transaction, and the
+// TweetCreatedEvent is only sent once it committed. An empty tweet (ID will be 0) is
+// returned when the scheduled tweet was already published by another worker.
+func (t Tweet) PublishScheduledTweet(ctx context.Context, scheduledTweet domain.ScheduledTweet) (domain.Tweet, error) {
+
+	tweet := domain.Tweet{
+		UserID:  scheduledTweet.UserID,
+		Content: scheduledTweet.Content,
+	}
+
+	if err := t.validateTweet(ctx, tweet); err != nil {
+		return domain.Tweet{}, err
+	}
+
+	newTweet, err := t.tweetRepository.InsertScheduled(ctx, scheduledTweet.ID, tweet)
+	if err != nil {
+		return domain.Tweet{}, err
+	}
+
+	if newTweet.ID != 0 {
+		t.publishTweetCreated(newTweet)
+	}
+
+	return newTweet, nil
+}
+
+// publishTweetCreated publishes the TweetCreatedEvent of a new tweet to Kafka for
+// Fan-Out processing, without blocking the response.
+func (t Tweet) publishTweetCreated(newTweet domain.Tweet) {
+
 	event := dto.NewEvent(
 		dto.TweetCreatedEvent,
 		dto.TweetCreatedEventData{
-			TweetID:   newTweet.ID,
-			UserID:    newTweet.UserID,
-			Content:   newTweet.Content,
-			CreatedAt: newTweet.CreatedAt,
+			TweetID:        newTweet.ID,
+			UserID:         newTweet.UserID,
+			Content:        newTweet.Content,
+			ReplyToTweetID: newTweet.ReplyToTweetID,
+			RetweetOfID:    newTweet.RetweetOfID,
+			CreatedAt:      newTweet.CreatedAt,
 		},
 	)
 
-	// Publish event asynchronously (don't block the response)
-	go func() {
-		eventKey := fmt.Sprintf(config.KeyFormatTweet, newTweet.ID)
-		if err := t.producer.Publish(context.Background(), config.TopicTweets, eventKey, event); err != nil {
-			log.Printf("Failed to publish TweetCreatedEvent: %v", err)
-		} else {
-			log.Printf("Published TweetCreatedEvent for tweet %d", newTweet.ID)
-		}
-	}()
-
-	return newTweet, nil
+	go t.publishTweetEvent(newTweet.ID, event)
 }
 
 func (t Tweet) UpdateTweetByID(ctx context.Context, id int64, tweet domain.Tweet) (domain.Tweet, error) {
@@ -89,24 +151,142 @@ func (t Tweet) UpdateTweetByID(ctx context.Context, id int64, tweet domain.Tweet
 		return domain.Tweet{}, fmt.Errorf("tweet not found")
 	}
 
-	// Update tweet content
+	// Check the edit window and the number of edits left
+	if time.Since(existingTweet.CreatedAt) > t.editConfig.Window {
+		return domain.Tweet{}, fmt.Errorf("%w: edit window has expired", ErrEditLimit)
+	}
+
+	if existingTweet.EditCount >= t.editConfig.MaxEdits {
+		return domain.Tweet{}, fmt.Errorf("%w: maximum number of edits reached", ErrEditLimit)
+	}
+
+	// Update tweet content, the previous version is kept as a revision
+	previousContent := existingTweet.Content
 	existingTweet.Content = tweet.Content
 
-	updatedTweet, err := t.tweetRepository.UpdateByID(ctx, id, existingTweet)
+	updatedTweet, err := t.tweetRepository.UpdateByID(ctx, id, existingTweet, t.editConfig.MaxEdits, t.editConfig.Window)
 	if err != nil {
 		return domain.Tweet{}, err
 	}
 
+	// A concurrent edit used up the last edit, or the window expired in between
+	if updatedTweet.ID == 0 {
+		return domain.Tweet{}, ErrEditLimit
+	}
+
+	// Publish TweetUpdatedEvent so cached and indexed copies can be refreshed
+	event := dto.NewEvent(
+		dto.TweetUpdatedEvent,
+		dto.TweetUpdatedEventData{
+			TweetID:         updatedTweet.ID,
+			UserID:          updatedTweet.UserID,
+			Content:         updatedTweet.Content,
+			PreviousContent: previousContent,
+			EditCount:       updatedTweet.EditCount,
+			CreatedAt:       updatedTweet.CreatedAt,
+			UpdatedAt:       updatedTweet.UpdatedAt,
+		},
+	)
+
+	go t.publishTweetEvent(updatedTweet.ID, event)
+
 	return updatedTweet, nil
 }
 
-func (t Tweet) validateTweet(ctx context.Context, tweet domain.Tweet) error {
+// GetTweetHistory returns the current version of a tweet along with its previous
+// versions, oldest first.
+// The same visibility rules as GetTweetByID apply.
+func (t Tweet) GetTweetHistory(ctx context.Context, id, viewerID int64) (domain.Tweet, []domain.TweetRevision, error) {
 
-	// Validate content
-	if err := t.validateTweetContent(tweet.Content); err != nil {
+	tweet, err := t.GetTweetByID(ctx, id, viewerID)
+	if err != nil {
+		return domain.Tweet{}, nil, err
+	}
+
+	if tweet.ID == 0 {
+		return domain.Tweet{}, nil, nil
+	}
+
+	revisions, err := t.tweetRepository.SelectRevisionsByTweetID(ctx, id)
+	if err != nil {
+		return domain.Tweet{}, nil, err
+	}
+
+	return tweet, revisions, nil
+}
+
+// PinTweet pins one of the user's own tweets to the top of their profile,
+// replacing the previously pinned tweet.
+func (t Tweet) PinTweet(ctx context.Context, userID, tweetID int64) error {
+
+	tweet, err := t.tweetRepository.SelectByID(ctx, tweetID)
+	if err != nil {
 		return err
 	}
 
+	if tweet.ID == 0 || tweet.UserID != userID {
+		return fmt.Errorf("tweet not found")
+	}
+
+	return t.tweetRepository.UpsertPinnedTweet(ctx, userID, tweetID)
+}
+
+func (t Tweet) UnpinTweet(ctx context.Context, userID int64) error {
+	return t.tweetRepository.DeletePinnedTweet(ctx, userID)
+}
+
+// publishTweetEvent publishes an event about a tweet to Kafka.
+// It is meant to run in its own goroutine so it doesn't block the response.
+func (t Tweet) publishTweetEvent(tweetID int64, event dto.Event) {
+	publishEvent(t.producer, config.TopicTweets, fmt.Sprintf(config.KeyFormatTweet, tweetID), event)
+}
+
+func (t Tweet) validateTweet(ctx context.Context, tweet domain.Tweet) error {
+
+	// Validate content, a plain retweet has no content of its own
+	if !tweet.IsRetweet() || tweet.Content != "" {
+		if err := t.validateTweetContent(tweet.Content); err != nil {
+			return err
+		}
+	}
+
+	if tweet.IsReply() && tweet.IsRetweet() {
+		return fmt.Errorf("a tweet cannot be both a reply and a retweet")
+	}
+
+	// Check if the referenced tweets exist
+	if tweet.IsReply() {
+		repliedTweet, err := t.tweetRepository.SelectByID(ctx, tweet.ReplyToTweetID)
+		if err != nil {
+			return err
+		}
+		if repliedTweet.ID == 0 {
+			return fmt.Errorf("replied tweet not found")
+		}
+		if err := t.checkNotBlocked(ctx, tweet.UserID, repliedTweet.UserID); err != nil {
+			return err
+		}
+		if err := t.checkVisible(ctx, tweet.UserID, repliedTweet.UserID); err != nil {
+			return err
+		}
+	}
+
+	if tweet.IsRetweet() {
+		retweetedTweet, err := t.tweetRepository.SelectByID(ctx, tweet.RetweetOfID)
+		if err != nil {
+			return err
+		}
+		if retweetedTweet.ID == 0 {
+			return fmt.Errorf("retweeted tweet not found")
+		}
+		if err := t.checkNotBlocked(ctx, tweet.UserID, retweetedTweet.UserID); err != nil {
+			return err
+		}
+		if err := t.checkNotProtected(ctx, tweet.UserID, retweetedTweet.UserID); err != nil {
+			return err
+		}
+	}
+
 	// Check if user exists
 	existingUser, err := t.userRepository.SelectByID(ctx, tweet.UserID)
 	if err != nil {
@@ -119,17 +299,82 @@ func (t Tweet) validateTweet(ctx context.Context, tweet domain.Tweet) error {
 	return nil
 }
 
-func (t Tweet) validateTweetContent(content string) error {
+// checkNotBlocked prevents replying to or retweeting a user when a block exists in either direction.
+func (t Tweet) checkNotBlocked(ctx context.Context, userID, authorID int64) error {
 
-	// Check if content is empty
-	if content == "" {
-		return fmt.Errorf("content cannot be empty")
+	if userID == authorID {
+		return nil
 	}
 
-	// Check if content exceeds 280 characters
-	if len(content) > 280 {
-		return fmt.Errorf("content cannot exceed 280 characters")
+	blocked, err := t.blockRepository.ExistsBetween(ctx, userID, authorID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fmt.Errorf("cannot interact with this user's tweets")
 	}
 
 	return nil
 }
+
+// checkVisible returns ErrProtectedAccount when viewerID is not allowed to see the tweets of authorID.
+func (t Tweet) checkVisible(ctx context.Context, viewerID, authorID int64) error {
+
+	author, err := t.userRepository.SelectByID(ctx, authorID)
+	if err != nil {
+		return err
+	}
+
+	visible, err := canViewTweetsOf(ctx, t.followerRepository, viewerID, author)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrProtectedAccount
+	}
+
+	return nil
+}
+
+// checkNotProtected prevents retweeting the tweets of a protected account, even for
+// its followers, since a retweet would show them to everyone. Owners can retweet themselves.
+func (t Tweet) checkNotProtected(ctx context.Context, userID, authorID int64) error {
+
+	if userID == authorID {
+		return nil
+	}
+
+	author, err := t.userRepository.SelectByID(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	if author.Protected {
+		return fmt.Errorf("cannot retweet tweets of a protected account")
+	}
+
+	return nil
+}
+
+// ValidateContent measures content with the weighted counter and reports why it
+// cannot be published, if that is the case. Clients call it while composing.
+func (t Tweet) ValidateContent(content string) (domain.TweetLength, error) {
+
+	length := CountTweetLength(content, t.textConfig)
+	if length.Valid {
+		return length, nil
+	}
+
+	switch {
+	case length.Oversized:
+		return length, fmt.Errorf("content cannot exceed %d bytes", t.textConfig.MaxBytes)
+	case strings.TrimSpace(content) == "":
+		return length, fmt.Errorf("content cannot be empty")
+	default:
+		return length, fmt.Errorf("content cannot exceed %d characters", length.MaxLength)
+	}
+}
+
+func (t Tweet) validateTweetContent(content string) error {
+	_, err := t.ValidateContent(content)
+	return err
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner    = '\u200D'
+	textPresentation   = '\uFE0E'
+	emojiPresentation  = '\uFE0F'
+	combiningKeycap    = '\u20E3'
+	carriageReturnFeed = "\r\n"
+)
+
+// urlPattern matches explicit http(s) links and bare www. links.
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// CountTweetLength measures content the way Twitter does: grapheme clusters are
+// counted once, weighted by the range of their first code point (emoji use their own
+// weight), and every URL counts as a fixed number of characters regardless of its size.
+// Content over cfg.MaxBytes is not measured, it is invalid whatever its weighted length.
+func CountTweetLength(content string, cfg config.TweetTextConfig) domain.TweetLength {
+
+	if cfg.MaxBytes > 0 && len(content) > cfg.MaxBytes {
+		return domain.TweetLength{
+			MaxLength: cfg.MaxWeightedLength,
+			Oversized: true,
+		}
+	}
+
+	units := 0
+	cursor := 0
+
+	for _, loc := range urlPattern.FindAllStringIndex(content, -1) {
+		end := loc[0] + len(trimURLPunctuation(content[loc[0]:loc[1]]))
+
+		units += countTextUnits(content[cursor:loc[0]], cfg)
+		units += cfg.URLLength * cfg.Scale
+		cursor = end
+	}
+	units += countTextUnits(content[cursor:], cfg)
+
+	weightedLength := units / cfg.Scale
+	permillage := 0
+	if cfg.MaxWeightedLength > 0 {
+		permillage = weightedLength * 1000 / cfg.MaxWeightedLength
+	}
+
+	return domain.TweetLength{
+		WeightedLength: weightedLength,
+		MaxLength:      cfg.MaxWeightedLength,
+		Permillage:     permillage,
+		Valid:          strings.TrimSpace(content) != "" && weightedLength <= cfg.MaxWeightedLength,
+	}
+}
+
+// trimURLPunctuation drops trailing punctuation that is part of the sentence, not the URL.
+func trimURLPunctuation(url string) string {
+	return strings.TrimRight(url, ".,!?;:)]}'")
+}
+
+// countTextUnits returns the weighted size of plain text, in units of cfg.Scale.
+func countTextUnits(text string, cfg config.TweetTextConfig) int {
+
+	units := 0
+	for len(text) > 0 {
+		cluster := nextGrapheme(text)
+		units += graphemeWeight(cluster, cfg)
+		text = text[len(cluster):]
+	}
+
+	return units
+}
+
+// graphemeWeight returns the weight of a single grapheme cluster.
+func graphemeWeight(cluster string, cfg config.TweetTextConfig) int {
+
+	first, _ := utf8.DecodeRuneInString(cluster)
+	if isEmojiCluster(cluster) {
+		return cfg.EmojiWeight
+	}
+
+	for _, r := range cfg.Ranges {
+		if first >= r.Start && first <= r.End {
+			return r.Weight
+		}
+	}
+
+	return cfg.DefaultWeight
+}
+
+// nextGrapheme returns the first extended grapheme cluster of text.
+// It covers the cases that matter for tweets: CRLF, combining marks, variation
+// selectors, emoji modifiers, tag sequences, ZWJ sequences and flag pairs.
+func nextGrapheme(text string) string {
+
+	if strings.HasPrefix(text, carriageReturnFeed) {
+		return text[:len(carriageReturnFeed)]
+	}
+
+	first, end := utf8.DecodeRuneInString(text)
+	if unicode.IsControl(first) {
+		return text[:end]
+	}
+
+	previous := first
+	for end < len(text) {
+		r, width := utf8.DecodeRuneInString(text[end:])
+
+		joins := isGraphemeExtender(r) ||
+			(previous == zeroWidthJoiner && isExtendedPictographic(r)) ||
+			(end == utf8.RuneLen(first) && isRegionalIndicator(first) && isRegionalIndicator(r))
+		if !joins {
+			break
+		}
+
+		previous = r
+		end += width
+	}
+
+	return text[:end]
+}
+
+// isGraphemeExtender reports whether r attaches to the preceding code point.
+func isGraphemeExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= 0xFE00 && r <= 0xFE0F) || // Variation selectors
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // Emoji skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) || // Tag sequences (subdivision flags)
+		(r >= 0xE0100 && r <= 0xE01EF) // Variation selectors supplement
+}
+
+// isRegionalIndicator reports whether r is one half of a flag pair.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isExtendedPictographic reports whether r is in one of the emoji blocks.
+func isExtendedPictographic(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2300 && r <= 0x23FF) ||
+		(r >= 0x2B00 && r <= 0x2BFF) ||
+		r == 0x00A9 || r == 0x00AE || r == 0x203C || r == 0x2049 || r == 0x2122
+}
+
+// isEmojiCluster reports whether the cluster renders as an emoji. Symbols from the
+// older blocks (such as ☀ or ©) default to text and only count as emoji when they
+// carry the emoji presentation selector.
+func isEmojiCluster(cluster string) bool {
+
+	first, _ := utf8.DecodeRuneInString(cluster)
+	if isRegionalIndicator(first) || strings.ContainsRune(cluster, combiningKeycap) {
+		return true
+	}
+
+	if !isExtendedPictographic(first) {
+		return false
+	}
+
+	if first < 0x1F000 {
+		return strings.ContainsRune(cluster, emojiPresentation)
+	}
+
+	return !strings.ContainsRune(cluster, textPresentation)
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"twitter-demo/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountTweetLength_Latin(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+
+	// Act
+	length := CountTweetLength("Hello, Twitter!", cfg)
+
+	// Assert
+	assert.Equal(t, 15, length.WeightedLength)
+	assert.Equal(t, 265, length.Remaining())
+	assert.True(t, length.Valid)
+}
+
+func TestCountTweetLength_CJKCountsDouble(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+
+	// Act
+	length := CountTweetLength(strings.Repeat("漢", 140), cfg)
+
+	// Assert
+	assert.Equal(t, 280, length.WeightedLength)
+	assert.True(t, length.Valid)
+}
+
+func TestCountTweetLength_EmojiGraphemes(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+
+	// Act
+	family := CountTweetLength("\U0001F468\u200D\U0001F469\u200D\U0001F467\u200D\U0001F466", cfg)
+	skinTone := CountTweetLength("👍🏽", cfg)
+	flag := CountTweetLength("🇻🇪", cfg)
+	combining := CountTweetLength("e\u0301", cfg)
+
+	// Assert
+	assert.Equal(t, 2, family.WeightedLength)
+	assert.Equal(t, 2, skinTone.WeightedLength)
+	assert.Equal(t, 2, flag.WeightedLength)
+	assert.Equal(t, 1, combining.WeightedLength)
+}
+
+func TestCountTweetLength_URLsHaveFixedLength(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+	content := "Read this: https://example.com/" + strings.Repeat("a", 300) + "."
+
+	// Act
+	length := CountTweetLength(content, cfg)
+
+	// Assert
+	assert.Equal(t, len("Read this: ")+23+len("."), length.WeightedLength)
+	assert.True(t, length.Valid)
+}
+
+func TestCountTweetLength_OverLimit(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+
+	// Act
+	length := CountTweetLength(strings.Repeat("a", 281), cfg)
+
+	// Assert
+	assert.Equal(t, -1, length.Remaining())
+	assert.False(t, length.Valid)
+}
+
+func TestCountTweetLength_OversizedURL(t *testing.T) {
+	// Arrange
+	cfg := config.NewTweetTextConfig()
+	content := "https://example.com/" + strings.Repeat("a", cfg.MaxBytes)
+
+	// Act
+	length := CountTweetLength(content, cfg)
+
+	// Assert
+	assert.True(t, length.Oversized)
+	assert.False(t, length.Valid)
+}
+
+func TestValidateContent(t *testing.T) {
+	// Arrange
+	tweetUsecase := NewTweet(nil, nil, nil, nil, nil, config.NewTweetTextConfig(), config.NewTweetEditConfig())
+
+	// Act
+	_, validErr := tweetUsecase.ValidateContent("Read this: https://example.com/" + strings.Repeat("a", 1000))
+	_, oversizedErr := tweetUsecase.ValidateContent("https://example.com/" + strings.Repeat("a", config.TweetMaxContentBytes))
+	_, emptyErr := tweetUsecase.ValidateContent("   ")
+	_, longErr := tweetUsecase.ValidateContent(strings.Repeat("a", 281))
+
+	// Assert
+	assert.NoError(t, validErr)
+	assert.EqualError(t, oversizedErr, "content cannot exceed 4096 bytes")
+	assert.EqualError(t, emptyErr, "content cannot be empty")
+	assert.EqualError(t, longErr, "content cannot exceed 280 characters")
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// stubTweetRepository serves a single tweet. updated is what UpdateByID returns,
+// an empty tweet when the edit is refused by the database.
+type stubTweetRepository struct {
+	repository.TweetRepository
+	tweet   domain.Tweet
+	updated domain.Tweet
+}
+
+func (s stubTweetRepository) SelectByID(ctx context.Context, id int64) (domain.Tweet, error) {
+	if id != s.tweet.ID {
+		return domain.Tweet{}, nil
+	}
+	return s.tweet, nil
+}
+
+func (s stubTweetRepository) UpdateByID(ctx context.Context, id int64, tweet domain.Tweet, maxEdits int, window time.Duration) (domain.Tweet, error) {
+	return s.updated, nil
+}
+
+// stubFollowerRepository knows the approved followers of every user, and records the
+// relationships inserted when inserted is set.
+type stubFollowerRepository struct {
+	repository.FollowerRepository
+	followers map[int64][]int64
+	inserted  *[]domain.Follower
+}
+
+func (s stubFollowerRepository) Insert(ctx context.Context, follower domain.Follower) (domain.Follower, error) {
+	*s.inserted = append(*s.inserted, follower)
+	follower.ID = int64(len(*s.inserted))
+	return follower, nil
+}
+
+func (s stubFollowerRepository) SelectByFollowerAndFollowed(ctx context.Context, followerID, followedID int64) (domain.Follower, error) {
+	for _, id := range s.followers[followedID] {
+		if id == followerID {
+			return domain.Follower{ID: 1, FollowerID: followerID, FollowedID: followedID}, nil
+		}
+	}
+	return domain.Follower{}, nil
+}
+
+var testEditConfig = config.TweetEditConfig{Window: time.Hour, MaxEdits: 5}
+
+func TestUpdateTweetByID_EditWindowExpired(t *testing.T) {
+	// Arrange
+	tweetRepository := stubTweetRepository{tweet: domain.Tweet{ID: 1, UserID: 2, Content: "hello", CreatedAt: time.Now().Add(-2 * time.Hour)}}
+	tweetUsecase := NewTweet(tweetRepository, nil, nil, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	// Act
+	_, err := tweetUsecase.UpdateTweetByID(context.Background(), 1, domain.Tweet{Content: "hello again"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrEditLimit)
+	assert.ErrorContains(t, err, "edit window has expired")
+}
+
+func TestUpdateTweetByID_EditLimitReached(t *testing.T) {
+	// Arrange
+	tweetRepository := stubTweetRepository{tweet: domain.Tweet{ID: 1, UserID: 2, Content: "hello", EditCount: 5, CreatedAt: time.Now()}}
+	tweetUsecase := NewTweet(tweetRepository, nil, nil, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	// Act
+	_, err := tweetUsecase.UpdateTweetByID(context.Background(), 1, domain.Tweet{Content: "hello again"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrEditLimit)
+	assert.ErrorContains(t, err, "maximum number of edits reached")
+}
+
+func TestUpdateTweetByID_ConcurrentEditUsedUpLimit(t *testing.T) {
+	// Arrange: the last edit was made by another request between the read and the update
+	tweetRepository := stubTweetRepository{tweet: domain.Tweet{ID: 1, UserID: 2, Content: "hello", EditCount: 4, CreatedAt: time.Now()}}
+	tweetUsecase := NewTweet(tweetRepository, nil, nil, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	// Act
+	_, err := tweetUsecase.UpdateTweetByID(context.Background(), 1, domain.Tweet{Content: "hello again"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrEditLimit)
+}
+
+func TestGetTweetByID_ProtectedAuthor(t *testing.T) {
+	// Arrange: user 2 is protected and approved user 3, user 4 only asked to follow
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(2)).Return(domain.User{ID: 2, Protected: true}, nil).AnyTimes()
+
+	tweetRepository := stubTweetRepository{tweet: domain.Tweet{ID: 1, UserID: 2, Content: "hello"}}
+	followerRepository := stubFollowerRepository{followers: map[int64][]int64{2: {3}}}
+	tweetUsecase := NewTweet(tweetRepository, userRepository, followerRepository, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	tests := []struct {
+		name     string
+		viewerID int64
+		visible  bool
+	}{
+		{name: "approved follower", viewerID: 3, visible: true},
+		{name: "pending requester", viewerID: 4, visible: false},
+		{name: "anonymous viewer", viewerID: 0, visible: false},
+		{name: "author", viewerID: 2, visible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			tweet, err := tweetUsecase.GetTweetByID(context.Background(), 1, tt.viewerID)
+
+			// Assert
+			if tt.visible {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), tweet.ID)
+			} else {
+				assert.ErrorIs(t, err, ErrProtectedAccount)
+				assert.Equal(t, int64(0), tweet.ID)
+			}
+		})
+	}
+}
+
+func TestGetTweetHistory_ProtectedAuthor(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mocks.NewMockUserRepository(ctrl)
+	userRepository.EXPECT().SelectByID(gomock.Any(), int64(2)).Return(domain.User{ID: 2, Protected: true}, nil)
+
+	tweetRepository := stubTweetRepository{tweet: domain.Tweet{ID: 1, UserID: 2, Content: "hello"}}
+	tweetUsecase := NewTweet(tweetRepository, userRepository, stubFollowerRepository{}, nil, nil, config.NewTweetTextConfig(), testEditConfig)
+
+	// Act
+	_, revisions, err := tweetUsecase.GetTweetHistory(context.Background(), 1, 0)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrProtectedAccount)
+	assert.Empty(t, revisions)
+}
+
+// SelectFollowerIDsPage returns the users following at least one account, by ID.
+func (s stubFollowerRepository) SelectFollowerIDsPage(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	var followerIDs []int64
+	for _, ids := range s.followers {
+		for _, id := range ids {
+			if id > afterID && !slices.Contains(followerIDs, id) {
+				followerIDs = append(followerIDs, id)
+			}
+		}
+	}
+	slices.Sort(followerIDs)
+	return followerIDs[:min(limit, len(followerIDs))], nil
+}
@@ -3,8 +3,12 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log"
+	"twitter-demo/internal/config"
 	"twitter-demo/internal/domain"
 	"twitter-demo/internal/infrastructure/repository"
+	"twitter-demo/internal/interfaces/dto"
+	"twitter-demo/pkg"
 )
 
 type UserUsecase interface {
@@ -17,12 +21,18 @@ type UserUsecase interface {
 }
 
 type User struct {
-	userRepository repository.UserRepository
+	userRepository  repository.UserRepository
+	followerUsecase FollowerUsecase
+	timelineUsecase TimelineUsecase
+	producer        pkg.Producer
 }
 
-func NewUser(userRepository repository.UserRepository) User {
+func NewUser(userRepository repository.UserRepository, followerUsecase FollowerUsecase, timelineUsecase TimelineUsecase, producer pkg.Producer) User {
 	return User{
-		userRepository: userRepository,
+		userRepository:  userRepository,
+		followerUsecase: followerUsecase,
+		timelineUsecase: timelineUsecase,
+		producer:        producer,
 	}
 }
 
@@ -83,17 +93,46 @@ func (u User) UpdateUser(ctx context.Context, id int64, user domain.User) (domai
 	}
 
 	// Update user
+	wasProtected := existingUser.Protected
 	existingUser.Email = user.Email
 	existingUser.Username = user.Username
+	existingUser.Protected = user.Protected
 
 	updatedUser, err := u.userRepository.UpdateByID(ctx, id, existingUser)
 	if err != nil {
 		return domain.User{}, err
 	}
 
+	// Tweets are hydrated with the cached profile of their author, drop the previous one
+	if err := u.timelineUsecase.InvalidateProfiles(ctx, id); err != nil {
+		log.Printf("Failed to invalidate profile of user %d: %v", id, err)
+	}
+
+	// Publish event asynchronously, e.g. for the search index to hide or show the user's tweets
+	go u.publishUserUpdated(updatedUser)
+
+	// Once public, the requests still pending are approved. The account is already
+	// public, failing the request would not undo it
+	if wasProtected && !updatedUser.Protected {
+		if _, err := u.followerUsecase.ApprovePendingFollowRequests(ctx, id); err != nil {
+			log.Printf("Failed to approve pending follow requests of user %d: %v", id, err)
+		}
+	}
+
 	return updatedUser, nil
 }
 
+// publishUserUpdated publishes a user.updated event to Kafka.
+// It is meant to run in its own goroutine so it doesn't block the response.
+func (u User) publishUserUpdated(user domain.User) {
+	event := dto.NewEvent(dto.UserUpdatedEvent, dto.UserUpdatedEventData{
+		UserID:    user.ID,
+		Protected: user.Protected,
+		UpdatedAt: user.UpdatedAt,
+	})
+	publishEvent(u.producer, config.TopicUsers, fmt.Sprintf(config.KeyFormatUser, user.ID), event)
+}
+
 func (u User) validateUser(ctx context.Context, user domain.User) error {
 
 	// Check if email already exists
@@ -3,10 +3,13 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 	"twitter-demo/internal/domain"
+	"twitter-demo/internal/interfaces/dto"
 	"twitter-demo/internal/mocks"
+	"twitter-demo/pkg"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -18,7 +21,7 @@ func TestUser_CreateUser_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	newUser := domain.User{
 		Username: "testuser",
@@ -69,7 +72,7 @@ func TestUser_CreateUser_EmailAlreadyExists(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	newUser := domain.User{
 		Username: "testuser",
@@ -103,7 +106,7 @@ func TestUser_CreateUser_UsernameAlreadyExists(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	newUser := domain.User{
 		Username: "existinguser",
@@ -143,7 +146,9 @@ func TestUser_UpdateUser_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	var invalidated []int64
+	producer := newStubProducer()
+	usecase := NewUser(mockRepo, nil, stubTimelineUsecase{invalidated: &invalidated}, producer)
 
 	userID := int64(1)
 	updateData := domain.User{
@@ -201,6 +206,107 @@ func TestUser_UpdateUser_Success(t *testing.T) {
 	assert.Equal(t, updatedUser.ID, result.ID)
 	assert.Equal(t, updatedUser.Username, result.Username)
 	assert.Equal(t, updatedUser.Email, result.Email)
+	assert.Equal(t, []int64{userID}, invalidated)
+
+	published := <-producer.published
+	assert.Equal(t, dto.UserUpdatedEvent, published.Type)
+	assert.Equal(t, dto.UserUpdatedEventData{UserID: userID, UpdatedAt: updatedUser.UpdatedAt}, published.Data)
+}
+
+// stubProducer hands the published events over a channel, they are published asynchronously.
+type stubProducer struct {
+	pkg.Producer
+	published chan dto.Event
+}
+
+func newStubProducer() stubProducer {
+	return stubProducer{published: make(chan dto.Event, 1)}
+}
+
+func (s stubProducer) Publish(ctx context.Context, topic string, key string, message interface{}) error {
+	s.published <- message.(dto.Event)
+	return nil
+}
+
+// stubFollowerUsecase records the users whose pending follow requests were approved.
+// Bulk follows of following are refused as already followed, the ones of protected
+// become follow requests.
+type stubFollowerUsecase struct {
+	FollowerUsecase
+	approvedFor *[]int64
+	following   []int64
+	protected   []int64
+}
+
+func (s stubFollowerUsecase) BulkFollowUser(ctx context.Context, followerID, followedID int64) (domain.Follower, domain.FollowRequest, error) {
+	switch {
+	case slices.Contains(s.following, followedID):
+		return domain.Follower{}, domain.FollowRequest{}, ErrAlreadyFollowing
+	case slices.Contains(s.protected, followedID):
+		return domain.Follower{}, domain.FollowRequest{ID: 1, RequesterID: followerID, TargetID: followedID}, nil
+	default:
+		return domain.Follower{ID: 1, FollowerID: followerID, FollowedID: followedID}, domain.FollowRequest{}, nil
+	}
+}
+
+func (s stubFollowerUsecase) ApprovePendingFollowRequests(ctx context.Context, targetID int64) (int, error) {
+	*s.approvedFor = append(*s.approvedFor, targetID)
+	return 1, nil
+}
+
+// stubTimelineUsecase records the users whose cached profiles or timelines were dropped,
+// and the timelines backfilled.
+type stubTimelineUsecase struct {
+	TimelineUsecase
+	invalidated          *[]int64
+	invalidatedTimelines *[]int64
+	backfilled           map[int64][]int64
+}
+
+func (s stubTimelineUsecase) InvalidateTimeline(ctx context.Context, userID int64) error {
+	*s.invalidatedTimelines = append(*s.invalidatedTimelines, userID)
+	return nil
+}
+
+func (s stubTimelineUsecase) BackfillTimeline(ctx context.Context, followerID int64, followedIDs ...int64) error {
+	s.backfilled[followerID] = append(s.backfilled[followerID], followedIDs...)
+	return nil
+}
+
+func (s stubTimelineUsecase) InvalidateProfiles(ctx context.Context, userIDs ...int64) error {
+	*s.invalidated = append(*s.invalidated, userIDs...)
+	return nil
+}
+
+func TestUser_UpdateUser_BecomesPublic(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var approvedFor []int64
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	usecase := NewUser(mockRepo, stubFollowerUsecase{approvedFor: &approvedFor}, stubTimelineUsecase{invalidated: new([]int64)}, newStubProducer())
+
+	userID := int64(1)
+	updateData := domain.User{Username: "alice", Email: "alice@example.com", Protected: false}
+	existingUser := domain.User{ID: userID, Username: "alice", Email: "alice@example.com", Protected: true}
+
+	mockRepo.EXPECT().SelectByEmail(gomock.Any(), updateData.Email).Return(domain.User{}, nil)
+	mockRepo.EXPECT().SelectByUsername(gomock.Any(), updateData.Username).Return(domain.User{}, nil)
+	mockRepo.EXPECT().SelectByID(gomock.Any(), userID).Return(existingUser, nil)
+	mockRepo.EXPECT().
+		UpdateByID(gomock.Any(), userID, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id int64, user domain.User) (domain.User, error) {
+			return user, nil
+		})
+
+	// Act
+	result, err := usecase.UpdateUser(context.Background(), userID, updateData)
+
+	// Assert: the requests still pending are approved
+	assert.NoError(t, err)
+	assert.False(t, result.Protected)
+	assert.Equal(t, []int64{userID}, approvedFor)
 }
 
 func TestUser_UpdateUser_UserNotFound(t *testing.T) {
@@ -209,7 +315,7 @@ func TestUser_UpdateUser_UserNotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	userID := int64(999)
 	updateData := domain.User{
@@ -249,7 +355,7 @@ func TestUser_GetUserByID_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	userID := int64(1)
 	expectedUser := domain.User{
@@ -282,7 +388,7 @@ func TestUser_GetUserByID_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	usecase := NewUser(mockRepo)
+	usecase := NewUser(mockRepo, nil, nil, nil)
 
 	userID := int64(1)
 	expectedError := fmt.Errorf("database error")
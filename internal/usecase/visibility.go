@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"twitter-demo/internal/domain"
+	"twitter-demo/internal/infrastructure/repository"
+)
+
+// ErrProtectedAccount is returned when a viewer asks for the tweets of a protected
+// account they don't follow.
+var ErrProtectedAccount = errors.New("this account's tweets are protected")
+
+// canViewTweetsOf reports whether viewerID can see the tweets written by author.
+// Public accounts are visible to everyone, protected ones only to themselves and
+// their approved followers. A viewerID of 0 is an anonymous viewer.
+func canViewTweetsOf(ctx context.Context, followerRepository repository.FollowerRepository, viewerID int64, author domain.User) (bool, error) {
+
+	if !author.Protected || viewerID == author.ID {
+		return true, nil
+	}
+
+	if viewerID == 0 {
+		return false, nil
+	}
+
+	follower, err := followerRepository.SelectByFollowerAndFollowed(ctx, viewerID, author.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return follower.ID != 0, nil
+}
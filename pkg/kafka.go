@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"twitter-demo/internal/config"
 
@@ -26,6 +27,7 @@ type Producer interface {
 // External code should depend on this interface, not on the concrete implementation.
 type Consumer interface {
 	Consume(ctx context.Context, topics []string, handler MessageHandler) error
+	CommitAfter(ctx context.Context, persist func(ctx context.Context) error) error
 	Close() error
 }
 
@@ -42,7 +44,18 @@ type kafkaConsumer struct {
 
 // consumerGroupHandler implements sarama.ConsumerGroupHandler
 type consumerGroupHandler struct {
-	handler MessageHandler
+	handler      MessageHandler
+	manualCommit bool
+
+	// With manual commits, the last message handled on each partition of the session
+	mu      sync.Mutex
+	session sarama.ConsumerGroupSession
+	handled map[topicPartition]*sarama.ConsumerMessage
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
 }
 
 // NewKafkaProducer creates a new Kafka producer instance.
@@ -63,12 +76,14 @@ func NewKafkaProducer(cfg config.KafkaConfig) (Producer, error) {
 }
 
 // NewKafkaConsumer creates a new Kafka consumer instance.
-// Note: The consumer uses the consumer group specified in the config.
+// Note: The consumer uses the consumer group specified in the config. With
+// cfg.ManualCommit, handled messages are only committed by CommitAfter.
 func NewKafkaConsumer(cfg config.KafkaConfig) (Consumer, error) {
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.Version = sarama.V2_8_0_0
 	saramaConfig.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
 	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = !cfg.ManualCommit
 
 	consumerGroup, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, saramaConfig)
 	if err != nil {
@@ -77,7 +92,7 @@ func NewKafkaConsumer(cfg config.KafkaConfig) (Consumer, error) {
 
 	return &kafkaConsumer{
 		consumerGroup: consumerGroup,
-		handler:       &consumerGroupHandler{},
+		handler:       &consumerGroupHandler{manualCommit: cfg.ManualCommit},
 	}, nil
 }
 
@@ -138,18 +153,88 @@ func (c *kafkaConsumer) Consume(ctx context.Context, topics []string, handler Me
 	}
 }
 
+// CommitAfter runs persist, then commits the offsets of the messages handled before it
+// started. Use it with cfg.ManualCommit when handlers keep what they consume in memory:
+// a crash before persist completes replays the messages instead of losing them. The
+// messages of partitions reassigned meanwhile are left to the new owner to replay.
+// Without cfg.ManualCommit offsets are committed as messages are handled, it only runs persist.
+func (c *kafkaConsumer) CommitAfter(ctx context.Context, persist func(ctx context.Context) error) error {
+
+	h := c.handler
+	if !h.manualCommit {
+		return persist(ctx)
+	}
+
+	h.mu.Lock()
+	session, handled := h.session, h.handled
+	h.handled = make(map[topicPartition]*sarama.ConsumerMessage)
+	h.mu.Unlock()
+
+	if err := persist(ctx); err != nil {
+		h.requeue(session, handled)
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if session == nil || session != h.session {
+		return nil
+	}
+
+	for _, message := range handled {
+		session.MarkMessage(message, "")
+	}
+	session.Commit()
+
+	return nil
+}
+
+// requeue puts back the messages of a failed CommitAfter, unless newer ones were handled
+// since, so the next one commits them.
+func (h *consumerGroupHandler) requeue(session sarama.ConsumerGroupSession, handled map[topicPartition]*sarama.ConsumerMessage) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if session != h.session {
+		return
+	}
+
+	for key, message := range handled {
+		if _, ok := h.handled[key]; !ok {
+			h.handled[key] = message
+		}
+	}
+}
+
 // Close closes the consumer connection.
 func (c *kafkaConsumer) Close() error {
 	return c.consumerGroup.Close()
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
-func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.session = session
+	h.handled = make(map[topicPartition]*sarama.ConsumerMessage)
+
 	return nil
 }
 
-// Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
+// Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited.
+// Messages handled but not committed are consumed again by the next session.
 func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.session = nil
+	h.handled = make(map[topicPartition]*sarama.ConsumerMessage)
+
 	return nil
 }
 
@@ -167,6 +252,9 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 				log.Printf("Error processing message: %v", err)
 				// Continue processing even if handler fails
 				// You might want to implement retry logic or dead letter queue here
+			} else if h.manualCommit {
+				// Committed by CommitAfter, once the handler's work is persisted
+				h.recordHandled(message)
 			} else {
 				// Mark message as consumed on success
 				session.MarkMessage(message, "")
@@ -177,3 +265,12 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 		}
 	}
 }
+
+// recordHandled remembers a message as the last one handled on its partition.
+func (h *consumerGroupHandler) recordHandled(message *sarama.ConsumerMessage) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handled[topicPartition{topic: message.Topic, partition: message.Partition}] = message
+}
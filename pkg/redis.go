@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"twitter-demo/internal/config"
@@ -14,15 +15,149 @@ import (
 type Cache interface {
 	Delete(ctx context.Context, key string) error
 
+	// Value operations for object caching
+	MGet(ctx context.Context, keys []string) ([]string, error)
+	SetMany(ctx context.Context, values map[string]interface{}, expiration time.Duration) error
+	SetManyIfNewer(ctx context.Context, values map[string]interface{}, versionField string, expiration time.Duration) error
+
 	// List operations for timeline caching
 	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
 	LPush(ctx context.Context, key string, values ...interface{}) error
+	LPushX(ctx context.Context, key string, values ...interface{}) error
 	RPush(ctx context.Context, key string, values ...interface{}) error
 	LTrim(ctx context.Context, key string, start, stop int64) error
 	LLen(ctx context.Context, key string) (int64, error)
 	Expire(ctx context.Context, key string, expiration time.Duration) error
+
+	// Atomic and batched list operations
+	ReplaceList(ctx context.Context, key string, values []interface{}, maxLen int64, expiration time.Duration) error
+	PushCapped(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration) error
+	PushCappedIfExists(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration) error
+	RenameIfExists(ctx context.Context, key, newKey string, expiration time.Duration) error
+
+	// Sorted set operations, e.g. for activity tracking
+	ZAdd(ctx context.Context, key, member string, score float64) error
+	ZScore(ctx context.Context, key, member string) (float64, error)
+	ZMScore(ctx context.Context, key string, members []string) ([]float64, error)
+	ZRemRangeByScore(ctx context.Context, key, min, max string) (int64, error)
+
+	// Hash operations for counters
+	HIncrBy(ctx context.Context, key, field string, incr int64) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+
+	// Stream and pub/sub operations for real-time delivery
+	AppendToStreams(ctx context.Context, keys []string, values map[string]interface{}, maxLen int64, expiration time.Duration) ([]string, error)
+	ReadStream(ctx context.Context, key, afterID string, count int64) ([]StreamEntry, error)
+	PublishMany(ctx context.Context, messages []Message) error
+	NewSubscription(ctx context.Context, channels ...string) Subscription
+
+	// Key inspection, for maintenance tools
+	ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error)
+	ListInfo(ctx context.Context, keys []string) ([]ListInfo, error)
+
+	// Lock operations for coordinating work between replicas
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	DeleteIfEquals(ctx context.Context, key string, value interface{}) (bool, error)
+}
+
+// StreamEntry is an entry of a stream, identified by the ID Redis gave it.
+type StreamEntry struct {
+	ID     string
+	Values map[string]string
+}
+
+// ListInfo describes a list. TTL is negative when the list never expires (-1) or
+// doesn't exist (-2).
+type ListInfo struct {
+	Length int64
+	TTL    time.Duration
 }
 
+// Message is a message published to a pub/sub channel.
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// Subscription receives the messages published to the channels it is subscribed to.
+// Channels can be added and removed while messages are being received.
+type Subscription interface {
+	Subscribe(ctx context.Context, channels ...string) error
+	Unsubscribe(ctx context.Context, channels ...string) error
+	Messages() <-chan Message
+	Close() error
+}
+
+// pipelineBatchSize is how many commands are sent per round trip by batched operations.
+const pipelineBatchSize = 1000
+
+// renameIfExistsScript renames a key and sets the expiration of the new one, doing nothing
+// when the key doesn't exist. KEYS[1]: key, KEYS[2]: new key, ARGV[1]: expiration in milliseconds.
+var renameIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+
+redis.call("RENAME", KEYS[1], KEYS[2])
+redis.call("PEXPIRE", KEYS[2], ARGV[1])
+return 1
+`)
+
+// deleteIfEqualsScript deletes a key only while it still holds the given value, so a lock
+// is never released by someone else than its owner. KEYS[1]: key, ARGV[1]: expected value.
+var deleteIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+
+return 0
+`)
+
+// pushCappedScript prepends a value to a list, trims it to maxLen and refreshes its
+// expiration in one atomic step. With onlyIfExists set, missing lists are not created.
+//
+// KEYS[1]: list, ARGV[1]: value, ARGV[2]: max length, ARGV[3]: expiration in milliseconds
+// (0 keeps the current one), ARGV[4]: "1" to push only if the list exists.
+var pushCappedScript = redis.NewScript(`
+local pushed
+if ARGV[4] == "1" then
+	pushed = redis.call("LPUSHX", KEYS[1], ARGV[1])
+else
+	pushed = redis.call("LPUSH", KEYS[1], ARGV[1])
+end
+
+if pushed > 0 then
+	redis.call("LTRIM", KEYS[1], 0, tonumber(ARGV[2]) - 1)
+	if tonumber(ARGV[3]) > 0 then
+		redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	end
+end
+
+return pushed
+`)
+
+// setIfNewerScript sets a JSON object unless the one already stored has a greater version,
+// so a stale copy read before an update can't replace the new one. A missing or
+// non-numeric version counts as 0.
+//
+// KEYS[1]: key, ARGV[1]: JSON object, ARGV[2]: version field, ARGV[3]: expiration in milliseconds.
+var setIfNewerScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current then
+	local ok, stored = pcall(cjson.decode, current)
+	if ok and type(stored) == "table" then
+		local storedVersion = tonumber(stored[ARGV[2]]) or 0
+		local newVersion = tonumber(cjson.decode(ARGV[1])[ARGV[2]]) or 0
+		if storedVersion > newVersion then
+			return 0
+		end
+	end
+end
+
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`)
+
 // redisCache is the concrete implementation of Cache using go-redis.
 type redisCache struct {
 	client *redis.Client
@@ -46,6 +181,102 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// MGet retrieves the values of many keys in one round trip, in the order of keys.
+// Missing keys (and values that are not strings) are returned as empty strings.
+func (r *redisCache) MGet(ctx context.Context, keys []string) ([]string, error) {
+
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(results))
+	for i, result := range results {
+		if value, ok := result.(string); ok {
+			values[i] = value
+		}
+	}
+
+	return values, nil
+}
+
+// SetMany sets many keys, each expiring after expiration (0 never). The commands are
+// pipelined, pipelineBatchSize per round trip.
+func (r *redisCache) SetMany(ctx context.Context, values map[string]interface{}, expiration time.Duration) error {
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		sent := 0
+		for key, value := range values {
+			pipe.Set(ctx, key, value, expiration)
+
+			sent++
+			if sent%pipelineBatchSize == 0 {
+				if _, err := pipe.Exec(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+
+	return err
+}
+
+// SetManyIfNewer stores JSON objects like SetMany, except the keys already holding a
+// version greater than the new one, read from versionField. The values are sent in
+// pipelined batches of pipelineBatchSize.
+func (r *redisCache) SetManyIfNewer(ctx context.Context, values map[string]interface{}, versionField string, expiration time.Duration) error {
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	// Load the script once so the pipelined calls only send its hash
+	if err := setIfNewerScript.Load(ctx, r.client).Err(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	for start := 0; start < len(keys); start += pipelineBatchSize {
+		batch := keys[start:min(start+pipelineBatchSize, len(keys))]
+
+		cmds, _ := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				setIfNewerScript.EvalSha(ctx, pipe, []string{key}, values[key], versionField, expiration.Milliseconds())
+			}
+			return nil
+		})
+
+		for i, cmd := range cmds {
+			err := cmd.Err()
+
+			// The script cache was flushed meanwhile: send the whole script for this key
+			if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+				key := batch[i]
+				err = setIfNewerScript.Eval(ctx, r.client, []string{key}, values[key], versionField, expiration.Milliseconds()).Err()
+			}
+
+			if err != nil && err != redis.Nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // LRange retrieves a range of elements from a Redis list.
 // Start and stop are zero-based indexes. Use -1 for the last element.
 func (r *redisCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
@@ -58,6 +289,12 @@ func (r *redisCache) LPush(ctx context.Context, key string, values ...interface{
 	return r.client.LPush(ctx, key, values...).Err()
 }
 
+// LPushX inserts values at the head of the list only if the list already exists.
+// Use this to keep a cached list up to date without creating a partial one.
+func (r *redisCache) LPushX(ctx context.Context, key string, values ...interface{}) error {
+	return r.client.LPushX(ctx, key, values...).Err()
+}
+
 // RPush inserts values at the tail of the list (appends, maintains order as provided).
 // Use this to build the initial cache from database results.
 func (r *redisCache) RPush(ctx context.Context, key string, values ...interface{}) error {
@@ -75,7 +312,346 @@ func (r *redisCache) LLen(ctx context.Context, key string) (int64, error) {
 	return r.client.LLen(ctx, key).Result()
 }
 
+// ScanKeys returns a batch of the keys matching pattern, about count of them, and the
+// cursor to pass to get the next batch. The scan starts and ends with cursor 0.
+func (r *redisCache) ScanKeys(ctx context.Context, pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	return r.client.Scan(ctx, cursor, pattern, count).Result()
+}
+
+// ListInfo returns the length and time to live of lists, in the order of keys.
+// Keys holding another type than a list have a length of 0, like missing ones.
+// The commands are pipelined, pipelineBatchSize lists per round trip.
+func (r *redisCache) ListInfo(ctx context.Context, keys []string) ([]ListInfo, error) {
+
+	infos := make([]ListInfo, 0, len(keys))
+	for start := 0; start < len(keys); start += pipelineBatchSize {
+		batch := keys[start:min(start+pipelineBatchSize, len(keys))]
+
+		lengths := make([]*redis.IntCmd, len(batch))
+		ttls := make([]*redis.DurationCmd, len(batch))
+		// Errors are checked per command, a key of another type only fails its LLEN
+		_, _ = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				lengths[i] = pipe.LLen(ctx, key)
+				ttls[i] = pipe.TTL(ctx, key)
+			}
+			return nil
+		})
+
+		for i := range batch {
+			if err := lengths[i].Err(); err != nil && !redis.HasErrorPrefix(err, "WRONGTYPE") {
+				return nil, err
+			}
+			if err := ttls[i].Err(); err != nil {
+				return nil, err
+			}
+			infos = append(infos, ListInfo{Length: lengths[i].Val(), TTL: ttls[i].Val()})
+		}
+	}
+
+	return infos, nil
+}
+
 // Expire sets the expiration time for a key.
 func (r *redisCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
 	return r.client.Expire(ctx, key, expiration).Err()
 }
+
+// ReplaceList replaces the content of a list in a MULTI/EXEC transaction: readers see
+// either the old list or the new one, never a missing or partial list in between.
+// The new list is trimmed to maxLen elements (0 keeps them all) and expires after
+// expiration (0 never). An empty values deletes the list.
+func (r *redisCache) ReplaceList(ctx context.Context, key string, values []interface{}, maxLen int64, expiration time.Duration) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(values) == 0 {
+			return nil
+		}
+
+		pipe.RPush(ctx, key, values...)
+		if maxLen > 0 {
+			pipe.LTrim(ctx, key, 0, maxLen-1)
+		}
+		if expiration > 0 {
+			pipe.Expire(ctx, key, expiration)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// PushCapped prepends value to every list, trimming each one to maxLen elements and
+// refreshing its expiration (0 keeps the current one). Each list is updated atomically
+// by a server-side script and the lists are sent pipelined, pipelineBatchSize per round trip.
+// Use this for Fan-Out to push a new tweet to thousands of timelines.
+func (r *redisCache) PushCapped(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration) error {
+	return r.pushCapped(ctx, keys, value, maxLen, expiration, false)
+}
+
+// PushCappedIfExists works like PushCapped but leaves alone the lists that don't exist,
+// so a cached list is kept up to date without creating a partial one.
+func (r *redisCache) PushCappedIfExists(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration) error {
+	return r.pushCapped(ctx, keys, value, maxLen, expiration, true)
+}
+
+func (r *redisCache) pushCapped(ctx context.Context, keys []string, value interface{}, maxLen int64, expiration time.Duration, onlyIfExists bool) error {
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Load the script once so the pipelined calls only send its hash
+	if err := pushCappedScript.Load(ctx, r.client).Err(); err != nil {
+		return err
+	}
+
+	existsFlag := "0"
+	if onlyIfExists {
+		existsFlag = "1"
+	}
+	args := []interface{}{value, maxLen, expiration.Milliseconds(), existsFlag}
+
+	failed := 0
+	var firstErr error
+
+	for start := 0; start < len(keys); start += pipelineBatchSize {
+		batch := keys[start:min(start+pipelineBatchSize, len(keys))]
+
+		cmds, _ := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pushCappedScript.EvalSha(ctx, pipe, []string{key}, args...)
+			}
+			return nil
+		})
+
+		for i, cmd := range cmds {
+			err := cmd.Err()
+
+			// The script cache was flushed meanwhile: send the whole script for this list
+			if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+				err = pushCappedScript.Eval(ctx, r.client, []string{batch[i]}, args...).Err()
+			}
+
+			if err != nil && err != redis.Nil {
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return fmt.Errorf("failed to push to %d of %d lists: %w", failed, len(keys), firstErr)
+	}
+
+	return nil
+}
+
+// ZAdd adds a member to a sorted set, or updates its score.
+func (r *redisCache) ZAdd(ctx context.Context, key, member string, score float64) error {
+	return r.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZScore returns the score of a member of a sorted set, 0 when it is not a member.
+func (r *redisCache) ZScore(ctx context.Context, key, member string) (float64, error) {
+	score, err := r.client.ZScore(ctx, key, member).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return score, err
+}
+
+// ZMScore returns the scores of many members of a sorted set, in the order of members,
+// 0 for the ones that are not members. Members are sent pipelineBatchSize per command.
+func (r *redisCache) ZMScore(ctx context.Context, key string, members []string) ([]float64, error) {
+
+	scores := make([]float64, 0, len(members))
+
+	for start := 0; start < len(members); start += pipelineBatchSize {
+		batch := members[start:min(start+pipelineBatchSize, len(members))]
+
+		batchScores, err := r.client.ZMScore(ctx, key, batch...).Result()
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, batchScores...)
+	}
+
+	return scores, nil
+}
+
+// ZRemRangeByScore removes the members of a sorted set whose score is between min and
+// max, in the syntax of ZRANGEBYSCORE (e.g. "-inf", "(42"), and returns how many were removed.
+func (r *redisCache) ZRemRangeByScore(ctx context.Context, key, min, max string) (int64, error) {
+	return r.client.ZRemRangeByScore(ctx, key, min, max).Result()
+}
+
+// HIncrBy increments a field of a hash, creating both if needed.
+func (r *redisCache) HIncrBy(ctx context.Context, key, field string, incr int64) error {
+	return r.client.HIncrBy(ctx, key, field, incr).Err()
+}
+
+// HGetAll returns every field of a hash, an empty map when it doesn't exist.
+func (r *redisCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+// AppendToStreams adds an entry with the given values to every stream, keeping about the
+// last maxLen entries of each (0 keeps them all) and refreshing its expiration (0 never).
+// The IDs of the new entries are returned in the order of keys. The commands are
+// pipelined, pipelineBatchSize streams per round trip.
+func (r *redisCache) AppendToStreams(ctx context.Context, keys []string, values map[string]interface{}, maxLen int64, expiration time.Duration) ([]string, error) {
+
+	ids := make([]string, 0, len(keys))
+
+	for start := 0; start < len(keys); start += pipelineBatchSize {
+		batch := keys[start:min(start+pipelineBatchSize, len(keys))]
+
+		addCmds := make([]*redis.StringCmd, len(batch))
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				addCmds[i] = pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, MaxLen: maxLen, Approx: true, Values: values})
+				if expiration > 0 {
+					pipe.Expire(ctx, key, expiration)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cmd := range addCmds {
+			ids = append(ids, cmd.Val())
+		}
+	}
+
+	return ids, nil
+}
+
+// ReadStream returns up to count entries of a stream (0 for all) added after afterID,
+// oldest first. An empty afterID reads from the beginning.
+func (r *redisCache) ReadStream(ctx context.Context, key, afterID string, count int64) ([]StreamEntry, error) {
+
+	start := "-"
+	if afterID != "" {
+		start = "(" + afterID
+	}
+
+	var messages []redis.XMessage
+	var err error
+	if count > 0 {
+		messages, err = r.client.XRangeN(ctx, key, start, "+", count).Result()
+	} else {
+		messages, err = r.client.XRange(ctx, key, start, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, 0, len(messages))
+	for _, message := range messages {
+		values := make(map[string]string, len(message.Values))
+		for field, value := range message.Values {
+			values[field] = fmt.Sprint(value)
+		}
+		entries = append(entries, StreamEntry{ID: message.ID, Values: values})
+	}
+
+	return entries, nil
+}
+
+// PublishMany publishes messages to their channels, pipelineBatchSize per round trip.
+func (r *redisCache) PublishMany(ctx context.Context, messages []Message) error {
+
+	for start := 0; start < len(messages); start += pipelineBatchSize {
+		batch := messages[start:min(start+pipelineBatchSize, len(messages))]
+
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, message := range batch {
+				pipe.Publish(ctx, message.Channel, message.Payload)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewSubscription opens a pub/sub connection subscribed to the given channels. One
+// subscription can serve many channels, so a process needs a single one. It should
+// always keep a channel: a connection subscribed to nothing leaves pub/sub mode.
+func (r *redisCache) NewSubscription(ctx context.Context, channels ...string) Subscription {
+
+	pubsub := r.client.Subscribe(ctx, channels...)
+
+	messages := make(chan Message)
+	go func() {
+		defer close(messages)
+		for message := range pubsub.Channel() {
+			messages <- Message{Channel: message.Channel, Payload: message.Payload}
+		}
+	}()
+
+	return &redisSubscription{
+		pubsub:   pubsub,
+		messages: messages,
+	}
+}
+
+// redisSubscription is the concrete implementation of Subscription using go-redis.
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	messages chan Message
+}
+
+// Subscribe adds channels to the subscription.
+func (s *redisSubscription) Subscribe(ctx context.Context, channels ...string) error {
+	return s.pubsub.Subscribe(ctx, channels...)
+}
+
+// Unsubscribe removes channels from the subscription.
+func (s *redisSubscription) Unsubscribe(ctx context.Context, channels ...string) error {
+	return s.pubsub.Unsubscribe(ctx, channels...)
+}
+
+// Messages returns the messages received on every subscribed channel.
+// It is closed when the subscription is closed.
+func (s *redisSubscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close closes the pub/sub connection.
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// RenameIfExists atomically moves a key to newKey, which expires after expiration.
+// Nothing happens when the key doesn't exist. Use this to keep the previous version
+// of a list around while it is rebuilt.
+func (r *redisCache) RenameIfExists(ctx context.Context, key, newKey string, expiration time.Duration) error {
+	return renameIfExistsScript.Run(ctx, r.client, []string{key, newKey}, expiration.Milliseconds()).Err()
+}
+
+// SetNX sets a key only if it doesn't exist and reports whether it was set.
+// Use this with a unique value to acquire a lock that expires on its own.
+func (r *redisCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
+// DeleteIfEquals deletes a key only if it still holds value and reports whether it was deleted.
+// Use this to release a lock acquired with SetNX.
+func (r *redisCache) DeleteIfEquals(ctx context.Context, key string, value interface{}) (bool, error) {
+	deleted, err := deleteIfEqualsScript.Run(ctx, r.client, []string{key}, value).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return deleted == 1, nil
+}
@@ -0,0 +1,204 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"twitter-demo/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// maxCachedTweets is the length timelines are capped to, see usecase.MaxCachedTweets.
+const maxCachedTweets = 1000
+
+func newTestRedisCache(t *testing.T) (Cache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	return NewRedisCache(config.RedisConfig{Address: server.Addr()}), server
+}
+
+// listValues returns values as list entries: "1", "2", ...
+func listValues(count int) []interface{} {
+	values := make([]interface{}, count)
+	for i := range values {
+		values[i] = strconv.Itoa(i + 1)
+	}
+	return values
+}
+
+func TestRedisCache_ReplaceList(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	_, err := server.Lpush("timeline:user:1", "old")
+	assert.NoError(t, err)
+
+	// Act
+	err = cache.ReplaceList(ctx, "timeline:user:1", listValues(maxCachedTweets+5), maxCachedTweets, time.Hour)
+
+	// Assert: the old entries are gone and the new list is capped and expires
+	assert.NoError(t, err)
+	list, _ := server.List("timeline:user:1")
+	assert.Len(t, list, maxCachedTweets)
+	assert.Equal(t, "1", list[0])
+	assert.Equal(t, strconv.Itoa(maxCachedTweets), list[maxCachedTweets-1])
+	assert.Equal(t, time.Hour, server.TTL("timeline:user:1"))
+}
+
+func TestRedisCache_ReplaceList_Empty(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	_, err := server.Lpush("timeline:user:1", "old")
+	assert.NoError(t, err)
+
+	// Act
+	err = cache.ReplaceList(ctx, "timeline:user:1", nil, maxCachedTweets, time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, server.Exists("timeline:user:1"))
+}
+
+func TestRedisCache_ReplaceList_NeverEmptyForReaders(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache, _ := newTestRedisCache(t)
+	assert.NoError(t, cache.ReplaceList(ctx, "timeline:user:1", listValues(10), maxCachedTweets, time.Hour))
+
+	// Act: read the list while it is replaced over and over
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			assert.NoError(t, cache.ReplaceList(ctx, "timeline:user:1", listValues(10+i%5), maxCachedTweets, time.Hour))
+		}
+	}()
+
+	var reads, emptyReads int
+	for {
+		select {
+		case <-done:
+			wg.Wait()
+
+			// Assert: no reader saw the list deleted and not written yet
+			assert.Positive(t, reads)
+			assert.Zero(t, emptyReads)
+			return
+		default:
+			length, err := cache.LLen(ctx, "timeline:user:1")
+			assert.NoError(t, err)
+			reads++
+			if length == 0 {
+				emptyReads++
+			}
+		}
+	}
+}
+
+func TestRedisCache_PushCapped(t *testing.T) {
+	// Arrange: a full timeline without expiration, and a missing one
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	assert.NoError(t, cache.ReplaceList(ctx, "timeline:user:1", listValues(maxCachedTweets), 0, 0))
+
+	// Act
+	err := cache.PushCapped(ctx, []string{"timeline:user:1", "timeline:user:2"}, "new", maxCachedTweets, time.Hour)
+
+	// Assert: the full list stays capped, the missing one is created, both expire
+	assert.NoError(t, err)
+	full, _ := server.List("timeline:user:1")
+	assert.Len(t, full, maxCachedTweets)
+	assert.Equal(t, "new", full[0])
+	assert.Equal(t, strconv.Itoa(maxCachedTweets-1), full[maxCachedTweets-1])
+	assert.Equal(t, time.Hour, server.TTL("timeline:user:1"))
+
+	created, _ := server.List("timeline:user:2")
+	assert.Equal(t, []string{"new"}, created)
+	assert.Equal(t, time.Hour, server.TTL("timeline:user:2"))
+}
+
+func TestRedisCache_PushCapped_KeepsExpiration(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	assert.NoError(t, cache.ReplaceList(ctx, "timeline:user:1", listValues(3), maxCachedTweets, time.Hour))
+	server.FastForward(time.Minute)
+
+	// Act
+	err := cache.PushCapped(ctx, []string{"timeline:user:1"}, "new", maxCachedTweets, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 59*time.Minute, server.TTL("timeline:user:1"))
+}
+
+func TestRedisCache_PushCappedIfExists(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	assert.NoError(t, cache.ReplaceList(ctx, "timeline:user:1", listValues(2), maxCachedTweets, time.Minute))
+
+	// Act
+	err := cache.PushCappedIfExists(ctx, []string{"timeline:user:1", "timeline:user:2"}, "new", maxCachedTweets, time.Hour)
+
+	// Assert: the missing list is skipped rather than created partial
+	assert.NoError(t, err)
+	list, _ := server.List("timeline:user:1")
+	assert.Equal(t, []string{"new", "1", "2"}, list)
+	assert.Equal(t, time.Hour, server.TTL("timeline:user:1"))
+	assert.False(t, server.Exists("timeline:user:2"))
+}
+
+func TestRedisCache_PushCappedIfExists_Pipelined(t *testing.T) {
+	// Arrange: more lists than fit in one pipeline, every other one cached
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+
+	keys := make([]string, 2*pipelineBatchSize+10)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("timeline:user:%d", i+1)
+		if i%2 == 0 {
+			_, err := server.Lpush(keys[i], "old")
+			assert.NoError(t, err)
+		}
+	}
+
+	// Act
+	err := cache.PushCappedIfExists(ctx, keys, "new", maxCachedTweets, time.Hour)
+
+	// Assert: every cached list of every batch got the value, the others are still missing
+	assert.NoError(t, err)
+	for i, key := range keys {
+		if i%2 != 0 {
+			assert.False(t, server.Exists(key), key)
+			continue
+		}
+		list, _ := server.List(key)
+		assert.Equal(t, []string{"new", "old"}, list, key)
+	}
+}
+
+func TestRedisCache_PushCapped_ReportsFailedLists(t *testing.T) {
+	// Arrange: one of the keys holds a string, not a list
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+	assert.NoError(t, server.Set("timeline:user:2", "oops"))
+
+	// Act
+	err := cache.PushCapped(ctx, []string{"timeline:user:1", "timeline:user:2", "timeline:user:3"}, "new", maxCachedTweets, time.Hour)
+
+	// Assert: the other lists are still pushed to
+	assert.ErrorContains(t, err, "failed to push to 1 of 3 lists")
+	for _, key := range []string{"timeline:user:1", "timeline:user:3"} {
+		list, _ := server.List(key)
+		assert.Equal(t, []string{"new"}, list, key)
+	}
+}